@@ -0,0 +1,65 @@
+// Copyright (C) 2024 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package blockchain
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"storj.io/storjscan/common"
+)
+
+// maxBatchSize is the maximum number of requests sent in a single JSON-RPC batch, providers reject larger batches.
+const maxBatchSize = 100
+
+// batchClient is a chain client able to send JSON-RPC batches.
+type batchClient interface {
+	BatchCallContext(ctx context.Context, batch []rpc.BatchElem) error
+}
+
+// headersByHash returns the headers of the blocks with the given hashes. The headers are fetched in JSON-RPC batches
+// when the client supports them, one request per block otherwise. It fails with ethereum.NotFound if a block is
+// not found.
+func headersByHash(ctx context.Context, client ChainClient, hashes []common.Hash) (_ []*types.Header, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	headers := make([]*types.Header, len(hashes))
+	batcher, ok := client.(batchClient)
+	if !ok {
+		for i, hash := range hashes {
+			if headers[i], err = client.HeaderByHash(ctx, hash); err != nil {
+				return nil, err
+			}
+		}
+		return headers, nil
+	}
+
+	for start := 0; start < len(hashes); start += maxBatchSize {
+		end := min(start+maxBatchSize, len(hashes))
+		batch := make([]rpc.BatchElem, 0, end-start)
+		for i := start; i < end; i++ {
+			batch = append(batch, rpc.BatchElem{
+				Method: "eth_getBlockByHash",
+				Args:   []any{hashes[i], false},
+				Result: &headers[i],
+			})
+		}
+		if err := batcher.BatchCallContext(ctx, batch); err != nil {
+			return nil, err
+		}
+		mon.Counter("header_batches").Inc(1)
+		for i, elem := range batch {
+			if elem.Error != nil {
+				return nil, elem.Error
+			}
+			if headers[start+i] == nil {
+				return nil, ethereum.NotFound
+			}
+		}
+	}
+	return headers, nil
+}
@@ -0,0 +1,50 @@
+// Copyright (C) 2024 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package blockchain
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"storj.io/storjscan/common"
+)
+
+// ChainClient is the client of a chain provider. It covers the requests the services make to every chain, so that
+// they can be served by other implementations than the go-ethereum client, such as an in-memory chain in tests.
+// Services needing other requests check whether the client supports them.
+type ChainClient interface {
+	// ChainID returns the chain ID served by the provider.
+	ChainID(ctx context.Context) (*big.Int, error)
+	// HeaderByNumber returns the header of the block with the given number, or of the latest block if number is nil.
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	// HeaderByHash returns the header of the block with the given hash.
+	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
+	// FilterLogs returns the logs matching the query.
+	FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error)
+}
+
+// Chains gives access to the clients of the providers of the endpoint chains.
+type Chains interface {
+	// Do calls fn with the client of a provider of the endpoint chain.
+	Do(ctx context.Context, endpoint common.EthEndpoint, fn func(client ChainClient) error) error
+	// DoOthers calls fn with the clients of the providers of the endpoint chain other than the one of the served client
+	// given to fn by Do, until fn succeeded for n of them, and returns the number of providers for which fn succeeded.
+	DoOthers(ctx context.Context, endpoint common.EthEndpoint, served ChainClient, n int, fn func(client ChainClient) error) (succeeded int, err error)
+}
+
+// Subscriptions gives access to the clients of the providers of the endpoint chains which support subscriptions.
+type Subscriptions interface {
+	// Subscribe calls fn with the client of a WebSocket or IPC provider of the endpoint chain.
+	Subscribe(ctx context.Context, endpoint common.EthEndpoint, fn func(client ChainClient) error) error
+}
+
+var (
+	_ ChainClient   = (*ethclient.Client)(nil)
+	_ Chains        = (*Clients)(nil)
+	_ Subscriptions = (*Clients)(nil)
+)
@@ -13,6 +13,7 @@ import (
 
 	"storj.io/common/sync2"
 	"storj.io/storjscan/blockchain"
+	"storj.io/storjscan/common"
 )
 
 var mon = monkit.Package()
@@ -20,27 +21,29 @@ var mon = monkit.Package()
 // Config is a configuration struct for the Chore.
 type Config struct {
 	Interval   time.Duration `help:"how often to remove old block headers" default:"336h" testDefault:"$TESTINTERVAL"`
-	RetainDays int           `help:"number of days of block headers to retain" default:"30"`
+	RetainDays int           `help:"number of days of block headers to retain for the chains which don't configure their retention" default:"30"`
 }
 
-// Chore to remove old block headers.
+// Chore to remove old block headers, following the retention configured for each chain by its endpoint.
 //
 // architecture: Chore
 type Chore struct {
-	log    *zap.Logger
-	db     blockchain.HeadersDB
-	config Config
+	log       *zap.Logger
+	db        blockchain.HeadersDB
+	endpoints []common.EthEndpoint
+	config    Config
 
 	Loop *sync2.Cycle
 }
 
 // NewChore creates new chore for removing old block headers.
-func NewChore(log *zap.Logger, db blockchain.HeadersDB, config Config) *Chore {
+func NewChore(log *zap.Logger, db blockchain.HeadersDB, endpoints []common.EthEndpoint, config Config) *Chore {
 
 	return &Chore{
-		log:    log,
-		db:     db,
-		config: config,
+		log:       log,
+		db:        db,
+		endpoints: endpoints,
+		config:    config,
 
 		Loop: sync2.NewCycle(config.Interval),
 	}
@@ -58,7 +61,7 @@ func (chore *Chore) Run(ctx context.Context) (err error) {
 	})
 }
 
-// RunOnce removes old block headers.
+// RunOnce removes old block headers of all the chains.
 func (chore *Chore) RunOnce(ctx context.Context) (err error) {
 	defer mon.Task()(&ctx)(&err)
 
@@ -66,15 +69,54 @@ func (chore *Chore) RunOnce(ctx context.Context) (err error) {
 		return errs.New("retain days cannot be less than 0")
 	}
 
-	beforeDays := time.Now().UTC().AddDate(0, 0, -chore.config.RetainDays)
-	err = chore.db.DeleteBefore(ctx, beforeDays)
+	chainIDs, err := chore.db.ListChainIDs(ctx)
 	if err != nil {
-		chore.log.Error("error removing old block headers", zap.Error(err))
+		chore.log.Error("error listing chains of block headers", zap.Error(err))
+		return nil
+	}
+
+	now := time.Now().UTC()
+	for _, chainID := range chainIDs {
+		endpoint, ok := chore.endpoint(chainID)
+		if !ok || (endpoint.RetainBlocks <= 0 && endpoint.RetainDays <= 0) {
+			err = chore.db.DeleteBefore(ctx, chainID, now.AddDate(0, 0, -chore.config.RetainDays))
+			if err != nil {
+				chore.log.Error("error removing old block headers", zap.Int64("Chain ID", chainID), zap.Error(err))
+			}
+			continue
+		}
+
+		if endpoint.RetainBlocks > 0 {
+			// the blocks are counted from the latest cached header, so that the chain doesn't need to be reached
+			latest, err := chore.db.GetLatest(ctx, chainID)
+			if err == nil {
+				err = chore.db.DeleteBeforeNumber(ctx, chainID, latest.Number-endpoint.RetainBlocks+1)
+			}
+			if err != nil && !errs.Is(err, blockchain.ErrNoHeader) {
+				chore.log.Error("error removing old block headers", zap.Int64("Chain ID", chainID), zap.Error(err))
+			}
+		}
+		if endpoint.RetainDays > 0 {
+			err = chore.db.DeleteBefore(ctx, chainID, now.AddDate(0, 0, -endpoint.RetainDays))
+			if err != nil {
+				chore.log.Error("error removing old block headers", zap.Int64("Chain ID", chainID), zap.Error(err))
+			}
+		}
 	}
 
 	return nil
 }
 
+// endpoint returns the endpoint of the chain.
+func (chore *Chore) endpoint(chainID int64) (common.EthEndpoint, bool) {
+	for _, endpoint := range chore.endpoints {
+		if endpoint.ChainID == chainID {
+			return endpoint, true
+		}
+	}
+	return common.EthEndpoint{}, false
+}
+
 // Close stops the chore.
 func (chore *Chore) Close() error {
 	chore.Loop.Close()
@@ -5,6 +5,7 @@ package cleanup_test
 
 import (
 	"crypto/rand"
+	"slices"
 	"testing"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"go.uber.org/zap/zaptest"
 
 	"storj.io/common/testcontext"
+	"storj.io/common/testrand"
 	"storj.io/storjscan/blockchain"
 	"storj.io/storjscan/blockchain/cleanup"
 	"storj.io/storjscan/common"
@@ -71,7 +73,7 @@ func TestChore(t *testing.T) {
 			require.Equal(t, header.Timestamp, dbHeader.Timestamp.Local())
 		}
 
-		chore := cleanup.NewChore(zaptest.NewLogger(t), db.Headers(), cleanup.Config{
+		chore := cleanup.NewChore(zaptest.NewLogger(t), db.Headers(), nil, cleanup.Config{
 			Interval:   336 * time.Hour,
 			RetainDays: 30,
 		})
@@ -106,3 +108,53 @@ func TestChore(t *testing.T) {
 		require.Equal(t, blockchain.Header{}, dbHeader)
 	})
 }
+
+func TestChoreChainRetention(t *testing.T) {
+	storjscandbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db *storjscandbtest.DB) {
+		now := time.Now().Truncate(time.Millisecond)
+		header := func(chainID, number int64, age time.Duration) blockchain.Header {
+			return blockchain.Header{
+				ChainID:   chainID,
+				Hash:      common.HashFromBytes(testrand.BytesInt(common.HashLength)),
+				Number:    number,
+				Timestamp: now.Add(-age),
+			}
+		}
+		day := 24 * time.Hour
+
+		// chain 1 retains the last 2 blocks, chain 2 retains 7 days, chain 3 isn't configured and retains 30 days
+		retained := []blockchain.Header{
+			header(1, 9, 100*day),
+			header(1, 10, 100*day),
+			header(2, 1, day),
+			header(3, 1, 29*day),
+		}
+		removed := []blockchain.Header{
+			header(1, 7, time.Hour),
+			header(1, 8, 100*day),
+			header(2, 2, 8*day),
+			header(3, 2, 31*day),
+		}
+		require.NoError(t, db.Headers().InsertMany(ctx, append(slices.Clone(retained), removed...)))
+
+		endpoints := []common.EthEndpoint{
+			{ChainID: 1, RetainBlocks: 2},
+			{ChainID: 2, RetainDays: 7},
+		}
+		chore := cleanup.NewChore(zaptest.NewLogger(t), db.Headers(), endpoints, cleanup.Config{
+			Interval:   336 * time.Hour,
+			RetainDays: 30,
+		})
+		defer ctx.Check(chore.Close)
+
+		require.NoError(t, chore.RunOnce(ctx))
+		for _, header := range retained {
+			_, err := db.Headers().Get(ctx, header.ChainID, header.Hash)
+			require.NoError(t, err)
+		}
+		for _, header := range removed {
+			_, err := db.Headers().Get(ctx, header.ChainID, header.Hash)
+			require.ErrorIs(t, err, blockchain.ErrNoHeader)
+		}
+	})
+}
@@ -0,0 +1,717 @@
+// Copyright (C) 2024 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package blockchain
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
+
+	"storj.io/common/sync2"
+	"storj.io/storjscan/common"
+)
+
+var mon = monkit.Package()
+
+// ErrClients is the error class of the chain clients pool.
+var ErrClients = errs.Class("chain clients")
+
+// ErrChainIDMismatch is the error class of providers serving another chain than the one of their endpoint.
+var ErrChainIDMismatch = errs.Class("chain id mismatch")
+
+const (
+	// failurePenalty is added to the score of a provider for each of its consecutive failures.
+	failurePenalty = 10 * time.Second
+	// lagPenalty is added to the score of a provider for each block it's behind the other providers of the chain.
+	lagPenalty = time.Second
+	// latencyWeight is the weight of the last probe in the average latency of a provider.
+	latencyWeight = 0.3
+)
+
+// ClientsConfig is the configuration of the chain clients pool.
+type ClientsConfig struct {
+	ProbeInterval time.Duration `help:"how often to check the latency and the latest block of the chain providers" default:"30s" testDefault:"$TESTINTERVAL"`
+	ProbeTimeout  time.Duration `help:"timeout of a chain provider check, slower providers are considered failing" default:"10s"`
+
+	Retries           int           `help:"number of times a request failing transiently is retried before failing over to the next provider" default:"3"`
+	RetryBackoff      time.Duration `help:"delay before the first retry of a request, doubled on each retry" default:"250ms"`
+	MaxRetryBackoff   time.Duration `help:"maximum delay before a retry of a request" default:"10s"`
+	RequestsPerSecond float64       `help:"maximum number of requests per second sent to each provider of the endpoints which don't configure it, 0 for unlimited" default:"0"`
+}
+
+// Clients is a pool of long-lived chain clients, one per provider URL of the endpoints. Clients are dialed on first
+// use and dialed again after a connection failure. The requests to each provider are rate limited and the requests
+// failing transiently are retried with exponential backoff. The providers of a chain are scored by their latency, their
+// consecutive failures and how far their latest block is behind the other providers, requests go to the provider with
+// the best score and fail over to the next ones when it's unavailable. The chain ID of each provider is verified
+// before its first request and on every probe, providers serving another chain than their endpoint are not used.
+//
+// architecture: Service
+type Clients struct {
+	log    *zap.Logger
+	config ClientsConfig
+
+	mu     sync.Mutex
+	chains map[string]*chain
+	closed bool
+
+	closeOnce sync.Once
+	stop      chan struct{}
+}
+
+// chain holds the providers of an endpoint.
+type chain struct {
+	endpoint  common.EthEndpoint
+	providers []*provider
+	// active is the provider of the last successful request.
+	active *provider
+}
+
+// provider holds the client and the health of a provider URL.
+type provider struct {
+	url     string
+	http    bool
+	limiter *rate.Limiter
+	client  *providerClient
+
+	// chainID is the chain ID reported by the provider, 0 until it's verified.
+	chainID  int64
+	latency  time.Duration
+	head     int64
+	lag      int64
+	failures int
+	lastErr  error
+}
+
+// score returns the score of the provider, the lower the better.
+func (provider *provider) score() time.Duration {
+	return provider.latency + time.Duration(provider.failures)*failurePenalty + time.Duration(provider.lag)*lagPenalty
+}
+
+// NewClients creates new chain clients pool.
+func NewClients(log *zap.Logger, config ClientsConfig) *Clients {
+	return &Clients{
+		log:    log,
+		config: config,
+		chains: make(map[string]*chain),
+		stop:   make(chan struct{}),
+	}
+}
+
+// Run periodically checks the latency and the latest block of the providers until the context is canceled or the
+// pool is closed.
+func (clients *Clients) Run(ctx context.Context) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-clients.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		clients.Probe(ctx)
+		if !sync2.Sleep(ctx, clients.config.ProbeInterval) {
+			return nil
+		}
+	}
+}
+
+// Do calls fn with the client of the best provider of the endpoint. When the provider is unavailable, fn is called
+// again with the client of the next provider. Errors returned by available providers, such as rejected queries, are
+// returned as is.
+func (clients *Clients) Do(ctx context.Context, endpoint common.EthEndpoint, fn func(client ChainClient) error) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	chain, providers, err := clients.rank(endpoint)
+	if err != nil {
+		return err
+	}
+
+	var group errs.Group
+	for i, provider := range providers {
+		client, err := clients.dial(ctx, endpoint, provider)
+		if err == nil {
+			if err = clients.verifyChainID(ctx, endpoint, provider, client); err == nil {
+				err = fn(client)
+			}
+			if ctx.Err() != nil {
+				return err
+			}
+			if !isProviderError(err) {
+				clients.succeeded(endpoint, chain, provider)
+				return err
+			}
+			clients.failed(endpoint, provider, err)
+		}
+		group.Add(err)
+
+		if i < len(providers)-1 {
+			mon.Counter("failovers", chainTag(endpoint)).Inc(1)
+			clients.log.Warn("chain provider failed, failing over to the next provider",
+				zap.Int64("Chain ID", endpoint.ChainID),
+				zap.String("Provider", redactURL(provider.url)),
+				zap.Error(err))
+		}
+	}
+	return group.Err()
+}
+
+// DoOthers calls fn with the clients of the providers of the endpoint other than the one of the served client, the
+// best scored first, until fn succeeded for n of them. It returns the number of providers for which fn succeeded along
+// with the errors of the others, so that requests can be confirmed by providers independent of the one which served
+// them. It fails when the served client isn't one of the pool anymore, as its provider can't be told apart.
+func (clients *Clients) DoOthers(ctx context.Context, endpoint common.EthEndpoint, served ChainClient, n int, fn func(client ChainClient) error) (succeeded int, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	_, providers, err := clients.rank(endpoint)
+	if err != nil {
+		return 0, err
+	}
+	clients.mu.Lock()
+	var active *provider
+	for _, provider := range providers {
+		if provider.client != nil && ChainClient(provider.client) == served {
+			active = provider
+		}
+	}
+	clients.mu.Unlock()
+	if active == nil {
+		return 0, ErrClients.New("served client is not a client of chain %d", endpoint.ChainID)
+	}
+
+	var group errs.Group
+	for _, provider := range providers {
+		if provider == active || succeeded >= n {
+			continue
+		}
+		client, err := clients.dial(ctx, endpoint, provider)
+		if err != nil {
+			group.Add(err)
+			continue
+		}
+		err = clients.verifyChainID(ctx, endpoint, provider, client)
+		if err == nil {
+			err = fn(client)
+		}
+		if err != nil {
+			if isProviderError(err) && ctx.Err() == nil {
+				clients.failed(endpoint, provider, err)
+			}
+			group.Add(err)
+			continue
+		}
+		succeeded++
+	}
+	return succeeded, group.Err()
+}
+
+// Subscribe calls fn with the client of the best WebSocket or IPC provider of the endpoint, once its chain ID is
+// verified. Subscriptions share the connection of the provider with the other requests, the provider is failed when
+// fn returns because the connection broke, so that the next subscription goes to another provider. fn isn't retried,
+// subscriptions are expected to run until they fail.
+func (clients *Clients) Subscribe(ctx context.Context, endpoint common.EthEndpoint, fn func(client ChainClient) error) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	_, providers, err := clients.rank(endpoint)
+	if err != nil {
+		return err
+	}
+	for _, provider := range providers {
+		if provider.http {
+			continue
+		}
+		client, err := clients.dial(ctx, endpoint, provider)
+		if err != nil {
+			return err
+		}
+		err = clients.verifyChainID(ctx, endpoint, provider, client)
+		if err == nil {
+			err = fn(client)
+		}
+		if isProviderError(err) && ctx.Err() == nil {
+			clients.failed(endpoint, provider, err)
+		}
+		return err
+	}
+	return ErrClients.New("no WebSocket or IPC URL configured for chain %d", endpoint.ChainID)
+}
+
+// VerifyChainIDs checks the chain ID of the providers of the endpoints. It returns an error if a provider serves
+// another chain than the one of its endpoint, providers which can't be reached are only logged. The chain IDs are
+// checked again on every probe.
+func (clients *Clients) VerifyChainIDs(ctx context.Context, endpoints []common.EthEndpoint) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	var group errs.Group
+	for _, endpoint := range endpoints {
+		_, providers, err := clients.rank(endpoint)
+		if err != nil {
+			return err
+		}
+		for _, provider := range providers {
+			err := clients.checkChainID(ctx, endpoint, provider)
+			switch {
+			case err == nil:
+			case ErrChainIDMismatch.Has(err):
+				group.Add(err)
+			default:
+				clients.log.Warn("failed to verify chain id of provider",
+					zap.Int64("Chain ID", endpoint.ChainID),
+					zap.String("Provider", redactURL(provider.url)),
+					zap.Error(err))
+			}
+		}
+	}
+	return group.Err()
+}
+
+// checkChainID asks the provider for its chain ID and verifies it.
+func (clients *Clients) checkChainID(ctx context.Context, endpoint common.EthEndpoint, provider *provider) error {
+	client, err := clients.dial(ctx, endpoint, provider)
+	if err != nil {
+		return err
+	}
+	reported, err := client.ChainID(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			clients.failed(endpoint, provider, err)
+		}
+		return err
+	}
+
+	clients.mu.Lock()
+	provider.chainID = reported.Int64()
+	clients.mu.Unlock()
+
+	err = clients.verifyChainID(ctx, endpoint, provider, client)
+	if err != nil {
+		clients.failed(endpoint, provider, err)
+	}
+	return err
+}
+
+// verifyChainID returns an error if the provider serves another chain than the one of the endpoint. The chain ID is
+// requested from providers which were not verified yet.
+func (clients *Clients) verifyChainID(ctx context.Context, endpoint common.EthEndpoint, provider *provider, client *providerClient) error {
+	clients.mu.Lock()
+	chainID := provider.chainID
+	clients.mu.Unlock()
+
+	if chainID == 0 {
+		reported, err := client.ChainID(ctx)
+		if err != nil {
+			return err
+		}
+		chainID = reported.Int64()
+
+		clients.mu.Lock()
+		provider.chainID = chainID
+		clients.mu.Unlock()
+	}
+	if chainID == endpoint.ChainID {
+		return nil
+	}
+
+	err := ErrChainIDMismatch.New("provider %s serves chain %d instead of chain %d", redactURL(provider.url), chainID, endpoint.ChainID)
+	mon.Event("chain_id_mismatch", chainTag(endpoint))
+	clients.log.Error("chain provider serves another chain",
+		zap.Int64("Chain ID", endpoint.ChainID),
+		zap.String("Name", endpoint.Name),
+		zap.String("Provider", redactURL(provider.url)),
+		zap.Int64("Reported Chain ID", chainID))
+	return err
+}
+
+// Probe checks the chain ID, the latency and the latest block of the providers of all the known endpoints.
+func (clients *Clients) Probe(ctx context.Context) {
+	clients.mu.Lock()
+	chains := make([]*chain, 0, len(clients.chains))
+	for _, chain := range clients.chains {
+		chains = append(chains, chain)
+	}
+	clients.mu.Unlock()
+
+	var group errgroup.Group
+	for _, chain := range chains {
+		for _, provider := range chain.providers {
+			group.Go(func() error {
+				clients.probe(ctx, chain.endpoint, provider)
+				return nil
+			})
+		}
+	}
+	_ = group.Wait()
+
+	clients.mu.Lock()
+	defer clients.mu.Unlock()
+
+	for _, chain := range chains {
+		var head int64
+		for _, provider := range chain.providers {
+			head = max(head, provider.head)
+		}
+		for _, provider := range chain.providers {
+			// providers never reached are scored by their failures only
+			if provider.head > 0 {
+				provider.lag = head - provider.head
+			}
+		}
+	}
+}
+
+// probe checks the chain ID, the latency and the latest block of the provider.
+func (clients *Clients) probe(ctx context.Context, endpoint common.EthEndpoint, provider *provider) {
+	probeCtx, cancel := context.WithTimeout(ctx, clients.config.ProbeTimeout)
+	defer cancel()
+
+	client, err := clients.dial(probeCtx, endpoint, provider)
+	if err != nil {
+		return
+	}
+	start := time.Now()
+	header, err := client.HeaderByNumber(probeCtx, nil)
+	latency := time.Since(start)
+	if err != nil {
+		// slow providers are failing as well, unless the pool is stopping
+		if ctx.Err() == nil {
+			clients.failed(endpoint, provider, err)
+		}
+		return
+	}
+	// the chain of a provider may change, for example when its URL is pointed at another node
+	if err := clients.checkChainID(probeCtx, endpoint, provider); err != nil {
+		return
+	}
+
+	clients.mu.Lock()
+	defer clients.mu.Unlock()
+
+	if provider.latency == 0 {
+		provider.latency = latency
+	} else {
+		provider.latency = time.Duration(latencyWeight*float64(latency) + (1-latencyWeight)*float64(provider.latency))
+	}
+	provider.head = header.Number.Int64()
+	provider.failures = 0
+	provider.lastErr = nil
+}
+
+// rank returns the chain of the endpoint and its providers, the best scored first.
+func (clients *Clients) rank(endpoint common.EthEndpoint) (*chain, []*provider, error) {
+	clients.mu.Lock()
+	defer clients.mu.Unlock()
+
+	if clients.closed {
+		return nil, nil, ErrClients.New("pool is closed")
+	}
+	chain := clients.chain(endpoint)
+	if len(chain.providers) == 0 {
+		return nil, nil, ErrClients.New("no URL configured for chain %d", endpoint.ChainID)
+	}
+	providers := slices.Clone(chain.providers)
+	slices.SortStableFunc(providers, func(a, b *provider) int {
+		return cmp.Compare(a.score(), b.score())
+	})
+	return chain, providers, nil
+}
+
+// chain returns the chain of the endpoint, adding it if it's not known yet. The mutex must be held.
+func (clients *Clients) chain(endpoint common.EthEndpoint) *chain {
+	urls := endpoint.GetURLs()
+	key := strconv.FormatInt(endpoint.ChainID, 10) + " " + strings.Join(urls, " ")
+	if existing, ok := clients.chains[key]; ok {
+		return existing
+	}
+	added := &chain{endpoint: endpoint}
+	for _, providerURL := range urls {
+		added.providers = append(added.providers, &provider{
+			url:     providerURL,
+			http:    strings.HasPrefix(providerURL, "http://") || strings.HasPrefix(providerURL, "https://"),
+			limiter: clients.config.newLimiter(endpoint),
+		})
+	}
+	clients.chains[key] = added
+	return added
+}
+
+// dial returns the client of the provider, dialing the provider if it's not connected. Providers are dialed without
+// holding the mutex, so that a slow provider doesn't block the requests to the other ones. When the provider was dialed
+// concurrently, the client installed first is kept.
+func (clients *Clients) dial(ctx context.Context, endpoint common.EthEndpoint, provider *provider) (_ *providerClient, err error) {
+	clients.mu.Lock()
+	closed, client := clients.closed, provider.client
+	clients.mu.Unlock()
+
+	if closed {
+		return nil, ErrClients.New("pool is closed")
+	}
+	if client != nil {
+		return client, nil
+	}
+
+	client, err = clients.connect(ctx, endpoint, provider)
+
+	clients.mu.Lock()
+	defer clients.mu.Unlock()
+
+	if err != nil {
+		mon.Counter("dial_failures", chainTag(endpoint)).Inc(1)
+		provider.failures++
+		provider.lastErr = err
+		clients.log.Warn("failed to dial chain provider",
+			zap.Int64("Chain ID", endpoint.ChainID),
+			zap.String("Name", endpoint.Name),
+			zap.String("Provider", redactURL(provider.url)),
+			zap.Error(err))
+		return nil, ErrClients.Wrap(err)
+	}
+	if clients.closed {
+		client.Close()
+		return nil, ErrClients.New("pool is closed")
+	}
+	if provider.client != nil {
+		client.Close()
+		return provider.client, nil
+	}
+	mon.Counter("dials", chainTag(endpoint)).Inc(1)
+	provider.client = client
+	return client, nil
+}
+
+// connect dials the provider.
+func (clients *Clients) connect(ctx context.Context, endpoint common.EthEndpoint, provider *provider) (*providerClient, error) {
+	var client *ethclient.Client
+	if provider.http {
+		rpcClient, err := rpc.DialOptions(ctx, provider.url, rpc.WithHTTPClient(&http.Client{
+			Transport: &retryTransport{
+				base:    http.DefaultTransport,
+				config:  clients.config,
+				limiter: provider.limiter,
+				tag:     chainTag(endpoint),
+			},
+		}))
+		if err != nil {
+			return nil, err
+		}
+		client = ethclient.NewClient(rpcClient)
+	} else {
+		var err error
+		client, err = dialContext(ctx, provider.url)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &providerClient{
+		client:   client,
+		log:      clients.log,
+		config:   clients.config,
+		endpoint: endpoint,
+		provider: provider,
+	}, nil
+}
+
+// dialContext dials a websocket or IPC provider. The websocket handshake doesn't stop when ctx is canceled, so it's
+// abandoned instead and the connection is closed once the dial finishes.
+func dialContext(ctx context.Context, url string) (*ethclient.Client, error) {
+	type result struct {
+		client *ethclient.Client
+		err    error
+	}
+	dialed := make(chan result, 1)
+	go func() {
+		client, err := ethclient.DialContext(ctx, url)
+		dialed <- result{client: client, err: err}
+	}()
+
+	select {
+	case result := <-dialed:
+		return result.client, result.err
+	case <-ctx.Done():
+		go func() {
+			if result := <-dialed; result.client != nil {
+				result.client.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+// succeeded records that the provider answered a request.
+func (clients *Clients) succeeded(endpoint common.EthEndpoint, chain *chain, provider *provider) {
+	clients.mu.Lock()
+	defer clients.mu.Unlock()
+
+	provider.failures = 0
+	provider.lastErr = nil
+	if chain.active != provider {
+		if chain.active != nil {
+			mon.Counter("provider_switches", chainTag(endpoint)).Inc(1)
+			clients.log.Info("switched chain provider",
+				zap.Int64("Chain ID", endpoint.ChainID),
+				zap.String("From", redactURL(chain.active.url)),
+				zap.String("To", redactURL(provider.url)))
+		}
+		chain.active = provider
+	}
+}
+
+// failed records a failure of the provider, closing its client if the connection failed so that the provider is
+// dialed again on next use.
+func (clients *Clients) failed(endpoint common.EthEndpoint, provider *provider, err error) {
+	clients.mu.Lock()
+	defer clients.mu.Unlock()
+
+	mon.Counter("provider_failures", chainTag(endpoint)).Inc(1)
+	provider.failures++
+	provider.lastErr = err
+	if isConnectionError(err) && provider.client != nil {
+		provider.client.Close()
+		provider.client = nil
+		mon.Counter("resets", chainTag(endpoint)).Inc(1)
+	}
+}
+
+// ProviderStatus is the health of a chain provider.
+type ProviderStatus struct {
+	// URL is the provider URL without path, query and credentials, which often contain API keys.
+	URL string
+	// Active is set for the provider of the last successful request.
+	Active bool
+	// ChainID is the chain ID reported by the provider, 0 until it's verified.
+	ChainID  int64
+	Latency  time.Duration
+	Head     int64
+	Lag      int64
+	Failures int
+	Error    string
+}
+
+// ChainProviders is the health of the providers of a chain.
+type ChainProviders struct {
+	ChainID   int64
+	Name      string
+	Providers []ProviderStatus
+}
+
+// Status returns the health of the providers of the endpoints.
+func (clients *Clients) Status(endpoints []common.EthEndpoint) []ChainProviders {
+	clients.mu.Lock()
+	defer clients.mu.Unlock()
+
+	status := make([]ChainProviders, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		chain := clients.chain(endpoint)
+		chainStatus := ChainProviders{
+			ChainID: endpoint.ChainID,
+			Name:    endpoint.Name,
+		}
+		for _, provider := range chain.providers {
+			providerStatus := ProviderStatus{
+				URL:      redactURL(provider.url),
+				Active:   provider == chain.active,
+				ChainID:  provider.chainID,
+				Latency:  provider.latency,
+				Head:     provider.head,
+				Lag:      provider.lag,
+				Failures: provider.failures,
+			}
+			if provider.lastErr != nil {
+				// errors often quote the request URL
+				providerStatus.Error = strings.ReplaceAll(provider.lastErr.Error(), provider.url, providerStatus.URL)
+			}
+			chainStatus.Providers = append(chainStatus.Providers, providerStatus)
+		}
+		status = append(status, chainStatus)
+	}
+	return status
+}
+
+// Close stops the probes and closes all the clients of the pool.
+func (clients *Clients) Close() error {
+	clients.closeOnce.Do(func() { close(clients.stop) })
+
+	clients.mu.Lock()
+	defer clients.mu.Unlock()
+
+	for _, chain := range clients.chains {
+		for _, provider := range chain.providers {
+			if provider.client != nil {
+				provider.client.Close()
+				provider.client = nil
+			}
+		}
+	}
+	clients.closed = true
+	return nil
+}
+
+// isProviderError reports whether the error is caused by an unavailable provider or a provider serving another
+// chain, rather than by the request.
+func isProviderError(err error) bool {
+	if ErrChainIDMismatch.Has(err) {
+		return true
+	}
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests || httpErr.StatusCode >= http.StatusInternalServerError
+	}
+	return isConnectionError(err)
+}
+
+// isConnectionError reports whether the error is caused by a closed or broken connection to the provider.
+func isConnectionError(err error) bool {
+	// canceled requests don't tell anything about the connection
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	return errors.Is(err, rpc.ErrClientQuit) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.As(err, &netErr)
+}
+
+// redactURL returns the URL without path, query and credentials, which often contain API keys. IPC paths are
+// returned as is.
+func redactURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "invalid URL"
+	}
+	if parsed.Host == "" {
+		return rawURL
+	}
+	return parsed.Scheme + "://" + parsed.Host
+}
+
+// chainTag returns the monkit series tag of the endpoint chain.
+func chainTag(endpoint common.EthEndpoint) monkit.SeriesTag {
+	return monkit.NewSeriesTag("chain_id", strconv.FormatInt(endpoint.ChainID, 10))
+}
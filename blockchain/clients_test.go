@@ -0,0 +1,224 @@
+// Copyright (C) 2024 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package blockchain_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"storj.io/common/testcontext"
+	"storj.io/storjscan/blockchain"
+	"storj.io/storjscan/common"
+	"storj.io/storjscan/private/testeth"
+)
+
+func TestClients(t *testing.T) {
+	testeth.Run(t, 1, 1, func(ctx *testcontext.Context, t *testing.T, networks []*testeth.Network) {
+		network := networks[0]
+		endpoint := common.EthEndpoint{
+			Name:    "Test",
+			URL:     network.HTTPEndpoint(),
+			ChainID: network.ChainID().Int64(),
+		}
+
+		clients := blockchain.NewClients(zaptest.NewLogger(t), blockchain.ClientsConfig{})
+		defer ctx.Check(clients.Close)
+
+		var first blockchain.ChainClient
+		err := clients.Do(ctx, endpoint, func(client blockchain.ChainClient) error {
+			first = client
+			_, err := client.HeaderByNumber(ctx, nil)
+			return err
+		})
+		require.NoError(t, err)
+
+		// the connected client is reused
+		err = clients.Do(ctx, endpoint, func(client blockchain.ChainClient) error {
+			require.Same(t, first, client)
+			return nil
+		})
+		require.NoError(t, err)
+
+		// request errors are returned without failing over
+		requestErr := errors.New("execution reverted")
+		calls := 0
+		err = clients.Do(ctx, endpoint, func(client blockchain.ChainClient) error {
+			calls++
+			return requestErr
+		})
+		require.ErrorIs(t, err, requestErr)
+		require.Equal(t, 1, calls)
+
+		require.NoError(t, clients.Close())
+		err = clients.Do(ctx, endpoint, func(client blockchain.ChainClient) error { return nil })
+		require.Error(t, err)
+		require.True(t, blockchain.ErrClients.Has(err))
+	})
+}
+
+func TestClientsFailover(t *testing.T) {
+	testeth.Run(t, 1, 1, func(ctx *testcontext.Context, t *testing.T, networks []*testeth.Network) {
+		network := networks[0]
+		endpoint := common.EthEndpoint{
+			Name:    "Test",
+			URL:     "http://127.0.0.1:1",
+			URLs:    []string{network.HTTPEndpoint()},
+			ChainID: network.ChainID().Int64(),
+		}
+
+		clients := blockchain.NewClients(zaptest.NewLogger(t), blockchain.ClientsConfig{ProbeTimeout: time.Minute})
+		defer ctx.Check(clients.Close)
+
+		// the unavailable provider fails over to the next one
+		err := clients.Do(ctx, endpoint, func(client blockchain.ChainClient) error {
+			_, err := client.HeaderByNumber(ctx, nil)
+			return err
+		})
+		require.NoError(t, err)
+
+		status := clients.Status([]common.EthEndpoint{endpoint})
+		require.Len(t, status, 1)
+		require.Len(t, status[0].Providers, 2)
+		require.False(t, status[0].Providers[0].Active)
+		require.Equal(t, 1, status[0].Providers[0].Failures)
+		require.NotEmpty(t, status[0].Providers[0].Error)
+		require.True(t, status[0].Providers[1].Active)
+		require.Zero(t, status[0].Providers[1].Failures)
+
+		// the failing provider is scored lower, requests go to the available one first
+		calls := 0
+		err = clients.Do(ctx, endpoint, func(client blockchain.ChainClient) error {
+			calls++
+			_, err := client.HeaderByNumber(ctx, nil)
+			return err
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, calls)
+
+		clients.Probe(ctx)
+		status = clients.Status([]common.EthEndpoint{endpoint})
+		require.Equal(t, 2, status[0].Providers[0].Failures)
+		require.Positive(t, status[0].Providers[1].Latency)
+		require.Zero(t, status[0].Providers[1].Lag)
+	})
+}
+
+func TestClientsChainIDMismatch(t *testing.T) {
+	testeth.Run(t, 2, 1, func(ctx *testcontext.Context, t *testing.T, networks []*testeth.Network) {
+		// the first provider serves another chain
+		endpoint := common.EthEndpoint{
+			Name:    "Test",
+			URL:     networks[1].HTTPEndpoint(),
+			URLs:    []string{networks[0].HTTPEndpoint()},
+			ChainID: networks[0].ChainID().Int64(),
+		}
+
+		clients := blockchain.NewClients(zaptest.NewLogger(t), blockchain.ClientsConfig{ProbeTimeout: time.Minute})
+		defer ctx.Check(clients.Close)
+
+		err := clients.VerifyChainIDs(ctx, []common.EthEndpoint{endpoint})
+		require.True(t, blockchain.ErrChainIDMismatch.Has(err))
+
+		status := clients.Status([]common.EthEndpoint{endpoint})
+		require.Len(t, status[0].Providers, 2)
+		require.Equal(t, networks[1].ChainID().Int64(), status[0].Providers[0].ChainID)
+		require.Positive(t, status[0].Providers[0].Failures)
+		require.Equal(t, networks[0].ChainID().Int64(), status[0].Providers[1].ChainID)
+		require.Zero(t, status[0].Providers[1].Failures)
+
+		// requests are only served by the provider of the configured chain
+		for range 2 {
+			err = clients.Do(ctx, endpoint, func(client blockchain.ChainClient) error {
+				chainID, err := client.ChainID(ctx)
+				if err != nil {
+					return err
+				}
+				require.Equal(t, networks[0].ChainID(), chainID)
+				return nil
+			})
+			require.NoError(t, err)
+		}
+
+		// the mismatch is detected again by the probes
+		clients.Probe(ctx)
+		status = clients.Status([]common.EthEndpoint{endpoint})
+		require.Positive(t, status[0].Providers[0].Failures)
+		require.Contains(t, status[0].Providers[0].Error, "chain id mismatch")
+		require.True(t, status[0].Providers[1].Active)
+
+		// without another provider the requests fail
+		endpoint.URLs = nil
+		err = clients.Do(ctx, endpoint, func(client blockchain.ChainClient) error {
+			return nil
+		})
+		require.True(t, blockchain.ErrChainIDMismatch.Has(err))
+	})
+}
+
+func TestClientsSlowDial(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	// the WebSocket provider accepts connections but never answers the handshake
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ctx.Check(listener.Close)
+	accepted := make(chan net.Conn, 1)
+	ctx.Go(func() error {
+		conn, err := listener.Accept()
+		if err == nil {
+			accepted <- conn
+		}
+		return nil
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID json.RawMessage `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"0x539"}`, req.ID)
+	}))
+	defer server.Close()
+
+	slow := common.EthEndpoint{Name: "Slow", URL: "ws://" + listener.Addr().String(), ChainID: 1}
+	fast := common.EthEndpoint{Name: "Fast", URL: server.URL, ChainID: 1337}
+
+	clients := blockchain.NewClients(zaptest.NewLogger(t), blockchain.ClientsConfig{})
+	defer ctx.Check(clients.Close)
+
+	dialCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	dialed := make(chan error, 1)
+	go func() {
+		dialed <- clients.Do(dialCtx, slow, func(client blockchain.ChainClient) error { return nil })
+	}()
+	conn := <-accepted
+	defer func() { _ = conn.Close() }()
+
+	// the other chains and the status are served while the slow provider is dialed
+	err = clients.Do(ctx, fast, func(client blockchain.ChainClient) error {
+		_, err := client.ChainID(ctx)
+		return err
+	})
+	require.NoError(t, err)
+	status := clients.Status([]common.EthEndpoint{slow, fast})
+	require.Len(t, status, 2)
+	require.True(t, status[1].Providers[0].Active)
+
+	cancel()
+	require.Error(t, <-dialed)
+}
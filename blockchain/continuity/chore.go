@@ -0,0 +1,233 @@
+// Copyright (C) 2024 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package continuity
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"math/big"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	"storj.io/common/sync2"
+	"storj.io/storjscan/blockchain"
+	"storj.io/storjscan/common"
+)
+
+var mon = monkit.Package()
+
+// ErrChore is an error class for the headers continuity chore.
+var ErrChore = errs.Class("continuity chore")
+
+// pageSize is the number of headers read from the db at once.
+const pageSize = 1000
+
+// Config is a configuration struct for the Chore.
+type Config struct {
+	Interval time.Duration `help:"how often to verify the continuity of the cached block headers" default:"1h" testDefault:"$TESTINTERVAL"`
+	Prune    bool          `help:"delete the cached block headers which are not part of the canonical chain" default:"false"`
+}
+
+// Report is the result of the verification of the cached headers of a chain.
+type Report struct {
+	ChainID int64
+	// Headers is the number of verified headers.
+	Headers int
+	// Gaps is the number of ranges of blocks without cached headers, where continuity can't be verified.
+	Gaps int
+	// Forks is the number of block numbers with several cached headers.
+	Forks int
+	// Breaks is the number of cached headers whose parent is not the cached header of the previous block.
+	Breaks int
+	// Orphaned are the cached headers which are not part of the canonical chain.
+	Orphaned []blockchain.Header
+}
+
+// Chore to verify that the cached block headers of each chain form a continuous chain, and to prune the orphaned
+// headers of blocks which were reorganized out of the chain.
+//
+// architecture: Chore
+type Chore struct {
+	log       *zap.Logger
+	db        blockchain.HeadersDB
+	chains    blockchain.Chains
+	endpoints []common.EthEndpoint
+	config    Config
+
+	Loop *sync2.Cycle
+}
+
+// NewChore creates new chore for verifying the continuity of the block headers.
+func NewChore(log *zap.Logger, db blockchain.HeadersDB, chains blockchain.Chains, endpoints []common.EthEndpoint, config Config) *Chore {
+	return &Chore{
+		log:       log,
+		db:        db,
+		chains:    chains,
+		endpoints: endpoints,
+		config:    config,
+
+		Loop: sync2.NewCycle(config.Interval),
+	}
+}
+
+// Run starts the chore.
+func (chore *Chore) Run(ctx context.Context) (err error) {
+	defer mon.Task()(&ctx)(&err)
+	return chore.Loop.Run(ctx, func(ctx context.Context) error {
+		err := chore.RunOnce(ctx)
+		if err != nil {
+			chore.log.Error("error running block headers continuity chore", zap.Error(ErrChore.Wrap(err)))
+		}
+		return nil
+	})
+}
+
+// RunOnce verifies the cached headers of all the chains, and prunes the orphaned headers if configured to.
+func (chore *Chore) RunOnce(ctx context.Context) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	var group errs.Group
+	for _, endpoint := range chore.endpoints {
+		report, err := chore.Verify(ctx, endpoint)
+		if err != nil {
+			group.Add(errs.New("chain %d: %v", endpoint.ChainID, err))
+			continue
+		}
+		chore.log.Debug("verified block headers continuity", zap.Int64("Chain ID", report.ChainID), zap.Int("Headers", report.Headers),
+			zap.Int("Gaps", report.Gaps), zap.Int("Forks", report.Forks), zap.Int("Breaks", report.Breaks), zap.Int("Orphaned", len(report.Orphaned)))
+		mon.IntVal("orphaned_headers", monkit.NewSeriesTag("chain_id", strconv.FormatInt(endpoint.ChainID, 10))).Observe(int64(len(report.Orphaned)))
+
+		for _, header := range report.Orphaned {
+			chore.log.Warn("cached block header is not part of the canonical chain", zap.Int64("Chain ID", header.ChainID),
+				zap.Int64("Block Number", header.Number), zap.String("Hash", header.Hash.Hex()), zap.Time("Timestamp", header.Timestamp))
+			if !chore.config.Prune {
+				continue
+			}
+			if err := chore.db.Delete(ctx, header.ChainID, header.Hash); err != nil && !errors.Is(err, blockchain.ErrNoHeader) {
+				group.Add(err)
+			}
+		}
+	}
+	return group.Err()
+}
+
+// Verify walks the cached headers of the endpoint chain by block number, linking the headers of consecutive blocks by
+// their parent hash. The block numbers with several cached headers, or whose headers aren't linked to the headers of
+// the previous block, are checked against the canonical chain to find the orphaned headers.
+func (chore *Chore) Verify(ctx context.Context, endpoint common.EthEndpoint) (report Report, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	report.ChainID = endpoint.ChainID
+	suspects := make(map[int64][]blockchain.Header)
+
+	var previous, current []blockchain.Header
+	// verify checks the headers of the current block number against the headers of the previous one
+	verify := func() {
+		report.Headers += len(current)
+		if len(current) > 1 {
+			report.Forks++
+			suspects[current[0].Number] = current
+		}
+		switch {
+		case previous == nil:
+		case current[0].Number != previous[0].Number+1:
+			report.Gaps++
+		default:
+			for _, header := range current {
+				if header.ParentHash == (common.Hash{}) || containsHash(previous, header.ParentHash) {
+					continue
+				}
+				report.Breaks++
+				suspects[previous[0].Number] = previous
+				suspects[current[0].Number] = current
+			}
+		}
+		previous, current = current, nil
+	}
+
+	var cursor *blockchain.HeadersCursor
+	for {
+		page, err := chore.db.List(ctx, endpoint.ChainID, cursor, pageSize)
+		if err != nil {
+			return Report{}, err
+		}
+		for _, header := range page.Headers {
+			if len(current) > 0 && header.Number != current[0].Number {
+				verify()
+			}
+			current = append(current, header)
+		}
+		if page.Next == nil {
+			break
+		}
+		cursor = page.Next
+	}
+	if len(current) > 0 {
+		verify()
+	}
+
+	for _, number := range slices.Sorted(maps.Keys(suspects)) {
+		canonical, err := chore.canonicalHash(ctx, endpoint, number)
+		if errors.Is(err, ethereum.NotFound) {
+			// the provider may be behind the chain, the headers are checked again on the next run
+			chore.log.Debug("block not found on the chain", zap.Int64("Chain ID", endpoint.ChainID), zap.Int64("Block Number", number))
+			continue
+		}
+		if err != nil {
+			return Report{}, err
+		}
+		for _, header := range suspects[number] {
+			if header.Hash != canonical {
+				report.Orphaned = append(report.Orphaned, header)
+			}
+		}
+	}
+	return report, nil
+}
+
+// canonicalHash returns the hash of the canonical block of the chain with the given number. The hash is taken from
+// the parent hash of the next block when there is one, as the hashes computed by go-ethereum don't match the hashes of
+// every chain.
+func (chore *Chore) canonicalHash(ctx context.Context, endpoint common.EthEndpoint, number int64) (hash common.Hash, err error) {
+	err = chore.chains.Do(ctx, endpoint, func(client blockchain.ChainClient) error {
+		next, err := client.HeaderByNumber(ctx, big.NewInt(number+1))
+		if err == nil {
+			hash = next.ParentHash
+			return nil
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			return err
+		}
+		header, err := client.HeaderByNumber(ctx, big.NewInt(number))
+		if err != nil {
+			return err
+		}
+		hash = header.Hash()
+		return nil
+	})
+	return hash, err
+}
+
+// containsHash returns true if one of the headers has the given hash.
+func containsHash(headers []blockchain.Header, hash common.Hash) bool {
+	for _, header := range headers {
+		if header.Hash == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// Close stops the chore.
+func (chore *Chore) Close() error {
+	chore.Loop.Close()
+	return nil
+}
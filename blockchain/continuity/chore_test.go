@@ -0,0 +1,88 @@
+// Copyright (C) 2024 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package continuity_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"storj.io/common/testcontext"
+	"storj.io/storjscan/blockchain"
+	"storj.io/storjscan/blockchain/continuity"
+	"storj.io/storjscan/common"
+	"storj.io/storjscan/private/testchain"
+	"storj.io/storjscan/storjscandb/storjscandbtest"
+)
+
+func TestChore(t *testing.T) {
+	storjscandbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db *storjscandbtest.DB) {
+		chain := testchain.New(1337)
+		var hashes []common.Hash
+		for range 5 {
+			hashes = append(hashes, chain.Mine().Hash())
+		}
+
+		// blocks 1 to 3 and 5 are cached, block 4 is missing
+		cache := blockchain.NewHeadersCache(zaptest.NewLogger(t), db.Headers(), blockchain.HeadersCacheConfig{})
+		_, err := cache.GetMany(ctx, chain, 1337, []common.Hash{hashes[0], hashes[1], hashes[2], hashes[4]})
+		require.NoError(t, err)
+
+		canonical, err := db.Headers().Get(ctx, 1337, hashes[1])
+		require.NoError(t, err)
+
+		// blocks 2 and 3 of side chains
+		orphaned := blockchain.Header{
+			ChainID:    1337,
+			Hash:       common.Hash{2},
+			Number:     2,
+			Timestamp:  canonical.Timestamp.Add(time.Second),
+			ParentHash: hashes[0],
+		}
+		orphanedSibling := blockchain.Header{
+			ChainID:    1337,
+			Hash:       common.Hash{3},
+			Number:     3,
+			Timestamp:  canonical.Timestamp.Add(2 * time.Second),
+			ParentHash: common.Hash{4},
+		}
+		require.NoError(t, db.Headers().InsertMany(ctx, []blockchain.Header{orphaned, orphanedSibling}))
+
+		endpoint := common.EthEndpoint{ChainID: 1337}
+		chore := continuity.NewChore(zaptest.NewLogger(t), db.Headers(), chain, []common.EthEndpoint{endpoint}, continuity.Config{
+			Interval: time.Hour,
+			Prune:    true,
+		})
+		defer ctx.Check(chore.Close)
+
+		report, err := chore.Verify(ctx, endpoint)
+		require.NoError(t, err)
+		require.Equal(t, 6, report.Headers)
+		require.Equal(t, 1, report.Gaps)
+		require.Equal(t, 2, report.Forks)
+		require.Equal(t, 1, report.Breaks)
+		require.ElementsMatch(t, []blockchain.Header{orphaned, orphanedSibling}, report.Orphaned)
+
+		// the orphaned headers are pruned
+		require.NoError(t, chore.RunOnce(ctx))
+		for _, header := range []blockchain.Header{orphaned, orphanedSibling} {
+			_, err = db.Headers().Get(ctx, 1337, header.Hash)
+			require.ErrorIs(t, err, blockchain.ErrNoHeader)
+		}
+		for _, hash := range []common.Hash{hashes[0], hashes[1], hashes[2], hashes[4]} {
+			_, err = db.Headers().Get(ctx, 1337, hash)
+			require.NoError(t, err)
+		}
+
+		report, err = chore.Verify(ctx, endpoint)
+		require.NoError(t, err)
+		require.Equal(t, 4, report.Headers)
+		require.Equal(t, 1, report.Gaps)
+		require.Zero(t, report.Forks)
+		require.Zero(t, report.Breaks)
+		require.Empty(t, report.Orphaned)
+	})
+}
@@ -0,0 +1,64 @@
+// Copyright (C) 2024 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	"storj.io/common/sync2"
+	"storj.io/storjscan/common"
+)
+
+// ErrChore is an error class for the transfer events indexer chore.
+var ErrChore = errs.Class("Chore")
+
+// Chore to index transfer events to claimed wallets into the ledger.
+//
+// architecture: Chore
+type Chore struct {
+	log       *zap.Logger
+	service   *Service
+	endpoints []common.EthEndpoint
+
+	Loop *sync2.Cycle
+}
+
+// NewChore creates new chore for indexing transfer events.
+func NewChore(log *zap.Logger, service *Service, endpoints []common.EthEndpoint, interval time.Duration) *Chore {
+	return &Chore{
+		log:       log,
+		service:   service,
+		endpoints: endpoints,
+		Loop:      sync2.NewCycle(interval),
+	}
+}
+
+// Run starts the chore.
+func (chore *Chore) Run(ctx context.Context) (err error) {
+	defer mon.Task()(&ctx)(&err)
+	return chore.Loop.Run(ctx, func(ctx context.Context) error {
+		err := chore.RunOnce(ctx)
+		if err != nil {
+			chore.log.Error("Error running transfer events indexer chore", zap.Error(ErrChore.Wrap(err)))
+			return nil
+		}
+		return nil
+	})
+}
+
+// RunOnce indexes new transfer events of all endpoints.
+func (chore *Chore) RunOnce(ctx context.Context) (err error) {
+	defer mon.Task()(&ctx)(&err)
+	return chore.service.Index(ctx, chore.endpoints)
+}
+
+// Close stops the chore.
+func (chore *Chore) Close() error {
+	chore.Loop.Close()
+	return nil
+}
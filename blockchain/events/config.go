@@ -3,10 +3,29 @@
 
 package events
 
+import "time"
+
 // Config is a configuration struct for the transfer events service.
 type Config struct {
-	AddressBatchSize int `help:"number of Addresses to fetch new events for in a single request" default:"100"`
-	BlockBatchSize   int `help:"number of blocks to fetch new events for in a single request" default:"5000"`
-	ChainReorgBuffer int `help:"minimum number of blocks to re-query for when looking for new transfer events" default:"15"`
-	MaximumQuerySize int `help:"maximum number of blocks prior to the latest block that storjscan can query for" default:"10000"`
+	AddressBatchSize      int  `help:"number of Addresses to fetch new events for in a single request" default:"100"`
+	ContractScanThreshold int  `help:"number of wallets from which all transfer logs of the token contracts are fetched and matched against the wallets locally instead of being queried per address batch, 0 to always query per address batch" default:"10000"`
+	BlockBatchSize        int  `help:"number of blocks to fetch new events for in a single request" default:"5000"`
+	ChainReorgBuffer      int  `help:"minimum number of blocks to re-query for when looking for new transfer events" default:"15"`
+	MaximumQuerySize      int  `help:"maximum number of blocks prior to the latest block that storjscan can query for" default:"10000"`
+	ReorgCheckDepth       int  `help:"number of blocks prior to the latest block in which reported transfer events are checked for chain reorganizations" default:"128"`
+	Outgoing              bool `help:"also look for transfer events sent from the wallets" default:"false"`
+	Native                bool `help:"also look for native currency transfers to the wallets in the blocks changing their balance or nonce, including internal transfers if the node supports call tracing" default:"false"`
+
+	ScanTimeout time.Duration `help:"maximum duration of a satellite scan, which is shared by the concurrent requests of the satellite and continues when the request which started it is canceled, 0 for no limit" default:"5m"`
+
+	Indexer IndexerConfig
+}
+
+// IndexerConfig is a configuration struct for the background transfer events indexer.
+type IndexerConfig struct {
+	Enabled  bool          `help:"index transfer events in the background and serve payments from the ledger" default:"false"`
+	Interval time.Duration `help:"how often to scan the chains for new transfer events" default:"1m" testDefault:"$TESTINTERVAL"`
+
+	Subscribe        bool          `help:"subscribe to the transfer logs of WebSocket and IPC endpoints to index payments as soon as they are mined" default:"false"`
+	ResubscribeDelay time.Duration `help:"how long to wait before subscribing again after a subscription failed" default:"10s"`
 }
@@ -0,0 +1,66 @@
+// Copyright (C) 2024 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package events
+
+import (
+	"context"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/storjscan/blockchain"
+	"storj.io/storjscan/common"
+)
+
+// ErrNoIndexedBlock is returned when a chain has not been indexed yet.
+var ErrNoIndexedBlock = errs.New("TransferEventsDB: chain not indexed yet")
+
+// DB is a ledger of transfer events to claimed wallets, populated by the indexer.
+//
+// architecture: Database
+type DB interface {
+	// Replace replaces the transfer events of the chain within the given block range (inclusive), dropping the
+	// events of blocks which are no longer part of the chain.
+	Replace(ctx context.Context, chainID, from, to int64, events []TransferEvent) error
+	// ListBySatellite returns transfer events to wallets claimed by the satellite, or from them if outgoing is set,
+	// within the given block range (inclusive).
+	ListBySatellite(ctx context.Context, satellite string, chainID, from, to int64, outgoing bool) ([]TransferEvent, error)
+	// ListByAddress returns transfer events to the given addresses, or from them if outgoing is set,
+	// within the given block range (inclusive).
+	ListByAddress(ctx context.Context, addresses []common.Address, chainID, from, to int64, outgoing bool) ([]TransferEvent, error)
+	// GetIndexedBlock returns the header of the last block indexed for the chain.
+	GetIndexedBlock(ctx context.Context, chainID int64) (blockchain.Header, error)
+	// SetIndexedBlock stores the header of the last block indexed for its chain.
+	SetIndexedBlock(ctx context.Context, header blockchain.Header) error
+}
+
+// WatermarksDB stores the last scanned block per satellite and chain, so that scanning
+// resumes from the same point after restarts and across replicas.
+//
+// architecture: Database
+type WatermarksDB interface {
+	// Get returns the scan watermarks of the satellite, keyed by chain id.
+	Get(ctx context.Context, satellite string) (map[int64]int64, error)
+	// Set stores the scan watermarks of the satellite, keyed by chain id.
+	Set(ctx context.Context, satellite string, watermarks map[int64]int64) error
+}
+
+// ReportedDB keeps track of the transfer events reported to the satellites, so that the events of blocks
+// orphaned by a chain reorganization can be reported as removed.
+//
+// architecture: Database
+type ReportedDB interface {
+	// Insert records transfer events reported to the satellite, reviving them if they were removed before.
+	Insert(ctx context.Context, satellite string, events []TransferEvent) error
+	// List returns the transfer events of the chain reported to the satellite from the given block number, which are
+	// not removed.
+	List(ctx context.Context, satellite string, chainID, from int64) ([]TransferEvent, error)
+	// ListRemoved returns the removed transfer events of the chain reported to the satellite.
+	ListRemoved(ctx context.Context, satellite string, chainID int64) ([]TransferEvent, error)
+	// Remove marks the transfer events of the block reported to the satellite as removed at the given latest block
+	// number of the chain.
+	Remove(ctx context.Context, satellite string, chainID int64, blockHash common.Hash, latest int64) error
+	// DeleteBefore deletes the reported transfer events of the chain before the given block number, and the removed
+	// ones which were removed before it.
+	DeleteBefore(ctx context.Context, chainID, before int64) error
+}
@@ -5,12 +5,22 @@ package events
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/spacemonkeygo/monkit/v3"
 	"github.com/zeebo/errs"
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
 
 	"storj.io/common/currency"
 	"storj.io/storjscan/blockchain"
@@ -19,7 +29,10 @@ import (
 	"storj.io/storjscan/wallets"
 )
 
-// TransferEvent holds a transfer event raised by an ERC20 contract.
+var mon = monkit.Package()
+
+// TransferEvent holds a transfer event raised by an ERC20 contract, or a native currency transfer, in which case
+// Contract is common.NativeAddress.
 type TransferEvent struct {
 	ChainID     int64
 	From        common.Address
@@ -28,191 +41,785 @@ type TransferEvent struct {
 	BlockNumber int64
 	TxHash      common.Hash
 	LogIndex    int
-	TokenValue  currency.Amount
+	// TraceIndex identifies native transfers, which don't emit logs, by the position of their call in the trace of the
+	// transaction at the LogIndex position of the block, the transaction itself being 1. It's 0 for token transfers.
+	TraceIndex int
+	// Contract is the address of the transferred token, TokenValue holds its base units.
+	Contract   common.Address
+	TokenValue common.TokenAmount
+	// Outgoing is set for transfers sent from one of the wallets instead of to them.
+	Outgoing bool
 }
 
 // Service for blockchain transfer events.
 type Service struct {
-	log       *zap.Logger
-	walletsDB wallets.DB
+	log          *zap.Logger
+	clients      blockchain.Chains
+	walletsDB    wallets.DB
+	db           DB
+	watermarksDB WatermarksDB
+	reportedDB   ReportedDB
 
 	config Config
-	// map satellite and chainID to the last scanned block number
-	lastScannedBlock map[string]map[int64]int64
+
+	// scans coalesces concurrent scans of the same satellite, chain and start block.
+	scans singleflight.Group
 }
 
 // NewEventsService creates a new transfer events service.
-func NewEventsService(log *zap.Logger, walletsDB wallets.DB, config Config) *Service {
-	lastScannedBlock := make(map[string]map[int64]int64)
+func NewEventsService(log *zap.Logger, clients blockchain.Chains, walletsDB wallets.DB, db DB, watermarksDB WatermarksDB, reportedDB ReportedDB, config Config) *Service {
 	return &Service{
-		log:              log,
-		walletsDB:        walletsDB,
-		config:           config,
-		lastScannedBlock: lastScannedBlock,
+		log:          log,
+		clients:      clients,
+		walletsDB:    walletsDB,
+		db:           db,
+		watermarksDB: watermarksDB,
+		reportedDB:   reportedDB,
+		config:       config,
 	}
 }
 
 // GetForSatellite returns with the latest transfer events from the blockchain for a given satellite.
-func (events *Service) GetForSatellite(ctx context.Context, endpoints []common.EthEndpoint, satelliteID string, from map[int64]int64) (map[int64]blockchain.Header, []TransferEvent, error) {
-	lastScan := events.lastScannedBlock[satelliteID]
-	for chain, block := range lastScan {
-		if from[chain] < block {
-			from[chain] = block
-		}
+// Concurrent calls scanning the same chain from the same block for the same satellite are coalesced
+// into a single scan and all callers receive its result. The scan is limited by ScanTimeout instead of the
+// context of the call which started it, so that canceling one call doesn't fail the others.
+// Chains scanned only up to MaximumQuerySize blocks after the starting block are reported as partial,
+// their returned header being the last scanned block. Chains whose native transfers failed to be scanned are
+// returned with their token transfer events, along with ErrNativeScan errors.
+func (events *Service) GetForSatellite(ctx context.Context, endpoints []common.EthEndpoint, satelliteID string, from map[int64]int64) (map[int64]blockchain.Header, []TransferEvent, map[int64]bool, error) {
+	scan, err := events.ForSatellite(ctx, satelliteID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return scan.Get(ctx, endpoints, from)
+}
+
+// SatelliteScan scans the chains for the transfer events of a satellite. The satellite wallets and scan watermarks
+// are loaded once and shared by the scans of all the chains.
+type SatelliteScan struct {
+	events      *Service
+	satelliteID string
+	lastScan    map[int64]int64
+	walletsList []common.Address
+}
+
+// ForSatellite loads the state needed to scan the chains for the transfer events of the satellite.
+func (events *Service) ForSatellite(ctx context.Context, satelliteID string) (_ *SatelliteScan, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	scan := &SatelliteScan{events: events, satelliteID: satelliteID}
+	if events.config.Indexer.Enabled {
+		return scan, nil
+	}
+	scan.lastScan, err = events.watermarksDB.Get(ctx, satelliteID)
+	if err != nil {
+		return nil, err
 	}
 	wallets, err := events.walletsDB.ListBySatellite(ctx, satelliteID)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
-	walletsList := make([]common.Address, 0, len(wallets))
+	scan.walletsList = make([]common.Address, 0, len(wallets))
 	for wallet := range wallets {
-		walletsList = append(walletsList, wallet)
+		scan.walletsList = append(scan.walletsList, wallet)
+	}
+	return scan, nil
+}
+
+// Get returns the latest transfer events of the satellite on the endpoint chains, the same way as GetForSatellite.
+func (scan *SatelliteScan) Get(ctx context.Context, endpoints []common.EthEndpoint, from map[int64]int64) (map[int64]blockchain.Header, []TransferEvent, map[int64]bool, error) {
+	events := scan.events
+	return events.scanChains(ctx, endpoints, func(ctx context.Context, endpoint common.EthEndpoint) (chainScan, error) {
+		start := max(from[endpoint.ChainID], scan.lastScan[endpoint.ChainID])
+		key := fmt.Sprintf("%s/%d/%d", scan.satelliteID, endpoint.ChainID, start)
+		results := events.scans.DoChan(key, func() (any, error) {
+			// the scan is shared with the callers which joined it, it doesn't end with the caller which started it
+			scanCtx := context.WithoutCancel(ctx)
+			if events.config.ScanTimeout > 0 {
+				var cancel context.CancelFunc
+				scanCtx, cancel = context.WithTimeout(scanCtx, events.config.ScanTimeout)
+				defer cancel()
+			}
+			return events.scanForSatellite(scanCtx, endpoint, scan.satelliteID, start, scan.walletsList)
+		})
+		select {
+		case result := <-results:
+			if result.Err != nil {
+				return chainScan{}, result.Err
+			}
+			return result.Val.(chainScan), nil
+		case <-ctx.Done():
+			return chainScan{}, ctx.Err()
+		}
+	})
+}
+
+// GetRemovedForSatellite returns the transfer events previously reported to the satellite whose blocks were
+// orphaned by a chain reorganization. The removed events are returned whatever block the satellite scans from, until
+// ReorgCheckDepth blocks were mined after their removal, so that satellites which already moved past the orphaned
+// blocks retract them as well.
+func (events *Service) GetRemovedForSatellite(ctx context.Context, endpoints []common.EthEndpoint, satelliteID string) (_ []TransferEvent, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	removed := make([]TransferEvent, 0)
+	for _, endpoint := range endpoints {
+		endpointRemoved, err := events.reportedDB.ListRemoved(ctx, satelliteID, endpoint.ChainID)
+		if err != nil {
+			return nil, err
+		}
+		removed = append(removed, endpointRemoved...)
 	}
-	updatedScannedBlocks, newEvents, err := events.getEvents(ctx, endpoints, walletsList, from)
+	return removed, nil
+}
+
+// chainScan is the result of scanning a single chain for transfer events.
+// Partial is set when the scan stopped before the latest block of the chain. Native is the failure of the native
+// transfers scan, whose events are missing.
+type chainScan struct {
+	latest  blockchain.Header
+	events  []TransferEvent
+	partial bool
+	native  error
+}
+
+// scanChains scans the endpoint chains concurrently and returns the latest scanned block headers, the transfer events
+// and the partially scanned chains, in endpoint order. Chains which are not indexed yet are skipped. A failing chain
+// doesn't stop the scans of the others: the failed chains are left out of the results, which are returned along with
+// the errors of these chains. The chains whose native transfers failed to be scanned are kept in the results, their
+// ErrNativeScan errors are returned along with them.
+func (events *Service) scanChains(ctx context.Context, endpoints []common.EthEndpoint, scanChain func(ctx context.Context, endpoint common.EthEndpoint) (chainScan, error)) (map[int64]blockchain.Header, []TransferEvent, map[int64]bool, error) {
+	scans := make([]*chainScan, len(endpoints))
+	failures := make([]error, len(endpoints))
+
+	var wg sync.WaitGroup
+	for i, endpoint := range endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			scan, err := scanChain(ctx, endpoint)
+			if err != nil {
+				if errs.Is(err, ErrNoIndexedBlock) {
+					events.log.Warn("chain is not indexed yet", zap.Int64("Chain ID", endpoint.ChainID))
+					return
+				}
+				failures[i] = err
+				return
+			}
+			scans[i] = &scan
+		}()
+	}
+	wg.Wait()
+
+	var group errs.Group
+	for i, endpoint := range endpoints {
+		if failures[i] != nil {
+			events.log.Warn("failed to scan chain for transfer events", zap.Int64("Chain ID", endpoint.ChainID), zap.Error(failures[i]))
+			group.Add(failures[i])
+		}
+	}
+
+	scannedBlocks := make(map[int64]blockchain.Header)
+	newEvents := make([]TransferEvent, 0)
+	partial := make(map[int64]bool)
+	for i, endpoint := range endpoints {
+		if scans[i] == nil {
+			continue
+		}
+		scannedBlocks[endpoint.ChainID] = scans[i].latest
+		newEvents = append(newEvents, scans[i].events...)
+		if scans[i].partial {
+			partial[endpoint.ChainID] = true
+		}
+		group.Add(scans[i].native)
+	}
+	return scannedBlocks, newEvents, partial, group.Err()
+}
+
+// scanForSatellite returns the transfer events to the satellite wallets on the endpoint chain, either from the ledger
+// or by scanning the chain, in which case the satellite watermark of the chain is moved forward. The returned events
+// are tracked to detect chain reorganizations.
+func (events *Service) scanForSatellite(ctx context.Context, endpoint common.EthEndpoint, satelliteID string, from int64, walletsList []common.Address) (_ chainScan, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	var latest blockchain.Header
+	var satelliteEvents []TransferEvent
+	var partial bool
+	var nativeErr error
+	if events.config.Indexer.Enabled {
+		latest, err = events.db.GetIndexedBlock(ctx, endpoint.ChainID)
+		if err != nil {
+			return chainScan{}, err
+		}
+		satelliteEvents, err = events.listIndexed(ctx, func(ctx context.Context, outgoing bool) ([]TransferEvent, error) {
+			return events.db.ListBySatellite(ctx, satelliteID, endpoint.ChainID, from, latest.Number, outgoing)
+		})
+		if err != nil {
+			events.log.Error("failed to list indexed events", zap.Int64("Chain ID", endpoint.ChainID))
+			return chainScan{}, err
+		}
+	} else {
+		latest, satelliteEvents, partial, err = events.getEventsForChain(ctx, endpoint, from, walletsList)
+		if err != nil && !ErrNativeScan.Has(err) {
+			return chainScan{}, err
+		}
+		nativeErr = err
+		err = events.watermarksDB.Set(ctx, satelliteID, map[int64]int64{
+			endpoint.ChainID: max(latest.Number-int64(events.config.ChainReorgBuffer), 0),
+		})
+		if err != nil {
+			return chainScan{}, err
+		}
+	}
+
+	removedBlocks, err := events.detectReorgs(ctx, endpoint, satelliteID, latest, satelliteEvents)
 	if err != nil {
-		return nil, nil, err
+		return chainScan{}, err
+	}
+	if len(removedBlocks) > 0 {
+		canonicalEvents := make([]TransferEvent, 0, len(satelliteEvents))
+		for _, event := range satelliteEvents {
+			if _, ok := removedBlocks[event.BlockHash]; !ok {
+				canonicalEvents = append(canonicalEvents, event)
+			}
+		}
+		satelliteEvents = canonicalEvents
 	}
+	return chainScan{latest: latest, events: satelliteEvents, partial: partial, native: nativeErr}, nil
+}
+
+// detectReorgs records the transfer events reported to the satellite and verifies that the blocks of the events
+// reported within the last ReorgCheckDepth blocks are still part of the chain. The events of orphaned blocks are
+// marked as removed and the hashes of these blocks are returned.
+func (events *Service) detectReorgs(ctx context.Context, endpoint common.EthEndpoint, satelliteID string, latest blockchain.Header, reported []TransferEvent) (_ map[common.Hash]struct{}, err error) {
+	defer mon.Task()(&ctx)(&err)
 
-	for chain, block := range updatedScannedBlocks {
-		if events.lastScannedBlock[satelliteID] == nil {
-			events.lastScannedBlock[satelliteID] = make(map[int64]int64)
+	// only incoming transfers are credited by the satellites and need to be retracted
+	incoming := make([]TransferEvent, 0, len(reported))
+	for _, event := range reported {
+		if !event.Outgoing {
+			incoming = append(incoming, event)
 		}
-		if block.Number > int64(events.config.ChainReorgBuffer) {
-			events.lastScannedBlock[satelliteID][chain] = block.Number - int64(events.config.ChainReorgBuffer)
-		} else {
-			events.lastScannedBlock[satelliteID][chain] = 0
+	}
+	if err = events.reportedDB.Insert(ctx, satelliteID, incoming); err != nil {
+		return nil, err
+	}
+
+	checkFrom := max(latest.Number-int64(events.config.ReorgCheckDepth), 0)
+	if err = events.reportedDB.DeleteBefore(ctx, endpoint.ChainID, checkFrom); err != nil {
+		return nil, err
+	}
+	tracked, err := events.reportedDB.List(ctx, satelliteID, endpoint.ChainID, checkFrom)
+	if err != nil {
+		return nil, err
+	}
+	if len(tracked) == 0 {
+		return nil, nil
+	}
+
+	canonical := make(map[int64]common.Hash)
+	removed := make(map[common.Hash]struct{})
+	for _, event := range tracked {
+		if _, ok := removed[event.BlockHash]; ok {
+			continue
+		}
+		hash, ok := canonical[event.BlockNumber]
+		if !ok {
+			err = events.clients.Do(ctx, endpoint, func(client blockchain.ChainClient) error {
+				header, err := client.HeaderByNumber(ctx, big.NewInt(event.BlockNumber))
+				if err != nil {
+					return err
+				}
+				hash = header.Hash()
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			canonical[event.BlockNumber] = hash
+		}
+		if hash == event.BlockHash {
+			continue
+		}
+
+		events.log.Info("chain reorganization detected, retracting reported transfer events",
+			zap.Int64("Chain ID", endpoint.ChainID),
+			zap.Int64("Block Number", event.BlockNumber),
+			zap.String("Block Hash", event.BlockHash.String()),
+			zap.String("Satellite", satelliteID),
+		)
+		mon.Counter("reorg_retracted_blocks").Inc(1)
+		if err = events.reportedDB.Remove(ctx, satelliteID, endpoint.ChainID, event.BlockHash, latest.Number); err != nil {
+			return nil, err
 		}
+		removed[event.BlockHash] = struct{}{}
 	}
-	return updatedScannedBlocks, newEvents, nil
+	return removed, nil
 }
 
 // GetForAddress returns with the latest transfer events from the blockchain for a given address.
-func (events *Service) GetForAddress(ctx context.Context, endpoints []common.EthEndpoint, address []common.Address, from map[int64]int64) (map[int64]blockchain.Header, []TransferEvent, error) {
+// Chains are scanned partially the same way as for GetForSatellite.
+func (events *Service) GetForAddress(ctx context.Context, endpoints []common.EthEndpoint, address []common.Address, from map[int64]int64) (map[int64]blockchain.Header, []TransferEvent, map[int64]bool, error) {
+	if events.config.Indexer.Enabled {
+		return events.getIndexedEvents(ctx, endpoints, from, func(ctx context.Context, chainID, from, to int64, outgoing bool) ([]TransferEvent, error) {
+			return events.db.ListByAddress(ctx, address, chainID, from, to, outgoing)
+		})
+	}
 	return events.getEvents(ctx, endpoints, address, from)
 }
 
-func (events *Service) getEvents(ctx context.Context, endpoints []common.EthEndpoint, address []common.Address, from map[int64]int64) (map[int64]blockchain.Header, []TransferEvent, error) {
-	scannedBlocks := make(map[int64]blockchain.Header)
-	newEvents := make([]TransferEvent, 0)
+// Index scans the endpoints for new transfer events to claimed wallets and stores them in the ledger.
+func (events *Service) Index(ctx context.Context, endpoints []common.EthEndpoint) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	wallets, err := events.walletsDB.ListAll(ctx)
+	if err != nil {
+		return err
+	}
+	walletsList := make([]common.Address, 0, len(wallets))
+	for wallet := range wallets {
+		walletsList = append(walletsList, wallet)
+	}
+
+	var group errs.Group
 	for _, endpoint := range endpoints {
-		latestChainBlockHeader, err := getChainLatestBlockHeader(ctx, endpoint.URL, endpoint.ChainID)
+		err := events.indexEndpoint(ctx, endpoint, walletsList)
 		if err != nil {
-			events.log.Error("failed to get latest block number", zap.String("URL", endpoint.URL))
-			return nil, nil, err
+			events.log.Error("failed to index transfer events", zap.String("URL", endpoint.URL), zap.Error(err))
+			group.Add(err)
 		}
+	}
+	return group.Err()
+}
+
+// indexEndpoint indexes the blocks following the last indexed block of the endpoint chain, re-scanning the last
+// ChainReorgBuffer blocks. The range is capped at MaximumQuerySize so that no blocks are skipped after a long pause,
+// the remaining blocks are picked up by the next run.
+func (events *Service) indexEndpoint(ctx context.Context, endpoint common.EthEndpoint, walletsList []common.Address) error {
+	latestChainBlockHeader, err := events.getChainLatestBlockHeader(ctx, endpoint)
+	if err != nil {
+		return err
+	}
 
-		endpointEvents, err := events.getEventsForEndpoint(ctx, endpoint, uint64(from[endpoint.ChainID]), uint64(latestChainBlockHeader.Number), address)
+	var start int64
+	indexedBlock, err := events.db.GetIndexedBlock(ctx, endpoint.ChainID)
+	switch {
+	case err == nil:
+		start = max(indexedBlock.Number-int64(events.config.ChainReorgBuffer), 0)
+	case errs.Is(err, ErrNoIndexedBlock):
+		start = max(latestChainBlockHeader.Number-int64(events.config.MaximumQuerySize), 0)
+	default:
+		return err
+	}
+
+	end := latestChainBlockHeader
+	if end.Number-start > int64(events.config.MaximumQuerySize) {
+		end, err = events.getChainBlockHeader(ctx, endpoint, big.NewInt(start+int64(events.config.MaximumQuerySize)))
 		if err != nil {
-			events.log.Error("failed to refresh events", zap.String("URL", endpoint.URL))
-			return nil, nil, err
+			return err
 		}
-		scannedBlocks[endpoint.ChainID] = latestChainBlockHeader
-		newEvents = append(newEvents, endpointEvents...)
 	}
-	return scannedBlocks, newEvents, nil
+
+	// the token transfers are indexed even if the native transfers failed to be scanned
+	newEvents, nativeErr := events.getEventsForEndpoint(ctx, endpoint, uint64(start), uint64(end.Number), walletsList)
+	if nativeErr != nil && !ErrNativeScan.Has(nativeErr) {
+		return nativeErr
+	}
+	if err = events.db.Replace(ctx, endpoint.ChainID, start, end.Number, newEvents); err != nil {
+		return err
+	}
+	events.log.Debug("indexed transfer events",
+		zap.Int64("Chain ID", endpoint.ChainID),
+		zap.Int64("From", start),
+		zap.Int64("To", end.Number),
+		zap.Int("Events", len(newEvents)),
+	)
+	return errs.Combine(events.db.SetIndexedBlock(ctx, end), nativeErr)
 }
 
-func (events *Service) getEventsForEndpoint(ctx context.Context, endpoint common.EthEndpoint, start, latestChainBlockNumber uint64, walletsList []common.Address) ([]TransferEvent, error) {
-	client, err := ethclient.DialContext(ctx, endpoint.URL)
+// getIndexedEvents reads transfer events from the ledger, up to the last indexed block of each chain.
+func (events *Service) getIndexedEvents(ctx context.Context, endpoints []common.EthEndpoint, from map[int64]int64, list func(ctx context.Context, chainID, from, to int64, outgoing bool) ([]TransferEvent, error)) (map[int64]blockchain.Header, []TransferEvent, map[int64]bool, error) {
+	return events.scanChains(ctx, endpoints, func(ctx context.Context, endpoint common.EthEndpoint) (chainScan, error) {
+		indexedBlock, err := events.db.GetIndexedBlock(ctx, endpoint.ChainID)
+		if err != nil {
+			return chainScan{}, err
+		}
+
+		endpointEvents, err := events.listIndexed(ctx, func(ctx context.Context, outgoing bool) ([]TransferEvent, error) {
+			return list(ctx, endpoint.ChainID, from[endpoint.ChainID], indexedBlock.Number, outgoing)
+		})
+		if err != nil {
+			events.log.Error("failed to list indexed events", zap.Int64("Chain ID", endpoint.ChainID))
+			return chainScan{}, err
+		}
+		return chainScan{latest: indexedBlock, events: endpointEvents}, nil
+	})
+}
+
+// listIndexed lists the incoming transfer events from the ledger, followed by the outgoing ones when enabled.
+func (events *Service) listIndexed(ctx context.Context, list func(ctx context.Context, outgoing bool) ([]TransferEvent, error)) ([]TransferEvent, error) {
+	listed, err := list(ctx, false)
+	if err != nil || !events.config.Outgoing {
+		return listed, err
+	}
+	outgoing, err := list(ctx, true)
 	if err != nil {
 		return nil, err
 	}
-	defer client.Close()
+	return append(listed, outgoing...), nil
+}
 
-	contractAdress, err := common.AddressFromHex(endpoint.Contract)
+// getEvents scans the endpoint chains concurrently for transfer events to the given addresses.
+func (events *Service) getEvents(ctx context.Context, endpoints []common.EthEndpoint, address []common.Address, from map[int64]int64) (map[int64]blockchain.Header, []TransferEvent, map[int64]bool, error) {
+	return events.scanChains(ctx, endpoints, func(ctx context.Context, endpoint common.EthEndpoint) (chainScan, error) {
+		scannedBlockHeader, endpointEvents, partial, err := events.getEventsForChain(ctx, endpoint, from[endpoint.ChainID], address)
+		if err != nil && !ErrNativeScan.Has(err) {
+			return chainScan{}, err
+		}
+		return chainScan{latest: scannedBlockHeader, events: endpointEvents, partial: partial, native: err}, nil
+	})
+}
+
+// getEventsForChain returns the transfer events to the given addresses from the given block and the header of the
+// last scanned block. At most MaximumQuerySize blocks following the given block are scanned, the chain is reported
+// as partial when its latest block is further, so that the next scan continues after the returned header instead of
+// skipping blocks. Without a given block, the scan starts MaximumQuerySize blocks before the latest block.
+func (events *Service) getEventsForChain(ctx context.Context, endpoint common.EthEndpoint, from int64, address []common.Address) (_ blockchain.Header, _ []TransferEvent, partial bool, err error) {
+	latestChainBlockHeader, err := events.getChainLatestBlockHeader(ctx, endpoint)
 	if err != nil {
-		return nil, err
+		events.log.Error("failed to get latest block number", zap.String("URL", endpoint.URL))
+		return blockchain.Header{}, nil, false, err
+	}
+
+	end := latestChainBlockHeader
+	if from <= 0 {
+		from = max(latestChainBlockHeader.Number-int64(events.config.MaximumQuerySize), 0)
+	} else if latestChainBlockHeader.Number-from > int64(events.config.MaximumQuerySize) {
+		end, err = events.getChainBlockHeader(ctx, endpoint, big.NewInt(from+int64(events.config.MaximumQuerySize)))
+		if err != nil {
+			return blockchain.Header{}, nil, false, err
+		}
+		partial = true
+		mon.Counter("partial_scans").Inc(1)
+		events.log.Debug("too many blocks to scan, scanning partially",
+			zap.Int64("Chain ID", endpoint.ChainID),
+			zap.Int64("From", from),
+			zap.Int64("To", end.Number),
+			zap.Int64("Latest", latestChainBlockHeader.Number),
+		)
 	}
-	token, err := erc20.NewERC20(contractAdress, client)
+
+	endpointEvents, err := events.getEventsForEndpoint(ctx, endpoint, uint64(from), uint64(end.Number), address)
+	if err != nil && !ErrNativeScan.Has(err) {
+		events.log.Error("failed to refresh events", zap.String("URL", endpoint.URL))
+		return blockchain.Header{}, nil, false, err
+	}
+	return end, endpointEvents, partial, err
+}
+
+// getEventsForEndpoint returns the transfer events of the endpoint chain within the given block range (inclusive),
+// scanning the range again on the next provider of the chain if the current one becomes unavailable. The native
+// transfers, if configured, are scanned separately: when their scan fails, the token transfer events are returned
+// along with an ErrNativeScan error.
+func (events *Service) getEventsForEndpoint(ctx context.Context, endpoint common.EthEndpoint, start, latestChainBlockNumber uint64, walletsList []common.Address) (newEvents []TransferEvent, err error) {
+	err = events.clients.Do(ctx, endpoint, func(client blockchain.ChainClient) (err error) {
+		newEvents, err = events.getEventsForClient(ctx, client, endpoint, start, latestChainBlockNumber, walletsList)
+		return err
+	})
+	if err != nil || !events.config.Native || len(walletsList) == 0 {
+		return newEvents, err
+	}
+
+	var nativeEvents []TransferEvent
+	err = events.clients.Do(ctx, endpoint, func(client blockchain.ChainClient) (err error) {
+		nativeClient, ok := client.(nativeClient)
+		if !ok {
+			return errs.New("chain client of chain %d doesn't support native transfers", endpoint.ChainID)
+		}
+		nativeEvents, err = events.getNativeEvents(ctx, nativeClient, endpoint, start, latestChainBlockNumber, walletsList)
+		return err
+	})
 	if err != nil {
-		events.log.Error("failed to bind to ERC20 contract", zap.String("Contract", contractAdress.Hex()), zap.String("URL", endpoint.URL))
-		return nil, err
+		mon.Counter("native_scan_failures").Inc(1)
+		events.log.Error("failed to search for native transfers", zap.Int64("Chain ID", endpoint.ChainID),
+			zap.Uint64("From", start), zap.Uint64("To", latestChainBlockNumber), zap.Error(err))
+		return newEvents, ErrNativeScan.Wrap(err)
+	}
+	return append(newEvents, nativeEvents...), nil
+}
+
+// getEventsForClient returns the transfer events of the endpoint tokens to or from the wallets within the given block
+// range (inclusive), queried from the given client.
+func (events *Service) getEventsForClient(ctx context.Context, client blockchain.ChainClient, endpoint common.EthEndpoint, start, latestChainBlockNumber uint64, walletsList []common.Address) (_ []TransferEvent, err error) {
+	var tokens []boundToken
+	for _, endpointToken := range endpoint.GetTokens() {
+		contractAdress, err := endpointToken.Address()
+		if err != nil {
+			return nil, err
+		}
+		token, err := erc20.NewERC20Filterer(contractAdress, logFilterer{client})
+		if err != nil {
+			events.log.Error("failed to bind to ERC20 contract", zap.String("Contract", contractAdress.Hex()), zap.String("URL", endpoint.URL))
+			return nil, err
+		}
+		tokens = append(tokens, boundToken{
+			contract: contractAdress,
+			currency: endpointToken.Currency(),
+			erc20:    token,
+		})
 	}
 
 	// shouldn't happen, but just in case
 	if start > latestChainBlockNumber {
 		return nil, nil
 	}
-	if (latestChainBlockNumber - start) > uint64(events.config.MaximumQuerySize) {
-		start = latestChainBlockNumber - uint64(events.config.MaximumQuerySize)
+	// with many wallets, fetching all the transfer logs of the contracts takes fewer queries than the address batches
+	var walletSet map[common.Address]struct{}
+	if events.matchLocally(len(walletsList)) {
+		walletSet = make(map[common.Address]struct{}, len(walletsList))
+		for _, wallet := range walletsList {
+			walletSet[wallet] = struct{}{}
+		}
 	}
+
 	newEvents := make([]TransferEvent, 0)
-	for j := int(start); j < int(latestChainBlockNumber); j += events.config.BlockBatchSize {
-		end := uint64(j + events.config.BlockBatchSize)
-		opts := &bind.FilterOpts{
-			Start:   uint64(j),
-			End:     &end,
-			Context: ctx,
+	maxBatchSize := uint64(max(events.config.BlockBatchSize, 1))
+	batchSize := maxBatchSize
+	splits, successes := 0, 0
+	for from := start; from <= latestChainBlockNumber; {
+		to := min(from+batchSize-1, latestChainBlockNumber)
+		var rangeEvents []TransferEvent
+		if walletSet != nil {
+			rangeEvents, err = events.getContractEventsForRange(ctx, endpoint.ChainID, tokens, from, to, walletSet)
+		} else {
+			rangeEvents, err = events.getEventsForRange(ctx, endpoint.ChainID, tokens, from, to, walletsList)
 		}
-		if end > latestChainBlockNumber {
-			opts.End = nil
+		if err != nil {
+			if !isRangeLimitError(err) || from == to || splits >= maxRangeSplits {
+				events.log.Error("failed to search for transfer events", zap.Int64("Chain ID", endpoint.ChainID),
+					zap.Uint64("From", from), zap.Uint64("To", to))
+				return nil, err
+			}
+			// the provider rejected the query, retry with half of the block range
+			batchSize = max((to-from+1)/2, 1)
+			splits++
+			successes = 0
+			mon.Counter("block_range_splits").Inc(1)
+			events.log.Debug("block range rejected by the provider, splitting it", zap.Int64("Chain ID", endpoint.ChainID),
+				zap.Uint64("From", from), zap.Uint64("To", to), zap.Error(err))
+			continue
 		}
+		newEvents = append(newEvents, rangeEvents...)
+		from = to + 1
+		splits = 0
+		// grow the block range back slowly while queries succeed, so that a provider limit isn't hit every other query
+		successes++
+		if batchSize < maxBatchSize && successes >= rangeGrowthSuccesses {
+			batchSize = min(batchSize*2, maxBatchSize)
+			successes = 0
+		}
+	}
+	return newEvents, nil
+}
 
-		for i := 0; i < len(walletsList); i += events.config.AddressBatchSize {
-			var addresses []common.Address
+// matchLocally reports whether the given number of wallets reaches the ContractScanThreshold, from which all the
+// transfers of the scanned blocks are fetched and matched against the wallets locally instead of being queried per
+// wallet.
+func (events *Service) matchLocally(wallets int) bool {
+	return events.config.ContractScanThreshold > 0 && wallets >= events.config.ContractScanThreshold
+}
 
-			for a := i; a-i < events.config.AddressBatchSize && a < len(walletsList); a++ {
-				addresses = append(addresses, walletsList[a])
-			}
+// getEventsForRange returns the transfer events of the tokens to the addresses, or from them as well if outgoing
+// transfers are tracked, within the given block range (inclusive).
+func (events *Service) getEventsForRange(ctx context.Context, chainID int64, tokens []boundToken, from, to uint64, walletsList []common.Address) ([]TransferEvent, error) {
+	opts := &bind.FilterOpts{
+		Start:   from,
+		End:     &to,
+		Context: ctx,
+	}
 
-			batchEvents, err := events.processBatch(token, opts, addresses, endpoint.ChainID)
+	var rangeEvents []TransferEvent
+	for i := 0; i < len(walletsList); i += events.config.AddressBatchSize {
+		var addresses []common.Address
+
+		for a := i; a-i < events.config.AddressBatchSize && a < len(walletsList); a++ {
+			addresses = append(addresses, walletsList[a])
+		}
+
+		for _, token := range tokens {
+			batchEvents, err := events.processBatch(token, opts, addresses, chainID, false)
 			if err != nil {
 				return nil, err
 			}
-			newEvents = append(newEvents, batchEvents...)
+			rangeEvents = append(rangeEvents, batchEvents...)
+
+			if events.config.Outgoing {
+				batchEvents, err = events.processBatch(token, opts, addresses, chainID, true)
+				if err != nil {
+					return nil, err
+				}
+				rangeEvents = append(rangeEvents, batchEvents...)
+			}
 		}
 	}
-	return newEvents, nil
+	return rangeEvents, nil
 }
 
-func (events *Service) processBatch(token *erc20.ERC20, opts *bind.FilterOpts, addresses []common.Address, chainID int64) ([]TransferEvent, error) {
-	iter, err := token.FilterTransfer(opts, nil, addresses)
+// getContractEventsForRange returns the transfer events of the tokens to the wallets of the set, or from them as well
+// if outgoing transfers are tracked, within the given block range (inclusive). All the transfer logs of the token
+// contracts are fetched and matched against the set locally.
+func (events *Service) getContractEventsForRange(ctx context.Context, chainID int64, tokens []boundToken, from, to uint64, walletSet map[common.Address]struct{}) ([]TransferEvent, error) {
+	opts := &bind.FilterOpts{
+		Start:   from,
+		End:     &to,
+		Context: ctx,
+	}
+
+	var rangeEvents []TransferEvent
+	for _, token := range tokens {
+		iter, err := token.erc20.FilterTransfer(opts, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		for iter.Next() {
+			if _, ok := walletSet[iter.Event.To]; ok {
+				rangeEvents = append(rangeEvents, events.transferEvent(chainID, token, iter.Event, false))
+			}
+			if _, ok := walletSet[iter.Event.From]; ok && events.config.Outgoing {
+				rangeEvents = append(rangeEvents, events.transferEvent(chainID, token, iter.Event, true))
+			}
+		}
+		if err = errs.Combine(iter.Error(), iter.Close()); err != nil {
+			return nil, err
+		}
+	}
+	return rangeEvents, nil
+}
+
+// boundToken is an ERC20 token contract of an endpoint bound to the chain client.
+type boundToken struct {
+	contract common.Address
+	currency *currency.Currency
+	erc20    *erc20.ERC20Filterer
+}
+
+// logFilterer adapts a chain client to the log filtering of the contract bindings, which don't subscribe to logs.
+type logFilterer struct {
+	blockchain.ChainClient
+}
+
+// SubscribeFilterLogs is not supported, logs are only filtered.
+func (logFilterer) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return nil, errs.New("log subscriptions are not supported")
+}
+
+// processBatch returns the transfer events of the token to the addresses, or from the addresses if outgoing is set.
+func (events *Service) processBatch(token boundToken, opts *bind.FilterOpts, addresses []common.Address, chainID int64, outgoing bool) ([]TransferEvent, error) {
+	var iter *erc20.ERC20TransferIterator
+	var err error
+	if outgoing {
+		iter, err = token.erc20.FilterTransfer(opts, addresses, nil)
+	} else {
+		iter, err = token.erc20.FilterTransfer(opts, nil, addresses)
+	}
 	if err != nil {
-		events.log.Error("failed to search for transfer events", zap.Int64("Chain ID", chainID))
 		return nil, err
 	}
 	defer func() { err = errs.Combine(err, errs.Wrap(iter.Close())) }()
 
 	newEvents := make([]TransferEvent, 0)
 	for iter.Next() {
-		events.log.Debug("found transfer event",
-			zap.Int64("Chain ID", chainID),
-			zap.String("From", iter.Event.From.String()),
-			zap.String("To", iter.Event.To.String()),
-			zap.String("Transaction Hash", iter.Event.Raw.TxHash.String()),
-			zap.Uint64("Block Number", iter.Event.Raw.BlockNumber),
-			zap.Int("Log Index", int(iter.Event.Raw.Index)),
-		)
-		tokenValue := currency.AmountFromBaseUnits(iter.Event.Value.Int64(), currency.StorjToken)
-		newEvents = append(newEvents, TransferEvent{
-			ChainID:     chainID,
-			From:        iter.Event.From,
-			To:          iter.Event.To,
-			BlockHash:   iter.Event.Raw.BlockHash,
-			BlockNumber: int64(iter.Event.Raw.BlockNumber),
-			TxHash:      iter.Event.Raw.TxHash,
-			LogIndex:    int(iter.Event.Raw.Index),
-			TokenValue:  tokenValue,
-		})
+		newEvents = append(newEvents, events.transferEvent(chainID, token, iter.Event, outgoing))
 	}
 	return newEvents, nil
 }
 
-func getChainLatestBlockHeader(ctx context.Context, url string, chainID int64) (_ blockchain.Header, err error) {
-	client, err := ethclient.DialContext(ctx, url)
-	if err != nil {
-		return blockchain.Header{}, err
+// transferEvent converts the Transfer log of the token to a transfer event.
+func (events *Service) transferEvent(chainID int64, token boundToken, transfer *erc20.ERC20Transfer, outgoing bool) TransferEvent {
+	events.log.Debug("found transfer event",
+		zap.Int64("Chain ID", chainID),
+		zap.String("Contract", token.contract.String()),
+		zap.String("From", transfer.From.String()),
+		zap.String("To", transfer.To.String()),
+		zap.String("Transaction Hash", transfer.Raw.TxHash.String()),
+		zap.Uint64("Block Number", transfer.Raw.BlockNumber),
+		zap.Int("Log Index", int(transfer.Raw.Index)),
+		zap.Bool("Outgoing", outgoing),
+	)
+	return TransferEvent{
+		ChainID:     chainID,
+		From:        transfer.From,
+		To:          transfer.To,
+		BlockHash:   transfer.Raw.BlockHash,
+		BlockNumber: int64(transfer.Raw.BlockNumber),
+		TxHash:      transfer.Raw.TxHash,
+		LogIndex:    int(transfer.Raw.Index),
+		Contract:    token.contract,
+		TokenValue:  common.TokenAmountFromBig(transfer.Value, token.currency),
+		Outgoing:    outgoing,
+	}
+}
+
+// maxRangeSplits is the maximum number of consecutive splits of a block range, so that a provider rejecting the
+// queries for other reasons than their range doesn't make the scan split the range forever.
+const maxRangeSplits = 32
+
+// rangeGrowthSuccesses is the number of consecutive successful queries after which a split block range is doubled
+// again, up to BlockBatchSize.
+const rangeGrowthSuccesses = 10
+
+// rangeLimitCodes are the JSON-RPC error codes of providers rejecting log queries over too many blocks or with too
+// many results: the block range limit error of QuickNode. The limit exceeded error code -32005 is left out, as
+// Infura uses it for rate limiting too, its range limit errors are recognized by their message instead.
+var rangeLimitCodes = []int{-32614}
+
+// rangeLimitErrors are fragments of the JSON-RPC error messages of providers rejecting log queries over too many
+// blocks or with too many results with a generic error code.
+var rangeLimitErrors = []string{
+	"query returned more than",
+	"exceed maximum block range",
+	"block range is too large",
+	"block range too large",
+	"response size exceeded",
+	"response size should not be greater than",
+}
+
+// isRangeLimitError reports whether a log query was rejected by the provider because of its block range or number of
+// results. Transport errors, such as timeouts, are not.
+func isRangeLimitError(err error) bool {
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		return false
 	}
-	defer client.Close()
+	if slices.Contains(rangeLimitCodes, rpcErr.ErrorCode()) {
+		return true
+	}
+	message := strings.ToLower(rpcErr.Error())
+	for _, fragment := range rangeLimitErrors {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+func (events *Service) getChainLatestBlockHeader(ctx context.Context, endpoint common.EthEndpoint) (_ blockchain.Header, err error) {
+	return events.getChainBlockHeader(ctx, endpoint, nil)
+}
 
-	latestBlock, err := client.HeaderByNumber(ctx, nil)
+// getChainBlockHeader returns the header of the block with the given number, or the latest block if number is nil.
+func (events *Service) getChainBlockHeader(ctx context.Context, endpoint common.EthEndpoint, number *big.Int) (_ blockchain.Header, err error) {
+	var block *types.Header
+	err = events.clients.Do(ctx, endpoint, func(client blockchain.ChainClient) (err error) {
+		block, err = client.HeaderByNumber(ctx, number)
+		return err
+	})
 	if err != nil {
 		return blockchain.Header{}, err
 	}
 	return blockchain.Header{
-		Hash:      latestBlock.Hash(),
-		Number:    latestBlock.Number.Int64(),
-		ChainID:   chainID,
-		Timestamp: time.Unix(int64(latestBlock.Time), 0).UTC(),
+		Hash:      block.Hash(),
+		Number:    block.Number.Int64(),
+		ChainID:   endpoint.ChainID,
+		Timestamp: time.Unix(int64(block.Time), 0).UTC(),
 	}, nil
 }
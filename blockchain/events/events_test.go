@@ -4,19 +4,34 @@
 package events_test
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
 
 	"storj.io/common/testcontext"
 	"storj.io/storj/shared/dbutil/dbtest"
+	"storj.io/storjscan/blockchain"
 	"storj.io/storjscan/blockchain/events"
 	"storj.io/storjscan/common"
+	"storj.io/storjscan/private/testchain"
 	"storj.io/storjscan/private/testeth"
 	"storj.io/storjscan/private/testeth/testtoken"
 	"storj.io/storjscan/storjscandb/storjscandbtest"
@@ -122,21 +137,838 @@ func testEventsService(t *testing.T, connStr string) {
 		require.Equal(t, insertedWallet.Address, claimedWallet.Address)
 		require.Equal(t, claimedWallet.Address, accs[4].Address)
 
-		eventsService := events.NewEventsService(logger, db.Wallets(), events.Config{
+		clients := blockchain.NewClients(logger, blockchain.ClientsConfig{})
+		defer ctx.Check(clients.Close)
+		eventsService := events.NewEventsService(logger, clients, db.Wallets(), db.TransferEvents(), db.ScanWatermarks(), db.ReportedEvents(), events.Config{
 			AddressBatchSize: 100,
 			BlockBatchSize:   100,
 			ChainReorgBuffer: 15,
 			MaximumQuerySize: 10000,
 		})
 
-		_, eventsList, err := eventsService.GetForSatellite(ctx, ethEndpoints, satelliteName, map[int64]int64{network.ChainID().Int64(): 0})
+		_, eventsList, _, err := eventsService.GetForSatellite(ctx, ethEndpoints, satelliteName, map[int64]int64{network.ChainID().Int64(): 0})
 		require.NoError(t, err)
 		require.Equal(t, 9, len(eventsList))
-		_, eventsList, err = eventsService.GetForAddress(ctx, ethEndpoints, []common.Address{accs[3].Address}, map[int64]int64{network.ChainID().Int64(): 0})
+		_, eventsList, _, err = eventsService.GetForAddress(ctx, ethEndpoints, []common.Address{accs[3].Address}, map[int64]int64{network.ChainID().Int64(): 0})
 		require.NoError(t, err)
 		require.Equal(t, 6, len(eventsList))
-		_, eventsList, err = eventsService.GetForAddress(ctx, ethEndpoints, []common.Address{accs[4].Address}, map[int64]int64{network.ChainID().Int64(): 0})
+		_, eventsList, _, err = eventsService.GetForAddress(ctx, ethEndpoints, []common.Address{accs[4].Address}, map[int64]int64{network.ChainID().Int64(): 0})
 		require.NoError(t, err)
 		require.Equal(t, 3, len(eventsList))
 	})
 }
+
+func TestEventsIndexer(t *testing.T) {
+	t.Run("Postgres", func(t *testing.T) {
+		testEventsIndexer(t, dbtest.PickPostgres(t))
+	})
+	t.Run("Cockroach", func(t *testing.T) {
+		testEventsIndexer(t, dbtest.PickCockroach(t))
+	})
+}
+
+func testEventsIndexer(t *testing.T, connStr string) {
+	testeth.Run(t, 1, 3, func(ctx *testcontext.Context, t *testing.T, networks []*testeth.Network) {
+		logger := zaptest.NewLogger(t)
+		network := networks[0]
+		chainID := network.ChainID().Int64()
+		satelliteName := "test-satellite"
+
+		db, err := storjscandbtest.OpenDB(ctx, zaptest.NewLogger(t), connStr, t.Name(), "T")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ctx.Check(db.Close)
+
+		err = db.MigrateToLatest(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		jsonEndpoint := `[{"Name":"Geth", "URL": "` + network.HTTPEndpoint() + `", "Contract": "` + network.TokenAddress().Hex() + `", "ChainID": "` + fmt.Sprint(network.ChainID()) + `"}]`
+		var ethEndpoints []common.EthEndpoint
+		err = json.Unmarshal([]byte(jsonEndpoint), &ethEndpoints)
+		require.NoError(t, err)
+
+		client := network.Dial()
+		defer client.Close()
+
+		tk, err := testtoken.NewTestToken(network.TokenAddress(), client)
+		require.NoError(t, err)
+
+		accs := network.Accounts()
+
+		_, err = db.Wallets().Insert(ctx, satelliteName, accs[1].Address, "")
+		require.NoError(t, err)
+		_, err = db.Wallets().Claim(ctx, satelliteName)
+		require.NoError(t, err)
+
+		// transfer to the claimed wallet and to an unknown address
+		for i, to := range []accounts.Account{accs[1], accs[2]} {
+			opts := network.TransactOptions(ctx, accs[0], int64(i+1))
+			tx, err := tk.Transfer(opts, to.Address, big.NewInt(1000))
+			require.NoError(t, err)
+			_, err = network.WaitForTx(ctx, tx.Hash())
+			require.NoError(t, err)
+		}
+
+		clients := blockchain.NewClients(logger, blockchain.ClientsConfig{})
+		defer ctx.Check(clients.Close)
+		eventsService := events.NewEventsService(logger, clients, db.Wallets(), db.TransferEvents(), db.ScanWatermarks(), db.ReportedEvents(), events.Config{
+			AddressBatchSize: 100,
+			BlockBatchSize:   100,
+			ChainReorgBuffer: 15,
+			MaximumQuerySize: 10000,
+			Indexer: events.IndexerConfig{
+				Enabled: true,
+			},
+		})
+
+		// nothing is served before the chain has been indexed
+		latestBlocks, eventsList, _, err := eventsService.GetForSatellite(ctx, ethEndpoints, satelliteName, nil)
+		require.NoError(t, err)
+		require.Empty(t, latestBlocks)
+		require.Empty(t, eventsList)
+
+		chore := events.NewChore(logger, eventsService, ethEndpoints, time.Minute)
+		defer ctx.Check(chore.Close)
+		require.NoError(t, chore.RunOnce(ctx))
+
+		indexedBlock, err := db.TransferEvents().GetIndexedBlock(ctx, chainID)
+		require.NoError(t, err)
+
+		latestBlocks, eventsList, _, err = eventsService.GetForSatellite(ctx, ethEndpoints, satelliteName, nil)
+		require.NoError(t, err)
+		require.Equal(t, indexedBlock, latestBlocks[chainID])
+		require.Len(t, eventsList, 1)
+		require.Equal(t, accs[0].Address, eventsList[0].From)
+		require.Equal(t, accs[1].Address, eventsList[0].To)
+		require.EqualValues(t, 1000, eventsList[0].TokenValue.BaseUnits())
+
+		_, eventsList, _, err = eventsService.GetForAddress(ctx, ethEndpoints, []common.Address{accs[2].Address}, nil)
+		require.NoError(t, err)
+		require.Empty(t, eventsList)
+
+		// re-indexing must not duplicate already stored events
+		require.NoError(t, chore.RunOnce(ctx))
+		_, eventsList, _, err = eventsService.GetForSatellite(ctx, ethEndpoints, satelliteName, nil)
+		require.NoError(t, err)
+		require.Len(t, eventsList, 1)
+	})
+}
+
+func TestEventsSubscriber(t *testing.T) {
+	t.Run("Postgres", func(t *testing.T) {
+		testEventsSubscriber(t, dbtest.PickPostgres(t))
+	})
+	t.Run("Cockroach", func(t *testing.T) {
+		testEventsSubscriber(t, dbtest.PickCockroach(t))
+	})
+}
+
+func testEventsSubscriber(t *testing.T, connStr string) {
+	testeth.Run(t, 1, 2, func(ctx *testcontext.Context, t *testing.T, networks []*testeth.Network) {
+		logger := zaptest.NewLogger(t)
+		network := networks[0]
+		satelliteName := "test-satellite"
+
+		db, err := storjscandbtest.OpenDB(ctx, zaptest.NewLogger(t), connStr, t.Name(), "T")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ctx.Check(db.Close)
+
+		err = db.MigrateToLatest(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		jsonEndpoint := `[{"Name":"Geth", "URL": "` + network.WSEndpoint() + `", "Contract": "` + network.TokenAddress().Hex() + `", "ChainID": "` + fmt.Sprint(network.ChainID()) + `"}]`
+		var ethEndpoints []common.EthEndpoint
+		err = json.Unmarshal([]byte(jsonEndpoint), &ethEndpoints)
+		require.NoError(t, err)
+
+		client := network.Dial()
+		defer client.Close()
+
+		tk, err := testtoken.NewTestToken(network.TokenAddress(), client)
+		require.NoError(t, err)
+
+		accs := network.Accounts()
+
+		_, err = db.Wallets().Insert(ctx, satelliteName, accs[1].Address, "")
+		require.NoError(t, err)
+		_, err = db.Wallets().Claim(ctx, satelliteName)
+		require.NoError(t, err)
+
+		clients := blockchain.NewClients(logger, blockchain.ClientsConfig{})
+		defer ctx.Check(clients.Close)
+		eventsService := events.NewEventsService(logger, clients, db.Wallets(), db.TransferEvents(), db.ScanWatermarks(), db.ReportedEvents(), events.Config{
+			AddressBatchSize: 100,
+			BlockBatchSize:   100,
+			ChainReorgBuffer: 15,
+			MaximumQuerySize: 10000,
+			Indexer: events.IndexerConfig{
+				Enabled:          true,
+				Interval:         time.Hour,
+				Subscribe:        true,
+				ResubscribeDelay: time.Second,
+			},
+		})
+
+		chore := events.NewChore(logger, eventsService, ethEndpoints, time.Hour)
+		defer ctx.Check(chore.Close)
+		ctx.Go(func() error {
+			return chore.Run(ctx)
+		})
+		// only the subscriber triggers indexing from now on
+		chore.Loop.Pause()
+
+		subscriber := events.NewSubscriber(logger, eventsService, clients, chore, ethEndpoints)
+		defer ctx.Check(subscriber.Close)
+		ctx.Go(func() error {
+			return subscriber.Run(ctx)
+		})
+
+		// the first transfer is indexed when received or by the backfill once subscribed,
+		// the following one is received through the established subscription
+		for i := 0; i < 2; i++ {
+			opts := network.TransactOptions(ctx, accs[0], int64(i+1))
+			tx, err := tk.Transfer(opts, accs[1].Address, big.NewInt(1000))
+			require.NoError(t, err)
+			_, err = network.WaitForTx(ctx, tx.Hash())
+			require.NoError(t, err)
+
+			require.Eventually(t, func() bool {
+				_, eventsList, _, err := eventsService.GetForSatellite(ctx, ethEndpoints, satelliteName, nil)
+				return err == nil && len(eventsList) == i+1 && eventsList[i].TxHash == tx.Hash()
+			}, time.Minute, 100*time.Millisecond)
+		}
+	})
+}
+
+func TestEventsServiceWatermarks(t *testing.T) {
+	t.Run("Postgres", func(t *testing.T) {
+		testEventsServiceWatermarks(t, dbtest.PickPostgres(t))
+	})
+	t.Run("Cockroach", func(t *testing.T) {
+		testEventsServiceWatermarks(t, dbtest.PickCockroach(t))
+	})
+}
+
+func testEventsServiceWatermarks(t *testing.T, connStr string) {
+	testeth.Run(t, 1, 2, func(ctx *testcontext.Context, t *testing.T, networks []*testeth.Network) {
+		logger := zaptest.NewLogger(t)
+		network := networks[0]
+		chainID := network.ChainID().Int64()
+		satelliteName := "test-satellite"
+
+		db, err := storjscandbtest.OpenDB(ctx, zaptest.NewLogger(t), connStr, t.Name(), "T")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ctx.Check(db.Close)
+
+		err = db.MigrateToLatest(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		jsonEndpoint := `[{"Name":"Geth", "URL": "` + network.HTTPEndpoint() + `", "Contract": "` + network.TokenAddress().Hex() + `", "ChainID": "` + fmt.Sprint(network.ChainID()) + `"}]`
+		var ethEndpoints []common.EthEndpoint
+		err = json.Unmarshal([]byte(jsonEndpoint), &ethEndpoints)
+		require.NoError(t, err)
+
+		client := network.Dial()
+		defer client.Close()
+
+		tk, err := testtoken.NewTestToken(network.TokenAddress(), client)
+		require.NoError(t, err)
+
+		accs := network.Accounts()
+
+		_, err = db.Wallets().Insert(ctx, satelliteName, accs[1].Address, "")
+		require.NoError(t, err)
+		_, err = db.Wallets().Claim(ctx, satelliteName)
+		require.NoError(t, err)
+
+		transfer := func(nonce int64) common.Hash {
+			opts := network.TransactOptions(ctx, accs[0], nonce)
+			tx, err := tk.Transfer(opts, accs[1].Address, big.NewInt(1000))
+			require.NoError(t, err)
+			_, err = network.WaitForTx(ctx, tx.Hash())
+			require.NoError(t, err)
+			return tx.Hash()
+		}
+
+		config := events.Config{
+			AddressBatchSize: 100,
+			BlockBatchSize:   100,
+			ChainReorgBuffer: 0,
+			MaximumQuerySize: 10000,
+		}
+
+		for nonce := int64(1); nonce <= 3; nonce++ {
+			transfer(nonce)
+		}
+
+		clients := blockchain.NewClients(logger, blockchain.ClientsConfig{})
+		defer ctx.Check(clients.Close)
+		eventsService := events.NewEventsService(logger, clients, db.Wallets(), db.TransferEvents(), db.ScanWatermarks(), db.ReportedEvents(), config)
+		latestBlocks, eventsList, _, err := eventsService.GetForSatellite(ctx, ethEndpoints, satelliteName, map[int64]int64{chainID: 0})
+		require.NoError(t, err)
+		require.Len(t, eventsList, 3)
+
+		watermarks, err := db.ScanWatermarks().Get(ctx, satelliteName)
+		require.NoError(t, err)
+		require.Equal(t, latestBlocks[chainID].Number, watermarks[chainID])
+
+		newTx := transfer(4)
+
+		// a new service instance, e.g. after a restart, resumes from the stored watermark
+		eventsService = events.NewEventsService(logger, clients, db.Wallets(), db.TransferEvents(), db.ScanWatermarks(), db.ReportedEvents(), config)
+		_, eventsList, _, err = eventsService.GetForSatellite(ctx, ethEndpoints, satelliteName, map[int64]int64{chainID: 0})
+		require.NoError(t, err)
+		require.Less(t, len(eventsList), 4)
+		require.Equal(t, newTx, eventsList[len(eventsList)-1].TxHash)
+		for _, event := range eventsList {
+			require.GreaterOrEqual(t, event.BlockNumber, watermarks[chainID])
+		}
+	})
+}
+
+func TestEventsRangeSplitting(t *testing.T) {
+	testeth.Run(t, 1, 2, func(ctx *testcontext.Context, t *testing.T, networks []*testeth.Network) {
+		network := networks[0]
+
+		client := network.Dial()
+		defer client.Close()
+
+		tk, err := testtoken.NewTestToken(network.TokenAddress(), client)
+		require.NoError(t, err)
+
+		accs := network.Accounts()
+		for i := 0; i < 5; i++ {
+			tx, err := tk.Transfer(network.TransactOptions(ctx, accs[0], int64(i+1)), accs[1].Address, big.NewInt(int64(i+1)))
+			require.NoError(t, err)
+			_, err = network.WaitForTx(ctx, tx.Hash())
+			require.NoError(t, err)
+		}
+
+		var rejected atomic.Int64
+		proxy := httptest.NewServer(limitedRPC(network.HTTPEndpoint(), 2, &rejected))
+		defer proxy.Close()
+
+		endpoints := []common.EthEndpoint{{
+			Name:     "Geth",
+			URL:      proxy.URL,
+			Contract: network.TokenAddress().Hex(),
+			ChainID:  network.ChainID().Int64(),
+		}}
+		clients := blockchain.NewClients(zaptest.NewLogger(t), blockchain.ClientsConfig{})
+		defer ctx.Check(clients.Close)
+		service := events.NewEventsService(zaptest.NewLogger(t), clients, nil, nil, nil, nil, events.Config{
+			AddressBatchSize: 100,
+			BlockBatchSize:   100,
+			ChainReorgBuffer: 15,
+			MaximumQuerySize: 10000,
+		})
+
+		_, transferEvents, _, err := service.GetForAddress(ctx, endpoints, []common.Address{accs[1].Address}, nil)
+		require.NoError(t, err)
+		require.Positive(t, rejected.Load())
+		require.Len(t, transferEvents, 5)
+		for i, event := range transferEvents {
+			require.EqualValues(t, i+1, event.TokenValue.BaseUnits())
+		}
+
+		// a long scan, whose ranges are rejected again after growing back, must not give up
+		for i := 0; i < 150; i++ {
+			network.Commit()
+		}
+		tx, err := tk.Transfer(network.TransactOptions(ctx, accs[0], 6), accs[1].Address, big.NewInt(6))
+		require.NoError(t, err)
+		_, err = network.WaitForTx(ctx, tx.Hash())
+		require.NoError(t, err)
+
+		rejected.Store(0)
+		service = events.NewEventsService(zaptest.NewLogger(t), clients, nil, nil, nil, nil, events.Config{
+			AddressBatchSize: 100,
+			BlockBatchSize:   4,
+			ChainReorgBuffer: 15,
+			MaximumQuerySize: 10000,
+		})
+		_, transferEvents, _, err = service.GetForAddress(ctx, endpoints, []common.Address{accs[1].Address}, nil)
+		require.NoError(t, err)
+		require.Len(t, transferEvents, 6)
+		require.Equal(t, tx.Hash(), transferEvents[5].TxHash)
+		// the block range grows back slowly instead of being rejected every other query
+		require.Positive(t, rejected.Load())
+		require.Less(t, rejected.Load(), int64(20))
+	})
+}
+
+func TestEventsNativeMissingState(t *testing.T) {
+	testeth.Run(t, 1, 3, func(ctx *testcontext.Context, t *testing.T, networks []*testeth.Network) {
+		network := networks[0]
+
+		accs := network.Accounts()
+		oneETH := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+		tx, err := network.TransferETH(ctx, accs[2], 0, accs[1].Address, oneETH)
+		require.NoError(t, err)
+		_, err = network.WaitForTx(ctx, tx.Hash())
+		require.NoError(t, err)
+
+		// the provider pruned the states of the blocks, as the nodes which aren't archive nodes do
+		proxy := httptest.NewServer(rejectingRPC(network.HTTPEndpoint(), []string{"eth_getBalance", "eth_getTransactionCount"},
+			"missing trie node 0000000000000000000000000000000000000000000000000000000000000000 (path )"))
+		defer proxy.Close()
+
+		endpoints := []common.EthEndpoint{{
+			Name:     "Geth",
+			URL:      proxy.URL,
+			Contract: network.TokenAddress().Hex(),
+			ChainID:  network.ChainID().Int64(),
+		}}
+		clients := blockchain.NewClients(zaptest.NewLogger(t), blockchain.ClientsConfig{})
+		defer ctx.Check(clients.Close)
+		service := events.NewEventsService(zaptest.NewLogger(t), clients, nil, nil, nil, nil, events.Config{
+			AddressBatchSize: 100,
+			BlockBatchSize:   100,
+			ChainReorgBuffer: 15,
+			MaximumQuerySize: 10000,
+			Native:           true,
+		})
+
+		// every block is scanned instead of the blocks changing the state of the wallet
+		_, transferEvents, _, err := service.GetForAddress(ctx, endpoints, []common.Address{accs[1].Address}, nil)
+		require.NoError(t, err)
+		require.Len(t, transferEvents, 1)
+		require.Equal(t, tx.Hash(), transferEvents[0].TxHash)
+		require.Equal(t, common.NativeAddress, transferEvents[0].Contract)
+		require.Zero(t, oneETH.Cmp(transferEvents[0].TokenValue.BaseUnitsBig()))
+	})
+}
+
+func TestEventsNativeFailure(t *testing.T) {
+	testeth.Run(t, 1, 3, func(ctx *testcontext.Context, t *testing.T, networks []*testeth.Network) {
+		network := networks[0]
+
+		client := network.Dial()
+		defer client.Close()
+
+		tk, err := testtoken.NewTestToken(network.TokenAddress(), client)
+		require.NoError(t, err)
+
+		accs := network.Accounts()
+		tokenTx, err := tk.Transfer(network.TransactOptions(ctx, accs[0], 1), accs[1].Address, big.NewInt(1))
+		require.NoError(t, err)
+		_, err = network.WaitForTx(ctx, tokenTx.Hash())
+		require.NoError(t, err)
+		nativeTx, err := network.TransferETH(ctx, accs[2], 0, accs[1].Address, big.NewInt(1))
+		require.NoError(t, err)
+		_, err = network.WaitForTx(ctx, nativeTx.Hash())
+		require.NoError(t, err)
+
+		// the provider fails to trace the blocks for another reason than not supporting tracing
+		proxy := httptest.NewServer(rejectingRPC(network.HTTPEndpoint(), []string{"debug_traceBlockByHash"}, "internal error"))
+		defer proxy.Close()
+
+		endpoints := []common.EthEndpoint{{
+			Name:     "Geth",
+			URL:      proxy.URL,
+			Contract: network.TokenAddress().Hex(),
+			ChainID:  network.ChainID().Int64(),
+		}}
+		clients := blockchain.NewClients(zaptest.NewLogger(t), blockchain.ClientsConfig{})
+		defer ctx.Check(clients.Close)
+		service := events.NewEventsService(zaptest.NewLogger(t), clients, nil, nil, nil, nil, events.Config{
+			AddressBatchSize: 100,
+			BlockBatchSize:   100,
+			ChainReorgBuffer: 15,
+			MaximumQuerySize: 10000,
+			Native:           true,
+		})
+
+		// the token transfers are still returned, along with the failure of the native transfers scan
+		latest, transferEvents, _, err := service.GetForAddress(ctx, endpoints, []common.Address{accs[1].Address}, nil)
+		require.Error(t, err)
+		require.True(t, events.ErrNativeScan.Has(err))
+		require.Len(t, latest, 1)
+		require.Len(t, transferEvents, 1)
+		require.Equal(t, tokenTx.Hash(), transferEvents[0].TxHash)
+	})
+}
+
+func TestEventsContractScan(t *testing.T) {
+	testeth.Run(t, 1, 4, func(ctx *testcontext.Context, t *testing.T, networks []*testeth.Network) {
+		network := networks[0]
+
+		client := network.Dial()
+		defer client.Close()
+
+		tk, err := testtoken.NewTestToken(network.TokenAddress(), client)
+		require.NoError(t, err)
+
+		accs := network.Accounts()
+		for i, to := range []accounts.Account{accs[1], accs[2], accs[3]} {
+			tx, err := tk.Transfer(network.TransactOptions(ctx, accs[0], int64(i+1)), to.Address, big.NewInt(int64(i+1)))
+			require.NoError(t, err)
+			_, err = network.WaitForTx(ctx, tx.Hash())
+			require.NoError(t, err)
+		}
+
+		endpoints := []common.EthEndpoint{{
+			Name:     "Geth",
+			URL:      network.HTTPEndpoint(),
+			Contract: network.TokenAddress().Hex(),
+			ChainID:  network.ChainID().Int64(),
+		}}
+		wallets := []common.Address{accs[0].Address, accs[1].Address, accs[2].Address}
+
+		// the transfers found per address batch and by matching all the contract logs must be the same
+		var found [][]events.TransferEvent
+		for _, threshold := range []int{0, len(wallets)} {
+			clients := blockchain.NewClients(zaptest.NewLogger(t), blockchain.ClientsConfig{})
+			defer ctx.Check(clients.Close)
+			service := events.NewEventsService(zaptest.NewLogger(t), clients, nil, nil, nil, nil, events.Config{
+				AddressBatchSize:      1,
+				ContractScanThreshold: threshold,
+				BlockBatchSize:        100,
+				ChainReorgBuffer:      15,
+				MaximumQuerySize:      10000,
+				Outgoing:              true,
+			})
+			_, transferEvents, _, err := service.GetForAddress(ctx, endpoints, wallets, nil)
+			require.NoError(t, err)
+			found = append(found, transferEvents)
+		}
+
+		// the tokens minted to the sender wallet, the transfers to the two other wallets, and the three transfers from
+		// the sender wallet
+		require.Len(t, found[0], 6)
+		require.ElementsMatch(t, found[0], found[1])
+	})
+}
+
+func TestEventsTestChain(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	chain := testchain.New(1337)
+	contract, sender, wallet := common.Address{1}, common.Address{2}, common.Address{3}
+	value := new(big.Int).Exp(big.NewInt(10), big.NewInt(20), nil)
+	tx := chain.Transfer(contract, sender, wallet, value)
+	chain.Transfer(contract, sender, common.Address{4}, big.NewInt(1))
+	chain.Transfer(common.Address{5}, sender, wallet, big.NewInt(1))
+	block := chain.Mine()
+	chain.Mine()
+
+	endpoints := []common.EthEndpoint{{
+		Name:     "Test",
+		Contract: contract.Hex(),
+		ChainID:  1337,
+	}}
+	service := events.NewEventsService(zaptest.NewLogger(t), chain, nil, nil, nil, nil, events.Config{
+		AddressBatchSize: 100,
+		BlockBatchSize:   100,
+		ChainReorgBuffer: 15,
+		MaximumQuerySize: 10000,
+	})
+	latest, transferEvents, _, err := service.GetForAddress(ctx, endpoints, []common.Address{wallet}, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, latest[1337].Number)
+
+	// only the transfer of the token to the wallet is found
+	require.Len(t, transferEvents, 1)
+	event := transferEvents[0]
+	require.Equal(t, sender, event.From)
+	require.Equal(t, wallet, event.To)
+	require.Equal(t, contract, event.Contract)
+	require.Equal(t, tx, event.TxHash)
+	require.Equal(t, block.Hash(), event.BlockHash)
+	require.EqualValues(t, 1, event.BlockNumber)
+	require.Zero(t, value.Cmp(event.TokenValue.BaseUnitsBig()))
+}
+
+func TestEventsChainFailure(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	chain := testchain.New(1337)
+	contract, sender, wallet := common.Address{1}, common.Address{2}, common.Address{3}
+	tx := chain.Transfer(contract, sender, wallet, big.NewInt(1))
+	chain.Mine()
+
+	// the second chain isn't served by the test chain and fails to be scanned
+	endpoints := []common.EthEndpoint{
+		{Name: "Test", Contract: contract.Hex(), ChainID: 1337},
+		{Name: "Failing", Contract: contract.Hex(), ChainID: 1338},
+	}
+	service := events.NewEventsService(zaptest.NewLogger(t), chain, nil, nil, nil, nil, events.Config{
+		AddressBatchSize: 100,
+		BlockBatchSize:   100,
+		ChainReorgBuffer: 15,
+		MaximumQuerySize: 10000,
+	})
+	latest, transferEvents, _, err := service.GetForAddress(ctx, endpoints, []common.Address{wallet}, nil)
+	require.Error(t, err)
+
+	// the other chain is still scanned
+	require.Len(t, latest, 1)
+	require.EqualValues(t, 1, latest[1337].Number)
+	require.Len(t, transferEvents, 1)
+	require.Equal(t, tx, transferEvents[0].TxHash)
+}
+
+// BenchmarkEventsScanModes compares scanning the transfer logs per address batch with scanning all the logs of the
+// token contract, for many wallets receiving a fraction of the transfers of the contract.
+func BenchmarkEventsScanModes(b *testing.B) {
+	const (
+		blocks            = 1000
+		transfersPerBlock = 20
+		wallets           = 20000
+	)
+	ctx := testcontext.New(b)
+	defer ctx.Cleanup()
+
+	contract := ethcommon.HexToAddress("0xb64ef51c888972c908cfacf59b47c1afbc0ab8ac")
+	walletsList := make([]common.Address, wallets)
+	for i := range walletsList {
+		walletsList[i] = ethcommon.BigToAddress(big.NewInt(int64(i + 1)))
+	}
+
+	chain := &fakeLogsRPC{latest: blocks}
+	for block := uint64(1); block <= blocks; block++ {
+		for i := uint64(0); i < transfersPerBlock; i++ {
+			// every other transfer goes to an address which is not a wallet
+			to := walletsList[(block*transfersPerBlock+i)%wallets]
+			if i%2 == 1 {
+				to = ethcommon.BigToAddress(new(big.Int).SetUint64(1<<32 + block*transfersPerBlock + i))
+			}
+			chain.logs = append(chain.logs, types.Log{
+				Address:     contract,
+				Topics:      []common.Hash{transferTopic, ethcommon.BigToHash(big.NewInt(1 << 40)), ethcommon.BytesToHash(to.Bytes())},
+				Data:        ethcommon.LeftPadBytes(big.NewInt(1000).Bytes(), 32),
+				BlockNumber: block,
+				TxHash:      ethcommon.BigToHash(new(big.Int).SetUint64(block*transfersPerBlock + i)),
+				BlockHash:   ethcommon.BigToHash(new(big.Int).SetUint64(block)),
+				Index:       uint(i),
+			})
+		}
+	}
+	server := httptest.NewServer(chain)
+	defer server.Close()
+
+	endpoints := []common.EthEndpoint{{
+		Name:     "Fake",
+		URL:      server.URL,
+		Contract: contract.Hex(),
+		ChainID:  1,
+	}}
+
+	for _, mode := range []struct {
+		name      string
+		threshold int
+	}{
+		{name: "AddressBatches", threshold: 0},
+		{name: "Contract", threshold: 1},
+	} {
+		b.Run(mode.name, func(b *testing.B) {
+			clients := blockchain.NewClients(zap.NewNop(), blockchain.ClientsConfig{})
+			defer ctx.Check(clients.Close)
+			service := events.NewEventsService(zap.NewNop(), clients, nil, nil, nil, nil, events.Config{
+				AddressBatchSize:      100,
+				ContractScanThreshold: mode.threshold,
+				BlockBatchSize:        100,
+				ChainReorgBuffer:      15,
+				MaximumQuerySize:      blocks,
+			})
+
+			chain.calls.Store(0)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, transferEvents, _, err := service.GetForAddress(ctx, endpoints, walletsList, nil)
+				require.NoError(b, err)
+				require.Len(b, transferEvents, blocks*transfersPerBlock/2)
+			}
+			b.ReportMetric(float64(chain.calls.Load())/float64(b.N), "calls/op")
+		})
+	}
+}
+
+// transferTopic is the topic of the ERC20 Transfer event logs.
+var transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// fakeLogsRPC is a stand-in JSON-RPC server of a chain with the given logs, serving the latest block header and
+// log queries only.
+type fakeLogsRPC struct {
+	latest uint64
+	logs   []types.Log
+	calls  atomic.Int64
+}
+
+// ServeHTTP serves a JSON-RPC request.
+func (chain *fakeLogsRPC) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	chain.calls.Add(1)
+
+	var result any
+	switch request.Method {
+	case "eth_chainId":
+		result = hexutil.Uint64(1)
+	case "eth_getBlockByNumber":
+		result = &types.Header{
+			Number:     new(big.Int).SetUint64(chain.latest),
+			Difficulty: big.NewInt(0),
+			Time:       uint64(time.Now().Unix()),
+		}
+	case "eth_getLogs":
+		var filter struct {
+			FromBlock hexutil.Uint64  `json:"fromBlock"`
+			ToBlock   hexutil.Uint64  `json:"toBlock"`
+			Topics    [][]common.Hash `json:"topics"`
+		}
+		if len(request.Params) != 1 || json.Unmarshal(request.Params[0], &filter) != nil {
+			http.Error(w, "invalid log filter", http.StatusBadRequest)
+			return
+		}
+		logs := []types.Log{}
+		for _, log := range chain.logs {
+			if log.BlockNumber >= uint64(filter.FromBlock) && log.BlockNumber <= uint64(filter.ToBlock) && matchTopics(log.Topics, filter.Topics) {
+				logs = append(logs, log)
+			}
+		}
+		result = logs
+	default:
+		http.Error(w, "unsupported method "+request.Method, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Result  any             `json:"result"`
+	}{JSONRPC: "2.0", ID: request.ID, Result: result})
+}
+
+// matchTopics reports whether the log topics match the filter topics, an empty filter position matching any topic.
+func matchTopics(topics []common.Hash, filter [][]common.Hash) bool {
+	for i, accepted := range filter {
+		if len(accepted) == 0 {
+			continue
+		}
+		if i >= len(topics) || !slices.Contains(accepted, topics[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// limitedRPC returns a stand-in JSON-RPC server forwarding requests to the given URL, which rejects log queries
+// spanning more than limit blocks like public providers do.
+// rejectingRPC forwards the JSON-RPC requests to the given URL, except the requests and batches calling one of the
+// given methods, which are rejected with the given error message.
+func rejectingRPC(url string, methods []string, message string) http.HandlerFunc {
+	type request struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	rejectedError := func(id json.RawMessage) string {
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"error":{"code":-32000,"message":%q}}`, id, message)
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var single request
+		var batch []request
+		if json.Unmarshal(body, &batch) == nil {
+			if slices.ContainsFunc(batch, func(req request) bool { return slices.Contains(methods, req.Method) }) {
+				responses := make([]string, 0, len(batch))
+				for _, req := range batch {
+					responses = append(responses, rejectedError(req.ID))
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = fmt.Fprintf(w, "[%s]", strings.Join(responses, ","))
+				return
+			}
+		} else if json.Unmarshal(body, &single) == nil && slices.Contains(methods, single.Method) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprint(w, rejectedError(single.ID))
+			return
+		}
+
+		forward, err := http.NewRequestWithContext(r.Context(), http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		forward.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(forward)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+		w.WriteHeader(resp.StatusCode)
+		_, _ = io.Copy(w, resp.Body)
+	}
+}
+
+func limitedRPC(url string, limit uint64, rejected *atomic.Int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var request struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if json.Unmarshal(body, &request) == nil && request.Method == "eth_getLogs" && len(request.Params) == 1 {
+			var filter struct {
+				FromBlock hexutil.Uint64 `json:"fromBlock"`
+				ToBlock   hexutil.Uint64 `json:"toBlock"`
+			}
+			if json.Unmarshal(request.Params[0], &filter) == nil && uint64(filter.ToBlock-filter.FromBlock)+1 > limit {
+				rejected.Add(1)
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":-32005,"message":"query returned more than 10000 results"}}`, request.ID)
+				return
+			}
+		}
+
+		forward, err := http.NewRequestWithContext(r.Context(), http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		forward.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(forward)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+		w.WriteHeader(resp.StatusCode)
+		_, _ = io.Copy(w, resp.Body)
+	}
+}
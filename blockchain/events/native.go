@@ -0,0 +1,378 @@
+// Copyright (C) 2024 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package events
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	"storj.io/storjscan/blockchain"
+	"storj.io/storjscan/common"
+)
+
+// ErrNativeScan is the error class of the native transfers scans, which don't fail the scans of the token transfers.
+var ErrNativeScan = errs.Class("native transfers scan")
+
+// nativeTracer is the tracer used to find the internal native currency transfers of the transactions.
+const nativeTracer = "callTracer"
+
+// nativeTransfer is a transfer of the native currency of a chain, either by a transaction or by one of its internal calls.
+type nativeTransfer struct {
+	txHash    common.Hash
+	txIndex   int
+	callIndex int
+	from      common.Address
+	to        common.Address
+	value     *big.Int
+}
+
+// callFrame is a call of a transaction traced by the call tracer.
+type callFrame struct {
+	Type  string         `json:"type"`
+	From  common.Address `json:"from"`
+	To    common.Address `json:"to"`
+	Value *hexutil.Big   `json:"value"`
+	Error string         `json:"error"`
+	Calls []callFrame    `json:"calls"`
+}
+
+// txTrace is the call trace of a transaction.
+type txTrace struct {
+	TxHash common.Hash `json:"txHash"`
+	Result callFrame   `json:"result"`
+	Error  string      `json:"error"`
+}
+
+// nativeClient is a chain client able to return the blocks, receipts and call traces needed to find native transfers.
+type nativeClient interface {
+	blockchain.ChainClient
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	CallContext(ctx context.Context, result any, method string, args ...any) error
+	BatchCallContext(ctx context.Context, batch []rpc.BatchElem) error
+}
+
+// getNativeEvents returns the native currency transfers to the addresses, or from them as well if outgoing transfers
+// are tracked, within the given block range (inclusive). Internal transfers are found by tracing the transactions of
+// the blocks, falling back to the top level transactions when the node doesn't support call tracing. Only the blocks
+// changing the balance or the nonce of a wallet are scanned, unless the wallets are too many to be followed or the
+// node pruned the states of the range, in which case every block of the range is scanned and its transfers are matched
+// against the wallets locally.
+func (events *Service) getNativeEvents(ctx context.Context, client nativeClient, endpoint common.EthEndpoint, start, end uint64, walletsList []common.Address) (_ []TransferEvent, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	walletSet := make(map[common.Address]struct{}, len(walletsList))
+	for _, wallet := range walletsList {
+		walletSet[wallet] = struct{}{}
+	}
+	isWallet := func(address common.Address) bool {
+		_, ok := walletSet[address]
+		return ok
+	}
+	match := func(from, to common.Address) bool {
+		return isWallet(to) || (events.config.Outgoing && isWallet(from))
+	}
+	nativeCurrency := endpoint.GetNative().Currency()
+
+	scanAll := events.matchLocally(len(walletsList))
+	var blocks []uint64
+	if !scanAll {
+		blocks, err = events.changedBlocks(ctx, client, start, end, walletsList)
+		if err != nil {
+			if !isMissingStateError(err) {
+				return nil, err
+			}
+			// only archive nodes keep the states of all the blocks
+			mon.Counter("native_missing_states").Inc(1)
+			events.log.Warn("historical states are not available, scanning every block for native transfers",
+				zap.Int64("Chain ID", endpoint.ChainID), zap.Uint64("From", start), zap.Uint64("To", end), zap.Error(err))
+			scanAll = true
+		}
+	}
+	if scanAll {
+		for number := start; number <= end; number++ {
+			blocks = append(blocks, number)
+		}
+	}
+
+	tracing := true
+	var nativeEvents []TransferEvent
+	for _, number := range blocks {
+		block, err := client.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+		if err != nil {
+			return nil, err
+		}
+		// empty blocks, such as the genesis block which can't be traced, transfer nothing
+		if len(block.Transactions()) == 0 {
+			continue
+		}
+
+		var transfers []nativeTransfer
+		if tracing {
+			transfers, err = traceNativeTransfers(ctx, client, block, match)
+			if err != nil {
+				if !isTracingUnsupported(err) {
+					return nil, err
+				}
+				events.log.Warn("call tracing is not supported, looking for top level native transfers only",
+					zap.Int64("Chain ID", endpoint.ChainID), zap.Error(err))
+				tracing = false
+			}
+		}
+		if !tracing {
+			transfers, err = topLevelNativeTransfers(ctx, client, endpoint.ChainID, block, match)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, transfer := range transfers {
+			event := TransferEvent{
+				ChainID:     endpoint.ChainID,
+				From:        transfer.from,
+				To:          transfer.to,
+				BlockHash:   block.Hash(),
+				BlockNumber: block.Number().Int64(),
+				TxHash:      transfer.txHash,
+				LogIndex:    transfer.txIndex,
+				TraceIndex:  transfer.callIndex + 1,
+				Contract:    common.NativeAddress,
+				TokenValue:  common.TokenAmountFromBig(transfer.value, nativeCurrency),
+			}
+			events.log.Debug("found native transfer",
+				zap.Int64("Chain ID", event.ChainID),
+				zap.String("From", event.From.String()),
+				zap.String("To", event.To.String()),
+				zap.String("Transaction Hash", event.TxHash.String()),
+				zap.Int64("Block Number", event.BlockNumber),
+				zap.Int("Call Index", transfer.callIndex),
+			)
+			if isWallet(transfer.to) {
+				nativeEvents = append(nativeEvents, event)
+			}
+			if events.config.Outgoing && isWallet(transfer.from) {
+				event.Outgoing = true
+				nativeEvents = append(nativeEvents, event)
+			}
+		}
+	}
+	return nativeEvents, nil
+}
+
+// walletState is the native currency balance and the nonce of a wallet at a block.
+type walletState struct {
+	balance hexutil.Big
+	nonce   hexutil.Uint64
+}
+
+// changedBlocks returns the blocks of the given range (inclusive) changing the balance or the nonce of one of the
+// wallets. The range is bisected on the states of the wallets at its ends, so that the number of requests grows with
+// the number of changes rather than with the number of blocks. A wallet spending exactly what it received within
+// the range changes its nonce, so no transfer is missed as long as the wallets are externally owned accounts.
+func (events *Service) changedBlocks(ctx context.Context, client nativeClient, start, end uint64, wallets []common.Address) (_ []uint64, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	// the state before the genesis block is empty
+	before := make([]walletState, len(wallets))
+	if start > 0 {
+		before, err = events.walletStates(ctx, client, wallets, start-1)
+		if err != nil {
+			return nil, err
+		}
+	}
+	after, err := events.walletStates(ctx, client, wallets, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var blocks []uint64
+	var bisect func(from, to uint64, wallets []common.Address, before, after []walletState) error
+	bisect = func(from, to uint64, wallets []common.Address, before, after []walletState) error {
+		wallets, before, after = changedWallets(wallets, before, after)
+		switch {
+		case len(wallets) == 0:
+			return nil
+		case from == to:
+			blocks = append(blocks, from)
+			return nil
+		}
+		middle := from + (to-from)/2
+		states, err := events.walletStates(ctx, client, wallets, middle)
+		if err != nil {
+			return err
+		}
+		if err := bisect(from, middle, wallets, before, states); err != nil {
+			return err
+		}
+		return bisect(middle+1, to, wallets, states, after)
+	}
+	if err := bisect(start, end, wallets, before, after); err != nil {
+		return nil, err
+	}
+	mon.IntVal("native_changed_blocks").Observe(int64(len(blocks)))
+	return blocks, nil
+}
+
+// changedWallets returns the wallets whose state changed between the before and after states, along with these states.
+func changedWallets(wallets []common.Address, before, after []walletState) (changed []common.Address, changedBefore, changedAfter []walletState) {
+	for i, wallet := range wallets {
+		if before[i].nonce == after[i].nonce && before[i].balance.ToInt().Cmp(after[i].balance.ToInt()) == 0 {
+			continue
+		}
+		changed = append(changed, wallet)
+		changedBefore = append(changedBefore, before[i])
+		changedAfter = append(changedAfter, after[i])
+	}
+	return changed, changedBefore, changedAfter
+}
+
+// walletStates returns the states of the wallets at the given block, requested in JSON-RPC batches of
+// AddressBatchSize wallets.
+func (events *Service) walletStates(ctx context.Context, client nativeClient, wallets []common.Address, number uint64) (_ []walletState, err error) {
+	block := hexutil.EncodeUint64(number)
+	states := make([]walletState, len(wallets))
+	batchSize := max(events.config.AddressBatchSize, 1)
+	for i := 0; i < len(wallets); i += batchSize {
+		var batch []rpc.BatchElem
+		for j := i; j < min(i+batchSize, len(wallets)); j++ {
+			batch = append(batch,
+				rpc.BatchElem{Method: "eth_getBalance", Args: []any{wallets[j], block}, Result: &states[j].balance},
+				rpc.BatchElem{Method: "eth_getTransactionCount", Args: []any{wallets[j], block}, Result: &states[j].nonce},
+			)
+		}
+		if err := client.BatchCallContext(ctx, batch); err != nil {
+			return nil, err
+		}
+		for _, elem := range batch {
+			if elem.Error != nil {
+				return nil, elem.Error
+			}
+		}
+	}
+	return states, nil
+}
+
+// traceNativeTransfers returns the successful native currency transfers of the block transactions and their
+// internal calls accepted by match.
+func traceNativeTransfers(ctx context.Context, client nativeClient, block *types.Block, match func(from, to common.Address) bool) (_ []nativeTransfer, err error) {
+	var traces []txTrace
+	err = client.CallContext(ctx, &traces, "debug_traceBlockByHash", block.Hash(), map[string]string{"tracer": nativeTracer})
+	if err != nil {
+		return nil, err
+	}
+	if len(traces) != len(block.Transactions()) {
+		return nil, errs.New("got %d transaction traces for %d transactions of block %s", len(traces), len(block.Transactions()), block.Hash())
+	}
+
+	var transfers []nativeTransfer
+	for txIndex, trace := range traces {
+		if trace.Error != "" {
+			return nil, errs.New("failed to trace transaction %s: %s", block.Transactions()[txIndex].Hash(), trace.Error)
+		}
+		callIndex := 0
+		var walk func(frame callFrame)
+		walk = func(frame callFrame) {
+			index := callIndex
+			callIndex++
+			// reverted calls, including the calls they made, transfer nothing
+			if frame.Error != "" {
+				return
+			}
+			if frame.Value != nil && frame.Value.ToInt().Sign() > 0 && frame.Type != "DELEGATECALL" && frame.Type != "STATICCALL" && match(frame.From, frame.To) {
+				transfers = append(transfers, nativeTransfer{
+					txHash:    block.Transactions()[txIndex].Hash(),
+					txIndex:   txIndex,
+					callIndex: index,
+					from:      frame.From,
+					to:        frame.To,
+					value:     frame.Value.ToInt(),
+				})
+			}
+			for _, call := range frame.Calls {
+				walk(call)
+			}
+		}
+		walk(trace.Result)
+	}
+	return transfers, nil
+}
+
+// topLevelNativeTransfers returns the native currency transfers of the successful block transactions accepted by match.
+// Transactions whose sender can't be recovered, such as the deposit transactions of L2 chains, are skipped.
+func topLevelNativeTransfers(ctx context.Context, client nativeClient, chainID int64, block *types.Block, match func(from, to common.Address) bool) (_ []nativeTransfer, err error) {
+	signer := types.LatestSignerForChainID(big.NewInt(chainID))
+
+	var transfers []nativeTransfer
+	for txIndex, tx := range block.Transactions() {
+		if tx.To() == nil || tx.Value().Sign() <= 0 {
+			continue
+		}
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			mon.Counter("native_unknown_senders").Inc(1)
+			continue
+		}
+		if !match(from, *tx.To()) {
+			continue
+		}
+		receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+		if err != nil {
+			return nil, err
+		}
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			continue
+		}
+		transfers = append(transfers, nativeTransfer{
+			txHash:  tx.Hash(),
+			txIndex: txIndex,
+			from:    from,
+			to:      *tx.To(),
+			value:   tx.Value(),
+		})
+	}
+	return transfers, nil
+}
+
+// missingStateErrors are fragments of the JSON-RPC error messages of nodes which pruned the state of a block.
+var missingStateErrors = []string{
+	"missing trie node",
+	"historical state",
+	"state is not available",
+	"state not available",
+	"state histories",
+	"pruned",
+}
+
+// isMissingStateError reports whether a state query was rejected by the node because it pruned the state of the
+// block, as nodes which aren't archive nodes do.
+func isMissingStateError(err error) bool {
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	message := strings.ToLower(rpcErr.Error())
+	for _, fragment := range missingStateErrors {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTracingUnsupported reports whether the error is caused by the node not supporting call tracing.
+func isTracingUnsupported(err error) bool {
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	return rpcErr.ErrorCode() == -32601 || strings.Contains(rpcErr.Error(), "tracer")
+}
@@ -0,0 +1,244 @@
+// Copyright (C) 2024 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package events
+
+import (
+	"context"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+
+	"storj.io/common/sync2"
+	"storj.io/storjscan/blockchain"
+	"storj.io/storjscan/common"
+)
+
+// ErrSubscriber is an error class for the transfer log subscriber.
+var ErrSubscriber = errs.Class("Subscriber")
+
+// transferTopic is the topic of the ERC20 Transfer event logs.
+var transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// subscriptionBuffer is the number of logs buffered while the claimed wallets are loaded.
+const subscriptionBuffer = 128
+
+// Subscriber subscribes to the transfer logs of the token contracts on WebSocket and IPC endpoints and triggers the
+// indexer as soon as a transfer to a claimed wallet is mined. The indexer keeps polling at its interval, which covers
+// the chains while they are not subscribed, and it is triggered on every subscription to backfill the missed blocks.
+// The triggers received while the indexer runs are coalesced into a single run, so that reading the logs never waits
+// for the indexer.
+//
+// architecture: Service
+type Subscriber struct {
+	log           *zap.Logger
+	service       *Service
+	subscriptions blockchain.Subscriptions
+	indexer       *Chore
+	endpoints     []common.EthEndpoint
+
+	triggers  chan struct{}
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// NewSubscriber creates new transfer log subscriber triggering the given indexer.
+func NewSubscriber(log *zap.Logger, service *Service, subscriptions blockchain.Subscriptions, indexer *Chore, endpoints []common.EthEndpoint) *Subscriber {
+	return &Subscriber{
+		log:           log,
+		service:       service,
+		subscriptions: subscriptions,
+		indexer:       indexer,
+		endpoints:     endpoints,
+		triggers:      make(chan struct{}, 1),
+		closed:        make(chan struct{}),
+	}
+}
+
+// Run subscribes to the transfer logs of the subscribable endpoints until the context is canceled or the subscriber
+// is closed.
+func (subscriber *Subscriber) Run(ctx context.Context) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-subscriber.closed:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var group errgroup.Group
+	group.Go(func() error {
+		subscriber.runIndexer(ctx)
+		return nil
+	})
+	for _, endpoint := range subscriber.endpoints {
+		if !slices.ContainsFunc(endpoint.GetURLs(), isSubscribable) {
+			subscriber.log.Debug("endpoint doesn't support subscriptions, polling only", zap.Int64("Chain ID", endpoint.ChainID))
+			continue
+		}
+		group.Go(func() error {
+			subscriber.runEndpoint(ctx, endpoint)
+			return nil
+		})
+	}
+	return group.Wait()
+}
+
+// runIndexer runs the indexer once for all the triggers received since its last run.
+func (subscriber *Subscriber) runIndexer(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-subscriber.triggers:
+			subscriber.indexer.Loop.TriggerWait()
+		}
+	}
+}
+
+// trigger makes the indexer run without waiting for it, a trigger received while a run is pending is merged into it.
+func (subscriber *Subscriber) trigger() {
+	select {
+	case subscriber.triggers <- struct{}{}:
+	default:
+	}
+}
+
+// runEndpoint keeps the endpoint subscribed, subscribing again after ResubscribeDelay when the subscription fails.
+// The failed providers are scored down, so that the next subscription goes to another provider of the endpoint if it
+// has several.
+func (subscriber *Subscriber) runEndpoint(ctx context.Context, endpoint common.EthEndpoint) {
+	for {
+		err := subscriber.subscriptions.Subscribe(ctx, endpoint, func(client blockchain.ChainClient) error {
+			return subscriber.subscribe(ctx, endpoint, client)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		mon.Counter("subscription_failures").Inc(1)
+		subscriber.log.Warn("transfer log subscription failed, polling until subscribed again",
+			zap.Int64("Chain ID", endpoint.ChainID), zap.Error(ErrSubscriber.Wrap(err)))
+		if !sync2.Sleep(ctx, subscriber.service.config.Indexer.ResubscribeDelay) {
+			return
+		}
+	}
+}
+
+// subscribe subscribes to the transfer logs of the endpoint token contracts and triggers the indexer for each new
+// block with a transfer to a claimed wallet, or from it if outgoing transfers are tracked. It returns when the
+// subscription fails.
+func (subscriber *Subscriber) subscribe(ctx context.Context, endpoint common.EthEndpoint, client blockchain.ChainClient) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	filterer, ok := client.(ethereum.LogFilterer)
+	if !ok {
+		return errs.New("chain client doesn't support subscriptions")
+	}
+
+	query := ethereum.FilterQuery{Topics: [][]common.Hash{{transferTopic}}}
+	for _, token := range endpoint.GetTokens() {
+		contract, err := token.Address()
+		if err != nil {
+			return err
+		}
+		query.Addresses = append(query.Addresses, contract)
+	}
+
+	logs := make(chan types.Log, subscriptionBuffer)
+	sub, err := filterer.SubscribeFilterLogs(ctx, query, logs)
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+
+	subscriber.log.Info("subscribed to transfer logs", zap.Int64("Chain ID", endpoint.ChainID))
+	// index the blocks mined while the chain was not subscribed
+	subscriber.trigger()
+
+	wallets, err := subscriber.claimedWallets(ctx)
+	if err != nil {
+		return err
+	}
+	walletsLoaded := time.Now()
+
+	var indexedBlock uint64
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-sub.Err():
+			if err == nil {
+				err = errs.New("subscription closed")
+			}
+			return err
+		case log := <-logs:
+			// the blocks of removed logs are re-indexed through the reorg buffer of the indexer
+			if log.Removed || len(log.Topics) < 3 || log.BlockNumber <= indexedBlock {
+				continue
+			}
+			// wallets claimed since the last refresh are picked up by polling until the next one
+			if time.Since(walletsLoaded) > subscriber.service.config.Indexer.Interval {
+				if wallets, err = subscriber.claimedWallets(ctx); err != nil {
+					return err
+				}
+				walletsLoaded = time.Now()
+			}
+
+			// the indexed addresses are the last bytes of their topics
+			from := common.Address(log.Topics[1][common.HashLength-common.AddrLength:])
+			to := common.Address(log.Topics[2][common.HashLength-common.AddrLength:])
+			_, incoming := wallets[to]
+			_, outgoing := wallets[from]
+			if !incoming && !(outgoing && subscriber.service.config.Outgoing) {
+				continue
+			}
+
+			mon.Counter("subscription_transfers").Inc(1)
+			subscriber.log.Debug("received transfer log, indexing",
+				zap.Int64("Chain ID", endpoint.ChainID),
+				zap.String("Transaction Hash", log.TxHash.String()),
+				zap.Uint64("Block Number", log.BlockNumber),
+			)
+			subscriber.trigger()
+			indexedBlock = log.BlockNumber
+		}
+	}
+}
+
+// claimedWallets returns the set of claimed wallets.
+func (subscriber *Subscriber) claimedWallets(ctx context.Context) (map[common.Address]string, error) {
+	return subscriber.service.walletsDB.ListAll(ctx)
+}
+
+// Close stops the subscriber.
+func (subscriber *Subscriber) Close() error {
+	subscriber.closeOnce.Do(func() { close(subscriber.closed) })
+	return nil
+}
+
+// isSubscribable reports whether the endpoint URL supports subscriptions, which are only available on WebSocket
+// and IPC connections.
+func isSubscribable(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	switch parsed.Scheme {
+	case "ws", "wss", "":
+		return true
+	default:
+		return false
+	}
+}
@@ -4,10 +4,12 @@
 package blockchain
 
 import (
+	"container/list"
 	"context"
+	"sync"
 	"time"
 
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/zeebo/errs"
 	"go.uber.org/zap"
 
@@ -26,6 +28,8 @@ type Header struct {
 	Hash      common.Hash
 	Number    int64
 	Timestamp time.Time
+	// ParentHash is the hash of the parent block, zero for the headers cached before parent hashes were stored.
+	ParentHash common.Hash
 }
 
 // HeadersDB is ethereum blockchain block header indexed cache.
@@ -34,39 +38,96 @@ type Header struct {
 type HeadersDB interface {
 	// Insert inserts new header to cache db.
 	Insert(ctx context.Context, header Header) error
+	// InsertMany inserts the headers to cache db in bulk, skipping the headers already in it.
+	InsertMany(ctx context.Context, headers []Header) error
 	// Delete deletes header from db by hash.
 	Delete(ctx context.Context, ChainID int64, hash common.Hash) error
-	// DeleteBefore deletes headers before the given time.
-	DeleteBefore(ctx context.Context, before time.Time) (err error)
+	// DeleteBefore deletes headers of the chain before the given time.
+	DeleteBefore(ctx context.Context, chainID int64, before time.Time) (err error)
+	// DeleteBeforeNumber deletes headers of the chain with a number lower than before.
+	DeleteBeforeNumber(ctx context.Context, chainID int64, before int64) (err error)
 	// Get retrieves header by hash.
 	Get(ctx context.Context, ChainID int64, hash common.Hash) (Header, error)
 	// GetByNumber retrieves header by number.
 	GetByNumber(ctx context.Context, ChainID int64, number int64) (Header, error)
-	// List retrieves all headers stored in cache db.
-	List(ctx context.Context) ([]Header, error)
+	// GetLatest retrieves the header of the chain with the highest number.
+	GetLatest(ctx context.Context, chainID int64) (Header, error)
+	// List retrieves a page of at most limit headers of the chain, ordered by number and hash, after the cursor or
+	// from the first header if the cursor is nil.
+	List(ctx context.Context, chainID int64, cursor *HeadersCursor, limit int) (HeadersPage, error)
+	// ListChainIDs retrieves the IDs of the chains with headers stored in cache db.
+	ListChainIDs(ctx context.Context) ([]int64, error)
+}
+
+// HeadersCursor is the position of a header in the listing of the headers of a chain.
+type HeadersCursor struct {
+	Number int64
+	Hash   common.Hash
+}
+
+// HeadersPage is a page of the listing of the headers of a chain.
+type HeadersPage struct {
+	Headers []Header
+	// Next is the cursor of the next page, nil for the last page.
+	Next *HeadersCursor
+}
+
+// HeadersCacheConfig is the configuration of the in-memory tier of the headers cache.
+type HeadersCacheConfig struct {
+	Size int           `help:"maximum number of block headers kept in memory in front of the database, 0 disables the in-memory tier" default:"10000"`
+	TTL  time.Duration `help:"how long a block header is kept in memory" default:"1h"`
 }
 
 // HeadersCache cache for blockchain block headers.
+// Recently used headers are kept in memory in front of the database, so that the payments of the same blocks don't
+// need a database round trip per payment.
 type HeadersCache struct {
-	log *zap.Logger
-	db  HeadersDB
+	log    *zap.Logger
+	db     HeadersDB
+	config HeadersCacheConfig
+
+	mu      sync.Mutex
+	recent  *list.List
+	entries map[headerKey]*list.Element
+}
+
+// headerKey identifies a header in the in-memory tier.
+type headerKey struct {
+	chainID int64
+	hash    common.Hash
+}
+
+// headerEntry is a header of the in-memory tier.
+type headerEntry struct {
+	key     headerKey
+	header  Header
+	expires time.Time
 }
 
 // NewHeadersCache creates new headers cache.
-func NewHeadersCache(log *zap.Logger, db HeadersDB) *HeadersCache {
+func NewHeadersCache(log *zap.Logger, db HeadersDB, config HeadersCacheConfig) *HeadersCache {
 	return &HeadersCache{
-		log: log,
-		db:  db,
+		log:     log,
+		db:      db,
+		config:  config,
+		recent:  list.New(),
+		entries: make(map[headerKey]*list.Element),
 	}
 }
 
 // Get retrieves block header from cache storage or fetches header from client and caches it.
-// TODO: remove direct dependency on go-eth client from public API.
-func (headersCache *HeadersCache) Get(ctx context.Context, client *ethclient.Client, chainID int64, hash common.Hash) (Header, error) {
+func (headersCache *HeadersCache) Get(ctx context.Context, client ChainClient, chainID int64, hash common.Hash) (Header, error) {
 	headersCache.log.Debug("fetching header", zap.Int64("Chain ID", chainID), zap.String("hash", hash.String()))
+	if header, ok := headersCache.recentHeader(chainID, hash); ok {
+		mon.Counter("headers_cache_hits").Inc(1)
+		return header, nil
+	}
+	mon.Counter("headers_cache_misses").Inc(1)
+
 	header, err := headersCache.db.Get(ctx, chainID, hash)
 	switch {
 	case err == nil:
+		headersCache.addRecent(header)
 		return header, nil
 	case errs.Is(err, ErrNoHeader):
 		ethHeader, err := client.HeaderByHash(ctx, hash)
@@ -74,25 +135,133 @@ func (headersCache *HeadersCache) Get(ctx context.Context, client *ethclient.Cli
 			return Header{}, err
 		}
 
-		header := Header{
-			// Note: we are using the provided hash here instead of what was fetched/computed by geth. This is done
-			// to allow support for additional blockchains that do not adhere strictly to the Ethereum block header
-			// format.
-			Hash:      hash,
-			ChainID:   chainID,
-			Number:    ethHeader.Number.Int64(),
-			Timestamp: time.Unix(int64(ethHeader.Time), 0).UTC(),
-		}
-		if chainID == 1 && ethHeader.Hash() != hash {
-			headersCache.log.Warn("ethereum header hash mismatch! geth library may be out of date.", zap.String("geth hash", ethHeader.Hash().String()), zap.String("node hash", hash.String()))
-		}
+		header := headersCache.fromChain(chainID, hash, ethHeader)
 		headersCache.log.Debug("header not found: inserting new header", zap.Int64("Chain ID", header.ChainID), zap.String("hash", header.Hash.String()))
+		mon.Counter("headers_cache_fetches").Inc(1)
 		if err = headersCache.db.Insert(ctx, header); err != nil {
 			return Header{}, err
 		}
 
+		headersCache.addRecent(header)
 		return header, nil
 	default:
 		return Header{}, err
 	}
 }
+
+// GetMany retrieves the block headers of the given hashes from cache storage, fetching the missing headers from
+// client in JSON-RPC batches and caching them.
+func (headersCache *HeadersCache) GetMany(ctx context.Context, client ChainClient, chainID int64, hashes []common.Hash) (_ map[common.Hash]Header, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	headers := make(map[common.Hash]Header, len(hashes))
+	seen := make(map[common.Hash]struct{}, len(hashes))
+	var missing []common.Hash
+	for _, hash := range hashes {
+		if _, ok := seen[hash]; ok {
+			continue
+		}
+		seen[hash] = struct{}{}
+		if header, ok := headersCache.recentHeader(chainID, hash); ok {
+			mon.Counter("headers_cache_hits").Inc(1)
+			headers[hash] = header
+			continue
+		}
+		mon.Counter("headers_cache_misses").Inc(1)
+
+		header, err := headersCache.db.Get(ctx, chainID, hash)
+		switch {
+		case err == nil:
+			headersCache.addRecent(header)
+			headers[hash] = header
+		case errs.Is(err, ErrNoHeader):
+			missing = append(missing, hash)
+		default:
+			return nil, err
+		}
+	}
+	if len(missing) == 0 {
+		return headers, nil
+	}
+
+	headersCache.log.Debug("headers not found: fetching new headers", zap.Int64("Chain ID", chainID), zap.Int("Count", len(missing)))
+	ethHeaders, err := headersByHash(ctx, client, missing)
+	if err != nil {
+		return nil, err
+	}
+	fetched := make([]Header, 0, len(missing))
+	for i, hash := range missing {
+		fetched = append(fetched, headersCache.fromChain(chainID, hash, ethHeaders[i]))
+	}
+	mon.Counter("headers_cache_fetches").Inc(int64(len(fetched)))
+	if err = headersCache.db.InsertMany(ctx, fetched); err != nil {
+		return nil, err
+	}
+	for _, header := range fetched {
+		headersCache.addRecent(header)
+		headers[header.Hash] = header
+	}
+	return headers, nil
+}
+
+// fromChain converts the header of the block with the given hash fetched from the chain.
+func (headersCache *HeadersCache) fromChain(chainID int64, hash common.Hash, ethHeader *types.Header) Header {
+	if chainID == 1 && ethHeader.Hash() != hash {
+		headersCache.log.Warn("ethereum header hash mismatch! geth library may be out of date.", zap.String("geth hash", ethHeader.Hash().String()), zap.String("node hash", hash.String()))
+	}
+	return Header{
+		// Note: we are using the provided hash here instead of what was fetched/computed by geth. This is done
+		// to allow support for additional blockchains that do not adhere strictly to the Ethereum block header
+		// format.
+		Hash:       hash,
+		ChainID:    chainID,
+		Number:     ethHeader.Number.Int64(),
+		Timestamp:  time.Unix(int64(ethHeader.Time), 0).UTC(),
+		ParentHash: ethHeader.ParentHash,
+	}
+}
+
+// recentHeader returns the header from the in-memory tier, if it's there and not expired.
+func (headersCache *HeadersCache) recentHeader(chainID int64, hash common.Hash) (Header, bool) {
+	headersCache.mu.Lock()
+	defer headersCache.mu.Unlock()
+
+	element, ok := headersCache.entries[headerKey{chainID: chainID, hash: hash}]
+	if !ok {
+		return Header{}, false
+	}
+	entry := element.Value.(*headerEntry)
+	if time.Now().After(entry.expires) {
+		headersCache.recent.Remove(element)
+		delete(headersCache.entries, entry.key)
+		return Header{}, false
+	}
+	headersCache.recent.MoveToFront(element)
+	return entry.header, true
+}
+
+// addRecent adds the header to the in-memory tier, evicting the least recently used headers above its size.
+func (headersCache *HeadersCache) addRecent(header Header) {
+	if headersCache.config.Size <= 0 {
+		return
+	}
+
+	headersCache.mu.Lock()
+	defer headersCache.mu.Unlock()
+
+	key := headerKey{chainID: header.ChainID, hash: header.Hash}
+	expires := time.Now().Add(headersCache.config.TTL)
+	if element, ok := headersCache.entries[key]; ok {
+		entry := element.Value.(*headerEntry)
+		entry.header, entry.expires = header, expires
+		headersCache.recent.MoveToFront(element)
+		return
+	}
+	headersCache.entries[key] = headersCache.recent.PushFront(&headerEntry{key: key, header: header, expires: expires})
+	for headersCache.recent.Len() > headersCache.config.Size {
+		oldest := headersCache.recent.Back()
+		headersCache.recent.Remove(oldest)
+		delete(headersCache.entries, oldest.Value.(*headerEntry).key)
+		mon.Counter("headers_cache_evictions").Inc(1)
+	}
+}
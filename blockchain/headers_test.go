@@ -4,12 +4,14 @@
 package blockchain_test
 
 import (
+	"bytes"
 	"crypto/rand"
 	"math/big"
 	"sort"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
@@ -19,6 +21,7 @@ import (
 	"storj.io/storj/shared/dbutil/dbtest"
 	"storj.io/storjscan/blockchain"
 	"storj.io/storjscan/common"
+	"storj.io/storjscan/private/testchain"
 	"storj.io/storjscan/private/testeth"
 	"storj.io/storjscan/storjscandb/storjscandbtest"
 )
@@ -35,6 +38,29 @@ func TestHeadersDBInsert(t *testing.T) {
 	})
 }
 
+func TestHeadersDBInsertMany(t *testing.T) {
+	storjscandbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db *storjscandbtest.DB) {
+		now := time.Now().Round(time.Microsecond).UTC()
+		headers := []blockchain.Header{
+			{ChainID: 1337, Hash: common.Hash{1}, Number: 1, Timestamp: now},
+			{ChainID: 1337, Hash: common.Hash{2}, Number: 2, Timestamp: now.Add(time.Second), ParentHash: common.Hash{1}},
+		}
+		require.NoError(t, db.Headers().InsertMany(ctx, nil))
+		require.NoError(t, db.Headers().InsertMany(ctx, headers[:1]))
+
+		// headers already in the db are skipped
+		require.NoError(t, db.Headers().InsertMany(ctx, headers))
+		for _, header := range headers {
+			inserted, err := db.Headers().Get(ctx, header.ChainID, header.Hash)
+			require.NoError(t, err)
+			require.Equal(t, header, inserted)
+		}
+
+		err := db.Headers().InsertMany(ctx, []blockchain.Header{{Hash: common.Hash{3}, Timestamp: now}})
+		require.Error(t, err)
+	})
+}
+
 func TestHeadersDBDelete(t *testing.T) {
 	storjscandbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db *storjscandbtest.DB) {
 		b := make([]byte, common.HashLength)
@@ -96,39 +122,70 @@ func TestHeadersDBList(t *testing.T) {
 		now := time.Now().Round(time.Microsecond).Add(-time.Hour).UTC()
 		var headers []blockchain.Header
 
-		// create block headers.
+		// create block headers, with two headers of block 5.
 		for i := int64(0); i < 10; i++ {
-			b := make([]byte, common.HashLength)
-			_, err := rand.Read(b)
-			require.NoError(t, err)
-
 			header := blockchain.Header{
 				ChainID:   1337,
-				Hash:      common.HashFromBytes(b),
+				Hash:      common.HashFromBytes(testrand.BytesInt(common.HashLength)),
 				Number:    i,
 				Timestamp: now.Add(time.Duration(i) * time.Minute),
 			}
 			headers = append(headers, header)
 		}
-		// insert headers into db.
+		headers = append(headers, blockchain.Header{
+			ChainID:   1337,
+			Hash:      common.HashFromBytes(testrand.BytesInt(common.HashLength)),
+			Number:    5,
+			Timestamp: now.Add(5 * time.Minute),
+		})
+		// insert headers into db, along with a header of another chain.
 		for _, header := range headers {
 			err := db.Headers().Insert(ctx, header)
 			require.NoError(t, err)
 		}
-
-		list, err := db.Headers().List(ctx)
+		err := db.Headers().Insert(ctx, blockchain.Header{
+			ChainID:   1338,
+			Hash:      headers[0].Hash,
+			Number:    100,
+			Timestamp: now,
+		})
 		require.NoError(t, err)
-		require.Equal(t, len(headers), len(list))
 
 		sort.Slice(headers, func(i, j int) bool {
-			return headers[i].Timestamp.After(headers[j].Timestamp)
+			if headers[i].Number != headers[j].Number {
+				return headers[i].Number < headers[j].Number
+			}
+			return bytes.Compare(headers[i].Hash.Bytes(), headers[j].Hash.Bytes()) < 0
 		})
-		for i, header := range headers {
-			require.Equal(t, header.ChainID, list[i].ChainID)
-			require.Equal(t, header.Hash, list[i].Hash)
-			require.Equal(t, header.Number, list[i].Number)
-			require.Equal(t, header.Timestamp, list[i].Timestamp)
+
+		// the headers of the chain are listed by pages.
+		var list []blockchain.Header
+		var cursor *blockchain.HeadersCursor
+		for {
+			page, err := db.Headers().List(ctx, 1337, cursor, 4)
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(page.Headers), 4)
+			list = append(list, page.Headers...)
+			if page.Next == nil {
+				break
+			}
+			cursor = page.Next
 		}
+		require.Equal(t, headers, list)
+
+		_, err = db.Headers().List(ctx, 1337, nil, 0)
+		require.Error(t, err)
+
+		chainIDs, err := db.Headers().ListChainIDs(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []int64{1337, 1338}, chainIDs)
+
+		latest, err := db.Headers().GetLatest(ctx, 1337)
+		require.NoError(t, err)
+		require.Equal(t, headers[len(headers)-1], latest)
+
+		_, err = db.Headers().GetLatest(ctx, 1339)
+		require.ErrorIs(t, err, blockchain.ErrNoHeader)
 	})
 }
 
@@ -149,7 +206,7 @@ func TestHeadersCache(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		cache := blockchain.NewHeadersCache(logger, db.Headers())
+		cache := blockchain.NewHeadersCache(logger, db.Headers(), blockchain.HeadersCacheConfig{})
 		header, err := cache.Get(ctx, &ethclient.Client{}, chainID, hash)
 		require.NoError(t, err)
 		require.Equal(t, chainID, header.ChainID)
@@ -194,7 +251,7 @@ func testHeadersCacheMissingHeader(t *testing.T, connStr string) {
 		require.NoError(t, err)
 		headerTime := time.Unix(int64(fullHeader.Time), 0).UTC()
 
-		cache := blockchain.NewHeadersCache(logger, db.Headers())
+		cache := blockchain.NewHeadersCache(logger, db.Headers(), blockchain.HeadersCacheConfig{})
 		header, err := cache.Get(ctx, client, chainID, hash)
 		require.NoError(t, err)
 		require.Equal(t, chainID, header.ChainID)
@@ -211,3 +268,144 @@ func testHeadersCacheMissingHeader(t *testing.T, connStr string) {
 		require.Equal(t, headerTime, header.Timestamp)
 	})
 }
+
+func TestHeadersCacheTestChain(t *testing.T) {
+	storjscandbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db *storjscandbtest.DB) {
+		chain := testchain.New(1337)
+		mined := chain.Mine()
+		hash := mined.Hash()
+
+		cache := blockchain.NewHeadersCache(zaptest.NewLogger(t), db.Headers(), blockchain.HeadersCacheConfig{})
+		header, err := cache.Get(ctx, chain, 1337, hash)
+		require.NoError(t, err)
+		require.Equal(t, blockchain.Header{
+			ChainID:    1337,
+			Hash:       hash,
+			Number:     1,
+			Timestamp:  time.Unix(int64(mined.Time), 0).UTC(),
+			ParentHash: mined.ParentHash,
+		}, header)
+
+		// the header is cached
+		cached, err := db.Headers().Get(ctx, 1337, hash)
+		require.NoError(t, err)
+		require.Equal(t, header, cached)
+
+		// unknown blocks are not found on the chain
+		_, err = cache.Get(ctx, chain, 1337, common.Hash{1})
+		require.ErrorIs(t, err, ethereum.NotFound)
+	})
+}
+
+func TestHeadersCacheInMemory(t *testing.T) {
+	storjscandbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db *storjscandbtest.DB) {
+		chain := testchain.New(1337)
+		first, second := chain.Mine().Hash(), chain.Mine().Hash()
+		// a chain without the blocks, headers are only found in the cache
+		empty := testchain.New(1337)
+
+		cache := blockchain.NewHeadersCache(zaptest.NewLogger(t), db.Headers(), blockchain.HeadersCacheConfig{
+			Size: 1,
+			TTL:  time.Hour,
+		})
+		header, err := cache.Get(ctx, chain, 1337, first)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, header.Number)
+
+		// the header is served from memory
+		require.NoError(t, db.Headers().Delete(ctx, 1337, first))
+		header, err = cache.Get(ctx, empty, 1337, first)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, header.Number)
+
+		// the least recently used header is evicted
+		header, err = cache.Get(ctx, chain, 1337, second)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, header.Number)
+		_, err = cache.Get(ctx, empty, 1337, first)
+		require.ErrorIs(t, err, ethereum.NotFound)
+
+		// expired headers are not served from memory
+		cache = blockchain.NewHeadersCache(zaptest.NewLogger(t), db.Headers(), blockchain.HeadersCacheConfig{
+			Size: 10,
+			TTL:  time.Nanosecond,
+		})
+		_, err = cache.Get(ctx, chain, 1337, first)
+		require.NoError(t, err)
+		require.NoError(t, db.Headers().Delete(ctx, 1337, first))
+		_, err = cache.Get(ctx, empty, 1337, first)
+		require.ErrorIs(t, err, ethereum.NotFound)
+	})
+}
+
+func TestHeadersCacheGetMany(t *testing.T) {
+	t.Run("Postgres", func(t *testing.T) {
+		testHeadersCacheGetMany(t, dbtest.PickPostgres(t))
+	})
+	t.Run("Cockroach", func(t *testing.T) {
+		testHeadersCacheGetMany(t, dbtest.PickCockroach(t))
+	})
+}
+
+func testHeadersCacheGetMany(t *testing.T, connStr string) {
+	testeth.Run(t, 1, 1, func(ctx *testcontext.Context, t *testing.T, networks []*testeth.Network) {
+		network := networks[0]
+		chainID := network.ChainID().Int64()
+
+		db, err := storjscandbtest.OpenDB(ctx, zaptest.NewLogger(t), connStr, t.Name(), "T")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ctx.Check(db.Close)
+
+		err = db.MigrateToLatest(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		client := network.Dial()
+		defer client.Close()
+
+		var hashes []common.Hash
+		for range 3 {
+			hashes = append(hashes, network.Commit())
+		}
+
+		// the first header is already cached in the db
+		cached, err := client.HeaderByHash(ctx, hashes[0])
+		require.NoError(t, err)
+		require.NoError(t, db.Headers().Insert(ctx, blockchain.Header{
+			ChainID:   chainID,
+			Hash:      hashes[0],
+			Number:    cached.Number.Int64(),
+			Timestamp: time.Unix(int64(cached.Time), 0).UTC(),
+		}))
+
+		cache := blockchain.NewHeadersCache(zaptest.NewLogger(t), db.Headers(), blockchain.HeadersCacheConfig{})
+		headers, err := cache.GetMany(ctx, client, chainID, append(hashes, hashes[1]))
+		require.NoError(t, err)
+		require.Len(t, headers, len(hashes))
+
+		for _, hash := range hashes {
+			ethHeader, err := client.HeaderByHash(ctx, hash)
+			require.NoError(t, err)
+			expected := blockchain.Header{
+				ChainID:    chainID,
+				Hash:       hash,
+				Number:     ethHeader.Number.Int64(),
+				Timestamp:  time.Unix(int64(ethHeader.Time), 0).UTC(),
+				ParentHash: ethHeader.ParentHash,
+			}
+			require.Equal(t, expected, headers[hash])
+
+			// check that header was written to db
+			header, err := db.Headers().Get(ctx, chainID, hash)
+			require.NoError(t, err)
+			require.Equal(t, expected, header)
+		}
+
+		// unknown blocks are not found on the chain
+		_, err = cache.GetMany(ctx, client, chainID, []common.Hash{{1}})
+		require.ErrorIs(t, err, ethereum.NotFound)
+	})
+}
@@ -0,0 +1,299 @@
+// Copyright (C) 2024 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/spacemonkeygo/monkit/v3"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+
+	"storj.io/common/sync2"
+	"storj.io/storjscan/common"
+)
+
+// rateLimitErrors are fragments of the JSON-RPC error messages of providers rate limiting the requests.
+var rateLimitErrors = []string{
+	"rate limit",
+	"rate exceeded",
+	"too many requests",
+	"try again later",
+}
+
+// Retryable reports whether the error of a chain request is transient, in which case the request may succeed when
+// retried: rate limited or overloaded providers, timeouts and broken connections. Other errors, such as rejected
+// queries, are fatal.
+func Retryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return retryableStatus(httpErr.StatusCode)
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		message := strings.ToLower(rpcErr.Error())
+		for _, fragment := range rateLimitErrors {
+			if strings.Contains(message, fragment) {
+				return true
+			}
+		}
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return isConnectionError(err)
+}
+
+// retryableStatus reports whether the HTTP status of a response is transient.
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryDelay returns the delay before the given retry, starting at RetryBackoff and doubled on each retry up to
+// MaxRetryBackoff, with jitter so that the retries of concurrent requests are spread.
+func (config ClientsConfig) retryDelay(retry int) time.Duration {
+	delay := config.RetryBackoff << retry
+	if delay <= 0 || delay > config.MaxRetryBackoff {
+		delay = config.MaxRetryBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// newLimiter returns the request rate limiter of a provider of the endpoint.
+func (config ClientsConfig) newLimiter(endpoint common.EthEndpoint) *rate.Limiter {
+	requestsPerSecond := config.RequestsPerSecond
+	if endpoint.RequestsPerSecond > 0 {
+		requestsPerSecond = endpoint.RequestsPerSecond
+	}
+	if requestsPerSecond <= 0 {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+	return rate.NewLimiter(rate.Limit(requestsPerSecond), max(int(requestsPerSecond), 1))
+}
+
+// providerClient is the client of a provider. Each of its requests, rather than each call of the pool, is rate limited
+// and retried while it fails transiently. The requests to HTTP providers are rate limited and retried by their
+// transport, so they are only retried here when the provider answered with a transient JSON-RPC error.
+type providerClient struct {
+	client   *ethclient.Client
+	log      *zap.Logger
+	config   ClientsConfig
+	endpoint common.EthEndpoint
+	provider *provider
+}
+
+var (
+	_ ChainClient          = (*providerClient)(nil)
+	_ ethereum.LogFilterer = (*providerClient)(nil)
+	_ bind.ContractCaller  = (*providerClient)(nil)
+	_ batchClient          = (*providerClient)(nil)
+)
+
+// do sends the request, retrying it with backoff while it fails transiently.
+func (client *providerClient) do(ctx context.Context, request func() error) error {
+	for retry := 0; ; retry++ {
+		if !client.provider.http {
+			if err := client.provider.limiter.Wait(ctx); err != nil {
+				return err
+			}
+		}
+		err := request()
+		if retry >= client.config.Retries || !client.retryable(err) {
+			return err
+		}
+
+		mon.Counter("retries", chainTag(client.endpoint)).Inc(1)
+		client.log.Debug("chain request failed transiently, retrying",
+			zap.Int64("Chain ID", client.endpoint.ChainID),
+			zap.String("Provider", redactURL(client.provider.url)),
+			zap.Int("Retry", retry+1),
+			zap.Error(err))
+		if !sync2.Sleep(ctx, client.config.retryDelay(retry)) {
+			return err
+		}
+	}
+}
+
+// retryable reports whether a request failing with the error should be retried on the provider.
+func (client *providerClient) retryable(err error) bool {
+	var rpcErr rpc.Error
+	if client.provider.http && !errors.As(err, &rpcErr) {
+		return false
+	}
+	return Retryable(err)
+}
+
+// ChainID returns the chain ID served by the provider.
+func (client *providerClient) ChainID(ctx context.Context) (chainID *big.Int, err error) {
+	err = client.do(ctx, func() (err error) {
+		chainID, err = client.client.ChainID(ctx)
+		return err
+	})
+	return chainID, err
+}
+
+// HeaderByNumber returns the header of the block with the given number, or of the latest block if number is nil.
+func (client *providerClient) HeaderByNumber(ctx context.Context, number *big.Int) (header *types.Header, err error) {
+	err = client.do(ctx, func() (err error) {
+		header, err = client.client.HeaderByNumber(ctx, number)
+		return err
+	})
+	return header, err
+}
+
+// HeaderByHash returns the header of the block with the given hash.
+func (client *providerClient) HeaderByHash(ctx context.Context, hash common.Hash) (header *types.Header, err error) {
+	err = client.do(ctx, func() (err error) {
+		header, err = client.client.HeaderByHash(ctx, hash)
+		return err
+	})
+	return header, err
+}
+
+// BlockByNumber returns the block with the given number, or the latest block if number is nil.
+func (client *providerClient) BlockByNumber(ctx context.Context, number *big.Int) (block *types.Block, err error) {
+	err = client.do(ctx, func() (err error) {
+		block, err = client.client.BlockByNumber(ctx, number)
+		return err
+	})
+	return block, err
+}
+
+// TransactionReceipt returns the receipt of the transaction with the given hash.
+func (client *providerClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (receipt *types.Receipt, err error) {
+	err = client.do(ctx, func() (err error) {
+		receipt, err = client.client.TransactionReceipt(ctx, txHash)
+		return err
+	})
+	return receipt, err
+}
+
+// FilterLogs returns the logs matching the query.
+func (client *providerClient) FilterLogs(ctx context.Context, query ethereum.FilterQuery) (logs []types.Log, err error) {
+	err = client.do(ctx, func() (err error) {
+		logs, err = client.client.FilterLogs(ctx, query)
+		return err
+	})
+	return logs, err
+}
+
+// SubscribeFilterLogs subscribes to the logs matching the query.
+func (client *providerClient) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (sub ethereum.Subscription, err error) {
+	err = client.do(ctx, func() (err error) {
+		sub, err = client.client.SubscribeFilterLogs(ctx, query, ch)
+		return err
+	})
+	return sub, err
+}
+
+// CodeAt returns the code of the contract at the given block, or at the latest block if blockNumber is nil.
+func (client *providerClient) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) (code []byte, err error) {
+	err = client.do(ctx, func() (err error) {
+		code, err = client.client.CodeAt(ctx, contract, blockNumber)
+		return err
+	})
+	return code, err
+}
+
+// CallContract executes the contract call at the given block, or at the latest block if blockNumber is nil.
+func (client *providerClient) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) (result []byte, err error) {
+	err = client.do(ctx, func() (err error) {
+		result, err = client.client.CallContract(ctx, call, blockNumber)
+		return err
+	})
+	return result, err
+}
+
+// CallContext sends a JSON-RPC request and stores its result into result.
+func (client *providerClient) CallContext(ctx context.Context, result any, method string, args ...any) error {
+	return client.do(ctx, func() error {
+		return client.client.Client().CallContext(ctx, result, method, args...)
+	})
+}
+
+// BatchCallContext sends the requests in a JSON-RPC batch. The batch is retried as a whole when it fails transiently,
+// the errors of its requests are stored into their elements.
+func (client *providerClient) BatchCallContext(ctx context.Context, batch []rpc.BatchElem) error {
+	return client.do(ctx, func() error {
+		return client.client.Client().BatchCallContext(ctx, batch)
+	})
+}
+
+// Close closes the connection to the provider.
+func (client *providerClient) Close() {
+	client.client.Close()
+}
+
+// retryTransport rate limits the requests to an HTTP provider and retries the requests failing transiently.
+type retryTransport struct {
+	base    http.RoundTripper
+	config  ClientsConfig
+	limiter *rate.Limiter
+	tag     monkit.SeriesTag
+}
+
+// RoundTrip sends the request, retrying it with backoff while it fails transiently.
+func (transport *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	attempt := req
+	for retry := 0; ; retry++ {
+		if err := transport.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		resp, err := transport.base.RoundTrip(attempt)
+		transient := Retryable(err) || (err == nil && retryableStatus(resp.StatusCode))
+		// requests without a replayable body can't be retried
+		if !transient || retry >= transport.config.Retries || (req.Body != nil && req.GetBody == nil) {
+			return resp, err
+		}
+
+		delay := transport.config.retryDelay(retry)
+		if resp != nil {
+			// providers may tell how long to back off
+			if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+				delay = max(delay, min(time.Duration(seconds)*time.Second, transport.config.MaxRetryBackoff))
+			}
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+		mon.Counter("retries", transport.tag).Inc(1)
+		if !sync2.Sleep(ctx, delay) {
+			return nil, ctx.Err()
+		}
+
+		attempt = req.Clone(ctx)
+		if req.GetBody != nil {
+			if attempt.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright (C) 2024 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package blockchain_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"storj.io/common/testcontext"
+	"storj.io/storjscan/blockchain"
+	"storj.io/storjscan/common"
+)
+
+func TestClientsRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		retries   int
+		responses []string
+		requests  int64
+		fails     bool
+	}{
+		{name: "rate limited", retries: 3, responses: []string{"429", "429"}, requests: 3},
+		{name: "retries exhausted", retries: 1, responses: []string{"503", "503"}, requests: 2, fails: true},
+		{name: "rate limit error", retries: 3, responses: []string{"rate limit exceeded"}, requests: 2},
+		{name: "fatal error", retries: 3, responses: []string{"execution reverted"}, requests: 1, fails: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := testcontext.New(t)
+
+			var requests atomic.Int64
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req struct {
+					ID     json.RawMessage `json:"id"`
+					Method string          `json:"method"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				if req.Method == "eth_chainId" {
+					_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"0x539"}`, req.ID)
+					return
+				}
+
+				n := int(requests.Add(1)) - 1
+				if n < len(test.responses) {
+					var status int
+					if _, err := fmt.Sscan(test.responses[n], &status); err == nil {
+						w.WriteHeader(status)
+						return
+					}
+					_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":-32000,"message":%q}}`, req.ID, test.responses[n])
+					return
+				}
+				_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"0x10"}`, req.ID)
+			}))
+			defer server.Close()
+
+			clients := blockchain.NewClients(zaptest.NewLogger(t), blockchain.ClientsConfig{
+				Retries:         test.retries,
+				RetryBackoff:    time.Millisecond,
+				MaxRetryBackoff: 10 * time.Millisecond,
+			})
+			defer ctx.Check(clients.Close)
+
+			var number hexutil.Uint64
+			err := clients.Do(ctx, common.EthEndpoint{URL: server.URL, ChainID: 1337}, func(client blockchain.ChainClient) (err error) {
+				return client.(rpcCaller).CallContext(ctx, &number, "eth_blockNumber")
+			})
+			if test.fails {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.EqualValues(t, 16, number)
+			}
+			require.Equal(t, test.requests, requests.Load())
+		})
+	}
+}
+
+func TestClientsRetryWebSocket(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	service := &testETHService{failures: 1}
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("eth", service))
+	defer server.Stop()
+	httpServer := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer httpServer.Close()
+
+	clients := blockchain.NewClients(zaptest.NewLogger(t), blockchain.ClientsConfig{
+		Retries:         3,
+		RetryBackoff:    time.Millisecond,
+		MaxRetryBackoff: 10 * time.Millisecond,
+	})
+	defer ctx.Check(clients.Close)
+
+	endpoint := common.EthEndpoint{URL: "ws" + strings.TrimPrefix(httpServer.URL, "http"), ChainID: 1337, RequestsPerSecond: 5}
+	start := time.Now()
+	calls := 0
+	err := clients.Do(ctx, endpoint, func(client blockchain.ChainClient) error {
+		calls++
+		for i := 0; i < 10; i++ {
+			var number hexutil.Uint64
+			if err := client.(rpcCaller).CallContext(ctx, &number, "eth_blockNumber"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	// only the failed request is retried, not all the requests of the call
+	require.Equal(t, 1, calls)
+	require.EqualValues(t, 11, service.requests.Load())
+	// and each request is rate limited, the burst of 5 requests being followed by 2 requests per 400ms
+	require.GreaterOrEqual(t, time.Since(start), time.Second)
+}
+
+func TestRetryable(t *testing.T) {
+	require.False(t, blockchain.Retryable(nil))
+	require.False(t, blockchain.Retryable(context.Canceled))
+	require.False(t, blockchain.Retryable(rpc.HTTPError{StatusCode: http.StatusBadRequest}))
+	require.False(t, blockchain.Retryable(testRPCError{code: -32000, message: "execution reverted"}))
+	require.False(t, blockchain.Retryable(testRPCError{code: -32005, message: "query returned more than 10000 results"}))
+
+	require.True(t, blockchain.Retryable(rpc.HTTPError{StatusCode: http.StatusTooManyRequests}))
+	require.True(t, blockchain.Retryable(rpc.HTTPError{StatusCode: http.StatusServiceUnavailable}))
+	require.True(t, blockchain.Retryable(testRPCError{code: -32005, message: "project ID request rate exceeded"}))
+	require.True(t, blockchain.Retryable(context.DeadlineExceeded))
+	require.True(t, blockchain.Retryable(syscall.ECONNRESET))
+}
+
+// testRPCError is a JSON-RPC error answered by a provider.
+type testRPCError struct {
+	code    int
+	message string
+}
+
+func (err testRPCError) Error() string  { return err.message }
+func (err testRPCError) ErrorCode() int { return err.code }
+
+// rpcCaller is a chain client able to send any JSON-RPC request.
+type rpcCaller interface {
+	CallContext(ctx context.Context, result any, method string, args ...any) error
+}
+
+// testETHService answers the JSON-RPC requests of the eth namespace needed by the tests, failing the first block number
+// requests as rate limited.
+type testETHService struct {
+	failures int64
+	requests atomic.Int64
+}
+
+// ChainId returns the chain ID.
+func (service *testETHService) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(1337))
+}
+
+// BlockNumber returns the latest block number.
+func (service *testETHService) BlockNumber() (hexutil.Uint64, error) {
+	if service.requests.Add(1) <= service.failures {
+		return 0, errors.New("rate limit exceeded")
+	}
+	return 16, nil
+}
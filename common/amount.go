@@ -0,0 +1,137 @@
+// Copyright (C) 2024 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package common
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/shopspring/decimal"
+	"github.com/zeebo/errs"
+
+	"storj.io/common/currency"
+)
+
+// TokenAmount is an amount of a token, with its value in base units of arbitrary precision.
+//
+// Token transfers carry uint256 values, which don't fit into the int64 base units of currency.Amount for tokens with
+// many decimals, such as the 18 decimals of most ERC20 tokens. TokenAmount is encoded to JSON the same way as
+// currency.Amount, along with the decimal places of its currency so that amounts of any token can be decoded.
+type TokenAmount struct {
+	baseUnits *big.Int
+	currency  *currency.Currency
+}
+
+// TokenAmountFromBig creates a new TokenAmount from the given base units and in the given currency.
+func TokenAmountFromBig(baseUnits *big.Int, currency *currency.Currency) TokenAmount {
+	return TokenAmount{
+		baseUnits: new(big.Int).Set(baseUnits),
+		currency:  currency,
+	}
+}
+
+// TokenAmountFromBaseUnits creates a new TokenAmount from the given base units and in the given currency.
+func TokenAmountFromBaseUnits(baseUnits int64, currency *currency.Currency) TokenAmount {
+	return TokenAmount{
+		baseUnits: big.NewInt(baseUnits),
+		currency:  currency,
+	}
+}
+
+// BaseUnitsBig returns the value of the amount in its base units.
+func (a TokenAmount) BaseUnitsBig() *big.Int {
+	if a.baseUnits == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(a.baseUnits)
+}
+
+// BaseUnits returns the value of the amount in its base units, truncated to 64 bits when it doesn't fit.
+func (a TokenAmount) BaseUnits() int64 {
+	return a.BaseUnitsBig().Int64()
+}
+
+// Currency returns the currency of the amount.
+func (a TokenAmount) Currency() *currency.Currency {
+	return a.currency
+}
+
+// AsDecimal returns the value of the amount in currency units.
+func (a TokenAmount) AsDecimal() decimal.Decimal {
+	var decimalPlaces int32
+	if a.currency != nil {
+		decimalPlaces = a.currency.DecimalPlaces()
+	}
+	return decimal.NewFromBigInt(a.BaseUnitsBig(), -decimalPlaces)
+}
+
+// Equal returns true if a and other are in the same currency and have the same value.
+func (a TokenAmount) Equal(other TokenAmount) bool {
+	return sameCurrency(a.currency, other.currency) && a.BaseUnitsBig().Cmp(other.BaseUnitsBig()) == 0
+}
+
+// String returns the value of the amount in currency units followed by the currency symbol.
+func (a TokenAmount) String() string {
+	if a.currency == nil {
+		return a.AsDecimal().String()
+	}
+	return a.AsDecimal().String() + " " + a.currency.Symbol()
+}
+
+// tokenAmountJSON is the JSON encoding of TokenAmount, extending the encoding of currency.Amount with the decimal
+// places of the currency.
+type tokenAmountJSON struct {
+	Value    decimal.Decimal `json:"value"`
+	Currency string          `json:"currency"`
+	Decimals *int32          `json:"decimals,omitempty"`
+}
+
+// MarshalJSON marshals the amount into JSON.
+func (a TokenAmount) MarshalJSON() ([]byte, error) {
+	if a.currency == nil {
+		return json.Marshal(tokenAmountJSON{})
+	}
+	decimals := a.currency.DecimalPlaces()
+	return json.Marshal(tokenAmountJSON{
+		Value:    a.AsDecimal(),
+		Currency: a.currency.Symbol(),
+		Decimals: &decimals,
+	})
+}
+
+// UnmarshalJSON unmarshals the amount from JSON. Amounts encoded without the decimal places of their currency, as
+// currency.Amount is, must be in a currency known to currency.FromSymbol.
+func (a *TokenAmount) UnmarshalJSON(data []byte) error {
+	var amountJSON tokenAmountJSON
+	if err := json.Unmarshal(data, &amountJSON); err != nil {
+		return err
+	}
+
+	if amountJSON.Value.IsZero() && amountJSON.Currency == "" {
+		*a = TokenAmount{}
+		return nil
+	}
+
+	curr, err := currency.FromSymbol(amountJSON.Currency)
+	switch {
+	case amountJSON.Decimals != nil && (err != nil || curr.DecimalPlaces() != *amountJSON.Decimals):
+		curr = currency.New(amountJSON.Currency, amountJSON.Currency, *amountJSON.Decimals)
+	case err != nil:
+		return errs.New("unknown currency %q", amountJSON.Currency)
+	}
+
+	*a = TokenAmount{
+		baseUnits: amountJSON.Value.Shift(curr.DecimalPlaces()).Round(0).BigInt(),
+		currency:  curr,
+	}
+	return nil
+}
+
+// sameCurrency returns true if the currencies have the same symbol and decimal places.
+func sameCurrency(a, b *currency.Currency) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Symbol() == b.Symbol() && a.DecimalPlaces() == b.DecimalPlaces()
+}
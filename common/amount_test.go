@@ -0,0 +1,45 @@
+// Copyright (C) 2024 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package common_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/currency"
+	"storj.io/storjscan/common"
+)
+
+func TestTokenAmountJSON(t *testing.T) {
+	// 10^12 STORJ, which doesn't fit into int64 base units
+	baseUnits := new(big.Int).Exp(big.NewInt(10), big.NewInt(20), nil)
+	amount := common.TokenAmountFromBig(baseUnits, currency.StorjToken)
+
+	data, err := json.Marshal(amount)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"value":"1000000000000","currency":"STORJ","decimals":8}`, string(data))
+
+	var decoded common.TokenAmount
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.True(t, amount.Equal(decoded), "expected %s, got %s", amount, decoded)
+	require.Equal(t, currency.StorjToken, decoded.Currency())
+
+	// currencies unknown to currency.FromSymbol are decoded with the encoded decimal places
+	usdc := common.TokenAmountFromBig(baseUnits, currency.New("USDC", "USDC", 6))
+	data, err = json.Marshal(usdc)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"value":"100000000000000","currency":"USDC","decimals":6}`, string(data))
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.True(t, usdc.Equal(decoded), "expected %s, got %s", usdc, decoded)
+
+	// amounts encoded as currency.Amount are decoded when their currency is known
+	require.NoError(t, json.Unmarshal([]byte(`{"value":"1.5","currency":"STORJ"}`), &decoded))
+	require.True(t, common.TokenAmountFromBaseUnits(150000000, currency.StorjToken).Equal(decoded))
+
+	// and the decimal places of unknown currencies can't be told from the value
+	require.Error(t, json.Unmarshal([]byte(`{"value":"1.5","currency":"USDC"}`), &decoded))
+}
@@ -4,16 +4,130 @@
 package common
 
 import (
+	"slices"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/zeebo/errs"
+
+	"storj.io/common/currency"
 )
 
-// EthEndpoint contains the URL and contract address to access a chain API.
+// EthEndpoint contains the URL and token contracts to access a chain API.
+// URLs are additional providers of the same chain, requests fail over to them when URL is unavailable.
+// Contract is the STORJ token contract address, used when no Tokens are configured.
+// Native is the native currency of the chain, ETH when not configured.
+// Confirmations is the number of blocks, including the block of the payment,
+// after which a payment is considered confirmed.
+// RequestsPerSecond limits the requests sent to each provider of the chain, overriding the configured default.
+// RetainBlocks and RetainDays are the number of the latest blocks and of days of block headers of the chain
+// kept in cache, overriding the configured retention.
 type EthEndpoint struct {
-	Name     string `json:"name"`
-	URL      string `json:"url"`
+	Name          string   `json:"name"`
+	URL           string   `json:"url"`
+	URLs          []string `json:"urls,omitempty"`
+	Contract      string   `json:"contract,omitempty"`
+	Tokens        []Token  `json:"tokens,omitempty"`
+	Native        *Token   `json:"native,omitempty"`
+	ChainID       int64    `json:"chainId,string,omitempty"`
+	Confirmations int64    `json:"confirmations,string,omitempty"`
+
+	RequestsPerSecond float64 `json:"requestsPerSecond,string,omitempty"`
+
+	RetainBlocks int64 `json:"retainBlocks,string,omitempty"`
+	RetainDays   int   `json:"retainDays,string,omitempty"`
+}
+
+// GetURLs returns the provider URLs of the endpoint chain, URL first.
+func (endpoint EthEndpoint) GetURLs() []string {
+	var urls []string
+	if endpoint.URL != "" {
+		urls = append(urls, endpoint.URL)
+	}
+	for _, url := range endpoint.URLs {
+		if url != "" && !slices.Contains(urls, url) {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// GetTokens returns the ERC20 tokens accepted on the endpoint chain.
+func (endpoint EthEndpoint) GetTokens() []Token {
+	if len(endpoint.Tokens) > 0 {
+		return endpoint.Tokens
+	}
+	return []Token{{
+		Symbol:   currency.StorjToken.Symbol(),
+		Contract: endpoint.Contract,
+		Decimals: 8,
+		Price:    PriceSourceSTORJ,
+	}}
+}
+
+// GetNative returns the native currency of the endpoint chain, with NativeAddress as its contract.
+func (endpoint EthEndpoint) GetNative() Token {
+	native := Token{
+		Symbol:   "ETH",
+		Decimals: 18,
+		Price:    PriceSourceETH,
+	}
+	if endpoint.Native != nil {
+		native = *endpoint.Native
+	}
+	native.Contract = NativeAddress.Hex()
+	return native
+}
+
+// Token returns the endpoint token with the given contract address, or the native currency for NativeAddress.
+// Transfer events recorded before the contract was tracked have the zero address, which resolves to the first token.
+func (endpoint EthEndpoint) Token(contract Address) (Token, bool) {
+	if contract == NativeAddress {
+		return endpoint.GetNative(), true
+	}
+	tokens := endpoint.GetTokens()
+	if contract == (Address{}) {
+		return tokens[0], true
+	}
+	for _, token := range tokens {
+		if address, err := token.Address(); err == nil && address == contract {
+			return token, true
+		}
+	}
+	return Token{}, false
+}
+
+// NativeAddress is the pseudo contract address standing for the native currency of a chain.
+var NativeAddress = common.HexToAddress("0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE")
+
+const (
+	// PriceSourceSTORJ values the token with the STORJ ticker price.
+	PriceSourceSTORJ = "storj"
+	// PriceSourceUSD values the token at one U.S. dollar, for stablecoins.
+	PriceSourceUSD = "usd"
+	// PriceSourceETH values the token with the ETH ticker price.
+	PriceSourceETH = "eth"
+)
+
+// Token is an ERC20 token accepted for payments on a chain, or the native currency of the chain.
+// Price is the source of the token price: PriceSourceSTORJ (the default), PriceSourceUSD or PriceSourceETH.
+type Token struct {
+	Symbol   string `json:"symbol"`
 	Contract string `json:"contract"`
-	ChainID  int64  `json:"chainId,string,omitempty"`
+	Decimals int32  `json:"decimals,string"`
+	Price    string `json:"price,omitempty"`
+}
+
+// Address returns the token contract address.
+func (token Token) Address() (Address, error) {
+	return AddressFromHex(token.Contract)
+}
+
+// Currency returns the currency token amounts are denominated in.
+func (token Token) Currency() *currency.Currency {
+	if token.Symbol == currency.StorjToken.Symbol() && token.Decimals == 8 {
+		return currency.StorjToken
+	}
+	return currency.New(token.Symbol, token.Symbol, token.Decimals)
 }
 
 // Address is wallet address on eth chain.
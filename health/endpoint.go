@@ -7,11 +7,13 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/spacemonkeygo/monkit/v3"
 	"go.uber.org/zap"
 
+	"storj.io/storjscan/blockchain"
 	"storj.io/storjscan/tokenprice"
 	"storj.io/storjscan/tokens"
 )
@@ -26,6 +28,7 @@ type Endpoint struct {
 	db           Pingable
 	tokenPrice   *tokenprice.Service
 	tokenService *tokens.Service
+	clients      *blockchain.Clients
 }
 
 // Pingable allows access to the storjscandb.
@@ -34,12 +37,13 @@ type Pingable interface {
 }
 
 // NewEndpoint creates a new endpoint instance for the health checker.
-func NewEndpoint(log *zap.Logger, db Pingable, tokenPrice *tokenprice.Service, tokenService *tokens.Service) *Endpoint {
+func NewEndpoint(log *zap.Logger, db Pingable, tokenPrice *tokenprice.Service, tokenService *tokens.Service, clients *blockchain.Clients) *Endpoint {
 	return &Endpoint{
 		log:          log,
 		db:           db,
 		tokenPrice:   tokenPrice,
 		tokenService: tokenService,
+		clients:      clients,
 	}
 }
 
@@ -48,6 +52,7 @@ func (endpoint *Endpoint) Register(router *mux.Router) {
 	router.HandleFunc("/live", endpoint.Live).Methods(http.MethodGet)
 	router.HandleFunc("/ready", endpoint.Ready).Methods(http.MethodGet)
 	router.HandleFunc("/chain-ids", endpoint.chainIDs).Methods(http.MethodGet)
+	router.HandleFunc("/providers", endpoint.providers).Methods(http.MethodGet)
 }
 
 // Live checks if the storjscan service is running.
@@ -55,6 +60,8 @@ func (endpoint *Endpoint) Live(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// chainIDs reports the configured chain ids and the chain id reported by each provider of the chains.
+// Returns 503 if a provider serves another chain than the configured one.
 func (endpoint *Endpoint) chainIDs(w http.ResponseWriter, r *http.Request) {
 	var ctx context.Context
 	var err error
@@ -71,6 +78,65 @@ func (endpoint *Endpoint) chainIDs(w http.ResponseWriter, r *http.Request) {
 		message += fmt.Sprintf("get-chain-ids:ok %v\n", ids)
 	}
 
+	// compare them with the chain ids reported by the providers
+	for _, chain := range endpoint.clients.Status(endpoint.tokenService.GetEndpoints()) {
+		for _, provider := range chain.Providers {
+			switch {
+			case provider.ChainID == 0:
+				message += fmt.Sprintf("chain %d %s %s:unverified\n", chain.ChainID, chain.Name, provider.URL)
+			case provider.ChainID != chain.ChainID:
+				status = http.StatusServiceUnavailable
+				message += fmt.Sprintf("chain %d %s %s:mismatch reported %d\n", chain.ChainID, chain.Name, provider.URL, provider.ChainID)
+				mon.Event("chain-ids-mismatch")
+			default:
+				message += fmt.Sprintf("chain %d %s %s:ok\n", chain.ChainID, chain.Name, provider.URL)
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(status)
+	_, err = w.Write([]byte(message))
+	if err != nil {
+		endpoint.log.Error(fmt.Sprintf("response writer error: %s\n", err.Error()))
+	}
+}
+
+// providers reports the health of the providers of each chain and the active one, which serves the requests.
+// Returns 503 if all the providers of a chain are failing.
+func (endpoint *Endpoint) providers(w http.ResponseWriter, r *http.Request) {
+	var err error
+	status := http.StatusOK
+	message := ""
+
+	for _, chain := range endpoint.clients.Status(endpoint.tokenService.GetEndpoints()) {
+		available := false
+		active := "none"
+		for _, provider := range chain.Providers {
+			if provider.Failures == 0 {
+				available = true
+			}
+			if provider.Active {
+				active = provider.URL
+			}
+		}
+		if available {
+			message += fmt.Sprintf("chain %d %s:ok active %s\n", chain.ChainID, chain.Name, active)
+		} else {
+			status = http.StatusServiceUnavailable
+			message += fmt.Sprintf("chain %d %s:failure\n", chain.ChainID, chain.Name)
+			mon.Event("health-providers-failure")
+		}
+		for _, provider := range chain.Providers {
+			message += fmt.Sprintf("  %s latency:%s head:%d lag:%d failures:%d", provider.URL,
+				provider.Latency.Round(time.Millisecond), provider.Head, provider.Lag, provider.Failures)
+			if provider.Error != "" {
+				message += " error:" + provider.Error
+			}
+			message += "\n"
+		}
+	}
+
 	w.Header().Set("Content-Type", "text/plain")
 	w.WriteHeader(status)
 	_, err = w.Write([]byte(message))
@@ -79,8 +145,10 @@ func (endpoint *Endpoint) chainIDs(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// Ready checks whether the database connection is available and whether the token price and blockchain services are reachable.
-// Returns 503 if database is unreachable. Sends a metric if either token price or blockchain services are unreachable.
+// Ready checks whether the database connection is available, whether the token price and blockchain services are reachable
+// and whether the blockchain providers serve the configured chains.
+// Returns 503 if database is unreachable or a provider serves another chain. Sends a metric if either token price or
+// blockchain services are unreachable.
 // 200 indicates that the storjscan service is ready for use.
 func (endpoint *Endpoint) Ready(w http.ResponseWriter, r *http.Request) {
 	var ctx context.Context
@@ -124,6 +192,25 @@ func (endpoint *Endpoint) Ready(w http.ResponseWriter, r *http.Request) {
 		endpoint.log.Debug("blockchain is ready")
 	}
 
+	// test chain ids of the blockchain providers
+	mismatches := 0
+	for _, chain := range endpoint.clients.Status(endpoint.tokenService.GetEndpoints()) {
+		for _, provider := range chain.Providers {
+			if provider.ChainID != 0 && provider.ChainID != chain.ChainID {
+				mismatches++
+			}
+		}
+	}
+	if mismatches > 0 {
+		status = http.StatusServiceUnavailable
+		message += "chain-ids:failure\n"
+		mon.Event("health-chain-ids-failure")
+		endpoint.log.Error(fmt.Sprintf("chain ids failure: %d providers serve another chain\n", mismatches))
+	} else {
+		message += "chain-ids:ok\n"
+		endpoint.log.Debug("chain ids are verified")
+	}
+
 	w.Header().Set("Content-Type", "text/plain")
 	w.WriteHeader(status)
 	_, err = w.Write([]byte(message))
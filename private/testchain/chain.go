@@ -0,0 +1,178 @@
+// Copyright (C) 2024 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package testchain implements an in-memory chain for testing the services without running a node.
+package testchain
+
+import (
+	"context"
+	"math/big"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/zeebo/errs"
+
+	"storj.io/storjscan/blockchain"
+	"storj.io/storjscan/common"
+)
+
+// TransferTopic is the topic of the ERC20 Transfer event logs.
+var TransferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// Chain is an in-memory chain of headers and logs. It implements blockchain.ChainClient, and blockchain.Chains for
+// the endpoints of its chain ID as their single provider.
+type Chain struct {
+	mu      sync.Mutex
+	chainID int64
+	headers []*types.Header
+	logs    []types.Log
+	pending []types.Log
+	txs     uint64
+}
+
+var (
+	_ blockchain.ChainClient = (*Chain)(nil)
+	_ blockchain.Chains      = (*Chain)(nil)
+)
+
+// New creates a new chain with the given chain ID and its genesis block.
+func New(chainID int64) *Chain {
+	chain := &Chain{chainID: chainID}
+	chain.Mine()
+	return chain
+}
+
+// ChainID returns the chain ID.
+func (chain *Chain) ChainID(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(chain.chainID), nil
+}
+
+// Transfer adds a Transfer log of the ERC20 contract to the next mined block and returns its transaction hash.
+func (chain *Chain) Transfer(contract, from, to common.Address, value *big.Int) common.Hash {
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+
+	chain.txs++
+	log := types.Log{
+		Address: contract,
+		Topics:  []common.Hash{TransferTopic, ethcommon.BytesToHash(from.Bytes()), ethcommon.BytesToHash(to.Bytes())},
+		Data:    ethcommon.LeftPadBytes(value.Bytes(), 32),
+		TxHash:  crypto.Keccak256Hash(big.NewInt(chain.chainID).Bytes(), new(big.Int).SetUint64(chain.txs).Bytes()),
+		TxIndex: uint(len(chain.pending)),
+		Index:   uint(len(chain.pending)),
+	}
+	chain.pending = append(chain.pending, log)
+	return log.TxHash
+}
+
+// Mine adds a block with the pending logs and returns its header.
+func (chain *Chain) Mine() *types.Header {
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+
+	header := &types.Header{
+		Number:     big.NewInt(int64(len(chain.headers))),
+		Difficulty: big.NewInt(0),
+		Time:       uint64(time.Now().Unix()),
+	}
+	if len(chain.headers) > 0 {
+		parent := chain.headers[len(chain.headers)-1]
+		header.ParentHash = parent.Hash()
+		header.Time = max(header.Time, parent.Time+1)
+	}
+	chain.headers = append(chain.headers, header)
+
+	for _, log := range chain.pending {
+		log.BlockNumber = header.Number.Uint64()
+		log.BlockHash = header.Hash()
+		chain.logs = append(chain.logs, log)
+	}
+	chain.pending = nil
+	return types.CopyHeader(header)
+}
+
+// HeaderByNumber returns the header of the block with the given number, or of the latest block if number is nil.
+// Block tags, such as the safe and finalized blocks, are not supported.
+func (chain *Chain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+
+	if number == nil {
+		return types.CopyHeader(chain.headers[len(chain.headers)-1]), nil
+	}
+	if !number.IsInt64() || number.Int64() < 0 || number.Int64() >= int64(len(chain.headers)) {
+		return nil, ethereum.NotFound
+	}
+	return types.CopyHeader(chain.headers[number.Int64()]), nil
+}
+
+// HeaderByHash returns the header of the block with the given hash.
+func (chain *Chain) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+
+	for _, header := range chain.headers {
+		if header.Hash() == hash {
+			return types.CopyHeader(header), nil
+		}
+	}
+	return nil, ethereum.NotFound
+}
+
+// FilterLogs returns the logs matching the query.
+func (chain *Chain) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+
+	from, to := uint64(0), uint64(len(chain.headers)-1)
+	if query.FromBlock != nil {
+		from = query.FromBlock.Uint64()
+	}
+	if query.ToBlock != nil {
+		to = query.ToBlock.Uint64()
+	}
+
+	var logs []types.Log
+	for _, log := range chain.logs {
+		switch {
+		case query.BlockHash != nil && log.BlockHash != *query.BlockHash,
+			query.BlockHash == nil && (log.BlockNumber < from || log.BlockNumber > to),
+			len(query.Addresses) > 0 && !slices.Contains(query.Addresses, log.Address),
+			!matchTopics(log.Topics, query.Topics):
+			continue
+		}
+		logs = append(logs, log)
+	}
+	return logs, nil
+}
+
+// Do calls fn with the chain if the endpoint is of its chain ID.
+func (chain *Chain) Do(ctx context.Context, endpoint common.EthEndpoint, fn func(client blockchain.ChainClient) error) error {
+	if endpoint.ChainID != chain.chainID {
+		return errs.New("chain %d is not served by the test chain %d", endpoint.ChainID, chain.chainID)
+	}
+	return fn(chain)
+}
+
+// DoOthers doesn't call fn, the chain is the single provider of its endpoints.
+func (chain *Chain) DoOthers(ctx context.Context, endpoint common.EthEndpoint, served blockchain.ChainClient, n int, fn func(client blockchain.ChainClient) error) (int, error) {
+	return 0, nil
+}
+
+// matchTopics reports whether the log topics match the filter topics, an empty filter position matching any topic.
+func matchTopics(topics []common.Hash, filter [][]common.Hash) bool {
+	for i, accepted := range filter {
+		if len(accepted) == 0 {
+			continue
+		}
+		if i >= len(topics) || !slices.Contains(accepted, topics[i]) {
+			return false
+		}
+	}
+	return true
+}
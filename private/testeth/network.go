@@ -142,6 +142,11 @@ func (network *Network) HTTPEndpoint() string {
 	return network.stack.HTTPEndpoint()
 }
 
+// WSEndpoint returns WebSocket RPC API endpoint address.
+func (network *Network) WSEndpoint() string {
+	return network.stack.WSEndpoint()
+}
+
 // TokenAddress returns address of deployed test token.
 func (network *Network) TokenAddress() common.Address {
 	return network.token
@@ -155,6 +160,22 @@ func (network *Network) TransactOptions(ctx context.Context, account accounts.Ac
 	return opts
 }
 
+// TransferETH sends value wei from the account to the given address with provided nonce.
+func (network *Network) TransferETH(ctx context.Context, from accounts.Account, nonce int64, to common.Address, value *big.Int) (*types.Transaction, error) {
+	client := network.Dial()
+	defer client.Close()
+
+	gasPrice, err := client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := network.keystore.SignTx(from, types.NewTransaction(uint64(nonce), to, value, params.TxGas, gasPrice, nil), network.ChainID())
+	if err != nil {
+		return nil, err
+	}
+	return tx, client.SendTransaction(ctx, tx)
+}
+
 // WaitForTx block execution until transaction receipt is received or context is cancelled.
 // It manually commits a block to ensure the transaction is included.
 func (network *Network) WaitForTx(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
@@ -226,6 +247,36 @@ func (network *Network) Commit() common.Hash {
 	return network.beacon.Commit()
 }
 
+// Fork rewinds the head of the chain to the given ancestor block. The blocks committed afterwards build a side chain
+// which replaces the blocks following the ancestor.
+func (network *Network) Fork(parentHash common.Hash) error {
+	return network.beacon.Fork(parentHash)
+}
+
+// Reorg simulates a chain reorganization by replacing the last depth blocks of the chain with depth+1 new blocks.
+// It returns the hashes of the replaced blocks. Transactions of the replaced blocks return to the transaction pool
+// and are included again by later commits.
+func (network *Network) Reorg(depth int) ([]common.Hash, error) {
+	chain := network.ethereum.BlockChain()
+	head := chain.CurrentBlock().Number.Uint64()
+	if depth <= 0 || uint64(depth) > head {
+		return nil, errs.New("invalid reorg depth %d for chain head %d", depth, head)
+	}
+
+	var replaced []common.Hash
+	for number := head - uint64(depth) + 1; number <= head; number++ {
+		replaced = append(replaced, chain.GetHeaderByNumber(number).Hash())
+	}
+
+	if err := network.Fork(chain.GetHeaderByNumber(head - uint64(depth)).Hash()); err != nil {
+		return nil, err
+	}
+	for i := 0; i <= depth; i++ {
+		network.Commit()
+	}
+	return replaced, nil
+}
+
 // Close stops the beacon and node, releasing all resources.
 func (network *Network) Close() error {
 	if network.beacon != nil {
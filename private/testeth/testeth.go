@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	_ "github.com/ethereum/go-ethereum/eth/tracers/native" // register the call tracer
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/params"
 
@@ -28,7 +29,10 @@ func Run(t *testing.T, numNetworks, numAccounts int, test func(ctx *testcontext.
 		nodeConfig.HTTPHost = "127.0.0.1"
 		nodeConfig.HTTPPort = 0
 		nodeConfig.AuthPort = 0
-		nodeConfig.HTTPModules = append(nodeConfig.HTTPModules, "eth")
+		nodeConfig.HTTPModules = append(nodeConfig.HTTPModules, "eth", "debug")
+		nodeConfig.WSHost = "127.0.0.1"
+		nodeConfig.WSPort = 0
+		nodeConfig.WSModules = append(nodeConfig.WSModules, "eth")
 		nodeConfig.P2P.MaxPeers = 0
 		nodeConfig.P2P.ListenAddr = ""
 		nodeConfig.P2P.NoDial = true
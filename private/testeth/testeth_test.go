@@ -9,6 +9,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/stretchr/testify/require"
 
@@ -84,6 +85,37 @@ func TestTokenTransfer(t *testing.T) {
 	})
 }
 
+func TestReorg(t *testing.T) {
+	testeth.Run(t, 1, 2, func(ctx *testcontext.Context, t *testing.T, networks []*testeth.Network) {
+		network := networks[0]
+		client := network.Dial()
+		defer client.Close()
+
+		accounts := network.Accounts()
+
+		tk, err := testtoken.NewTestToken(network.TokenAddress(), client)
+		require.NoError(t, err)
+
+		tx, err := tk.Transfer(network.TransactOptions(ctx, accounts[0], 1), accounts[1].Address, big.NewInt(1000))
+		require.NoError(t, err)
+
+		rcpt, err := network.WaitForTx(ctx, tx.Hash())
+		require.NoError(t, err)
+
+		replaced, err := network.Reorg(1)
+		require.NoError(t, err)
+		require.Equal(t, []common.Hash{rcpt.BlockHash}, replaced)
+
+		header, err := client.HeaderByNumber(ctx, rcpt.BlockNumber)
+		require.NoError(t, err)
+		require.NotEqual(t, rcpt.BlockHash, header.Hash())
+
+		head, err := client.BlockNumber(ctx)
+		require.NoError(t, err)
+		require.Greater(t, head, rcpt.BlockNumber.Uint64())
+	})
+}
+
 func TestTokenTransferMultipleNetworks(t *testing.T) {
 	testeth.Run(t, 10, 2, func(ctx *testcontext.Context, t *testing.T, networks []*testeth.Network) {
 		// connect to both networks
@@ -19,6 +19,7 @@ import (
 	"storj.io/storjscan/api"
 	"storj.io/storjscan/blockchain"
 	headerCleanup "storj.io/storjscan/blockchain/cleanup"
+	"storj.io/storjscan/blockchain/continuity"
 	"storj.io/storjscan/blockchain/events"
 	"storj.io/storjscan/common"
 	"storj.io/storjscan/health"
@@ -34,11 +35,14 @@ var mon = monkit.Package()
 // Config wraps storjscan configuration.
 type Config struct {
 	Debug             debug.Config
+	Clients           blockchain.ClientsConfig
+	HeadersCache      blockchain.HeadersCacheConfig
 	Events            events.Config
 	Tokens            tokens.Config
 	TokenPrice        tokenprice.Config
 	TokenPriceCleanup tokenPriceCleanup.Config
 	HeaderCleanup     headerCleanup.Config
+	HeaderContinuity  continuity.Config
 	API               api.Config
 }
 
@@ -48,8 +52,16 @@ type DB interface {
 	Headers() blockchain.HeadersDB
 	// TokenPrice returns database for STORJ token price information.
 	TokenPrice() tokenprice.PriceQuoteDB
+	// ETHPrice returns database for ETH price information.
+	ETHPrice() tokenprice.PriceQuoteDB
 	// Wallets returns database for deposit address information.
 	Wallets() wallets.DB
+	// TransferEvents returns database for indexed transfer events.
+	TransferEvents() events.DB
+	// ScanWatermarks returns database for the last scanned blocks of the satellites.
+	ScanWatermarks() events.WatermarksDB
+	// ReportedEvents returns database for transfer events reported to the satellites.
+	ReportedEvents() events.ReportedDB
 	// Ping checks if the database connection is available.
 	Ping(context.Context) error
 }
@@ -69,9 +81,13 @@ type App struct {
 	}
 
 	Blockchain struct {
-		HeadersCache *blockchain.HeadersCache
-		Events       *events.Service
-		CleanupChore *headerCleanup.Chore
+		Clients         *blockchain.Clients
+		HeadersCache    *blockchain.HeadersCache
+		Events          *events.Service
+		Indexer         *events.Chore
+		Subscriber      *events.Subscriber
+		CleanupChore    *headerCleanup.Chore
+		ContinuityChore *continuity.Chore
 	}
 
 	Tokens struct {
@@ -83,6 +99,7 @@ type App struct {
 		Chore        *tokenprice.Chore
 		CleanupChore *tokenPriceCleanup.Chore
 		Service      *tokenprice.Service
+		ETHService   *tokenprice.Service
 	}
 
 	API struct {
@@ -98,6 +115,8 @@ type App struct {
 	Health struct {
 		Endpoint *health.Endpoint
 	}
+
+	verifyContracts bool
 }
 
 // NewApp creates new storjscan application instance.
@@ -106,25 +125,86 @@ func NewApp(log *zap.Logger, config Config, db DB) (*App, error) {
 		Log: log,
 		DB:  db,
 
+		verifyContracts: config.Tokens.VerifyContracts,
+
 		Servers:  lifecycle.NewGroup(log.Named("servers")),
 		Services: lifecycle.NewGroup(log.Named("services")),
 	}
 
+	var endpoints []common.EthEndpoint
+	err := json.Unmarshal([]byte(config.Tokens.Endpoints), &endpoints)
+	if err != nil {
+		return nil, err
+	}
+
 	{ // blockchain
+		app.Blockchain.Clients = blockchain.NewClients(log.Named("blockchain:clients"), config.Clients)
+
+		// added first so that the clients are closed after all the services using them
+		app.Services.Add(lifecycle.Item{
+			Name:  "blockchain:clients",
+			Run:   app.Blockchain.Clients.Run,
+			Close: app.Blockchain.Clients.Close,
+		})
+
 		app.Blockchain.HeadersCache = blockchain.NewHeadersCache(log.Named("blockchain:headers-cache"),
-			db.Headers())
+			db.Headers(), config.HeadersCache)
+		app.Blockchain.CleanupChore = headerCleanup.NewChore(log.Named("blockchain:cleanup-chore"),
+			db.Headers(), endpoints, config.HeaderCleanup)
+
+		app.Services.Add(lifecycle.Item{
+			Name:  "blockchain:cleanup-chore",
+			Run:   app.Blockchain.CleanupChore.Run,
+			Close: app.Blockchain.CleanupChore.Close,
+		})
+
+		app.Blockchain.ContinuityChore = continuity.NewChore(log.Named("blockchain:continuity-chore"),
+			db.Headers(), app.Blockchain.Clients, endpoints, config.HeaderContinuity)
+
+		app.Services.Add(lifecycle.Item{
+			Name:  "blockchain:continuity-chore",
+			Run:   app.Blockchain.ContinuityChore.Run,
+			Close: app.Blockchain.ContinuityChore.Close,
+		})
+
 		app.Blockchain.Events = events.NewEventsService(log.Named("blockchain:events-service"),
-			db.Wallets(), config.Events)
+			app.Blockchain.Clients, db.Wallets(), db.TransferEvents(), db.ScanWatermarks(), db.ReportedEvents(), config.Events)
+
+		if config.Events.Indexer.Enabled {
+			app.Blockchain.Indexer = events.NewChore(log.Named("blockchain:events-indexer"),
+				app.Blockchain.Events, endpoints, config.Events.Indexer.Interval)
+
+			app.Services.Add(lifecycle.Item{
+				Name:  "blockchain:events-indexer",
+				Run:   app.Blockchain.Indexer.Run,
+				Close: app.Blockchain.Indexer.Close,
+			})
+
+			if config.Events.Indexer.Subscribe {
+				app.Blockchain.Subscriber = events.NewSubscriber(log.Named("blockchain:events-subscriber"),
+					app.Blockchain.Events, app.Blockchain.Clients, app.Blockchain.Indexer, endpoints)
+
+				app.Services.Add(lifecycle.Item{
+					Name:  "blockchain:events-subscriber",
+					Run:   app.Blockchain.Subscriber.Run,
+					Close: app.Blockchain.Subscriber.Close,
+				})
+			}
+		}
 	}
 
 	{ // token price
-		var client tokenprice.Client
+		var client, ethClient tokenprice.Client
 		if config.TokenPrice.UseTestPrices {
 			client = coinmarketcap.NewTestClient()
+			ethClient = coinmarketcap.NewTestClient()
 		} else {
 			client = coinmarketcap.NewClient(config.TokenPrice.CoinmarketcapConfig)
+			ethClient = coinmarketcap.NewETHClient(config.TokenPrice.CoinmarketcapConfig)
 		}
 		app.TokenPrice.Service = tokenprice.NewService(log.Named("tokenprice:service"), db.TokenPrice(), client, config.TokenPrice.PriceWindow)
+		// ETH prices are only retrieved on demand, when valuing native currency payments.
+		app.TokenPrice.ETHService = tokenprice.NewService(log.Named("tokenprice:eth-service"), db.ETHPrice(), ethClient, config.TokenPrice.PriceWindow)
 		app.TokenPrice.Chore = tokenprice.NewChore(log.Named("tokenprice:chore"), app.TokenPrice.Service, config.TokenPrice.Interval)
 
 		app.Services.Add(lifecycle.Item{
@@ -132,26 +212,32 @@ func NewApp(log *zap.Logger, config Config, db DB) (*App, error) {
 			Run:   app.TokenPrice.Chore.Run,
 			Close: app.TokenPrice.Chore.Close,
 		})
+
+		app.TokenPrice.CleanupChore = tokenPriceCleanup.NewChore(log.Named("tokenprice:cleanup-chore"),
+			[]tokenprice.PriceQuoteDB{db.TokenPrice(), db.ETHPrice()}, config.TokenPriceCleanup)
+
+		app.Services.Add(lifecycle.Item{
+			Name:  "tokenprice:cleanup-chore",
+			Run:   app.TokenPrice.CleanupChore.Run,
+			Close: app.TokenPrice.CleanupChore.Close,
+		})
 	}
 
 	{ // tokens
-		var endpoints []common.EthEndpoint
-		err := json.Unmarshal([]byte(config.Tokens.Endpoints), &endpoints)
-		if err != nil {
-			return nil, err
-		}
-
 		app.Tokens.Service = tokens.NewService(log.Named("tokens:service"),
+			app.Blockchain.Clients,
 			endpoints,
 			app.Blockchain.HeadersCache,
 			app.Blockchain.Events,
-			app.TokenPrice.Service)
+			app.TokenPrice.Service,
+			app.TokenPrice.ETHService,
+			config.Tokens.ChainTimeout,
+			config.Tokens.Quorum)
 
 		app.Tokens.Endpoint = tokens.NewEndpoint(log.Named("tokens:endpoint"), app.Tokens.Service)
 	}
 
 	{ // wallets
-		var err error
 		app.Wallets.Service, err = wallets.NewService(log.Named("wallets:service"), db.Wallets())
 		if err != nil {
 			return nil, err
@@ -160,12 +246,10 @@ func NewApp(log *zap.Logger, config Config, db DB) (*App, error) {
 	}
 
 	{ // health check
-		app.Health.Endpoint = health.NewEndpoint(log.Named("health:endpoint"), db, app.TokenPrice.Service, app.Tokens.Service)
+		app.Health.Endpoint = health.NewEndpoint(log.Named("health:endpoint"), db, app.TokenPrice.Service, app.Tokens.Service, app.Blockchain.Clients)
 	}
 
 	{ // API
-		var err error
-
 		app.API.Listener, err = net.Listen("tcp", config.API.Address)
 		if err != nil {
 			return nil, err
@@ -187,7 +271,7 @@ func NewApp(log *zap.Logger, config Config, db DB) (*App, error) {
 		})
 	}
 
-	err := app.API.Server.LogRoutes()
+	err = app.API.Server.LogRoutes()
 	if err != nil {
 		return app, err
 	}
@@ -198,6 +282,18 @@ func NewApp(log *zap.Logger, config Config, db DB) (*App, error) {
 func (app *App) Run(ctx context.Context) (err error) {
 	defer mon.Task()(&ctx)(&err)
 
+	// a provider serving another chain would label its payments with the wrong chain id, providers which can't be
+	// reached yet are verified before their first request
+	if err := app.Blockchain.Clients.VerifyChainIDs(ctx, app.Tokens.Service.GetEndpoints()); err != nil {
+		return err
+	}
+
+	if app.verifyContracts {
+		if err := app.Tokens.Service.VerifyContracts(ctx); err != nil {
+			return err
+		}
+	}
+
 	group, ctx := errgroup.WithContext(ctx)
 
 	app.Servers.Run(ctx, group)
@@ -16,6 +16,7 @@ import (
 	"storj.io/storj/shared/dbutil/pgutil"
 	"storj.io/storj/shared/tagsql"
 	"storj.io/storjscan/blockchain"
+	"storj.io/storjscan/blockchain/events"
 	"storj.io/storjscan/storjscandb/dbx"
 	"storj.io/storjscan/tokenprice"
 	"storj.io/storjscan/wallets"
@@ -112,11 +113,31 @@ func (db *DB) MigrateToLatest(ctx context.Context) error {
 	return migration.Run(ctx, db.log)
 }
 
+// ETHPrice creates new PriceQuoteDB of ETH prices with current DB connection.
+func (db *DB) ETHPrice() tokenprice.PriceQuoteDB {
+	return &ethPriceDB{db: db.DB}
+}
+
 // Headers creates new headersDB with current DB connection.
 func (db *DB) Headers() blockchain.HeadersDB {
 	return &headersDB{db: db.DB}
 }
 
+// ReportedEvents creates new reported transfer events database with current DB connection.
+func (db *DB) ReportedEvents() events.ReportedDB {
+	return &reportedEventsDB{db: db.DB}
+}
+
+// ScanWatermarks creates new scan watermarks database with current DB connection.
+func (db *DB) ScanWatermarks() events.WatermarksDB {
+	return &scanWatermarksDB{db: db.DB}
+}
+
+// TransferEvents creates new transfer events ledger with current DB connection.
+func (db *DB) TransferEvents() events.DB {
+	return &transferEventsDB{db: db.DB}
+}
+
 // TokenPrice creates new PriceQuoteDB with current DB connection.
 func (db *DB) TokenPrice() tokenprice.PriceQuoteDB {
 	return &priceQuoteDB{db: db.DB}
@@ -253,6 +274,103 @@ func (db *DB) PostgresMigration() *migrate.Migration {
 					`DROP TABLE transfer_events;`,
 				},
 			},
+			{
+				DB:          &db.migrationDB,
+				Description: "Add transfer events ledger and indexed blocks tables",
+				Version:     10,
+				Action: migrate.SQL{
+					`CREATE TABLE transfer_events (
+						chain_id bigint NOT NULL,
+						block_hash bytea NOT NULL,
+						block_number bigint NOT NULL,
+						transaction bytea NOT NULL,
+						log_index integer NOT NULL,
+						trace_index integer NOT NULL,
+						from_address bytea NOT NULL,
+						to_address bytea NOT NULL,
+						token_value bytea NOT NULL,
+						contract bytea NOT NULL,
+						created_at timestamp with time zone NOT NULL DEFAULT current_timestamp,
+						PRIMARY KEY ( chain_id, block_hash, log_index, trace_index )
+					);
+					CREATE INDEX transfer_events_to_address_block_number_index ON transfer_events ( to_address, block_number );
+					CREATE TABLE indexed_blocks (
+						chain_id bigint NOT NULL,
+						hash bytea NOT NULL,
+						number bigint NOT NULL,
+						timestamp timestamp with time zone NOT NULL,
+						PRIMARY KEY ( chain_id )
+					);`,
+				},
+			},
+			{
+				DB:          &db.migrationDB,
+				Description: "Add scan watermarks table",
+				Version:     11,
+				Action: migrate.SQL{
+					`CREATE TABLE scan_watermarks (
+						satellite text NOT NULL,
+						chain_id bigint NOT NULL,
+						block_number bigint NOT NULL,
+						PRIMARY KEY ( satellite, chain_id )
+					);`,
+				},
+			},
+			{
+				DB:          &db.migrationDB,
+				Description: "Add reported transfer events table",
+				Version:     12,
+				Action: migrate.SQL{
+					`CREATE TABLE reported_transfer_events (
+						satellite text NOT NULL,
+						chain_id bigint NOT NULL,
+						block_hash bytea NOT NULL,
+						block_number bigint NOT NULL,
+						transaction bytea NOT NULL,
+						log_index integer NOT NULL,
+						trace_index integer NOT NULL,
+						from_address bytea NOT NULL,
+						to_address bytea NOT NULL,
+						token_value bytea NOT NULL,
+						contract bytea NOT NULL,
+						removed boolean NOT NULL,
+						removed_block bigint NOT NULL,
+						created_at timestamp with time zone NOT NULL DEFAULT current_timestamp,
+						PRIMARY KEY ( satellite, chain_id, block_hash, log_index, trace_index )
+					);
+					CREATE INDEX reported_transfer_events_chain_id_block_number_index ON reported_transfer_events ( chain_id, block_number );`,
+				},
+			},
+			{
+				DB:          &db.migrationDB,
+				Description: "Add index for outgoing transfer events",
+				Version:     13,
+				Action: migrate.SQL{
+					`CREATE INDEX transfer_events_from_address_block_number_index ON transfer_events ( from_address, block_number );`,
+				},
+			},
+			{
+				DB:          &db.migrationDB,
+				Description: "Add ETH prices table",
+				Version:     14,
+				Action: migrate.SQL{
+					`CREATE TABLE eth_prices (
+						interval_start timestamp with time zone NOT NULL,
+						price bigint NOT NULL,
+						PRIMARY KEY ( interval_start )
+					);`,
+				},
+			},
+			{
+				DB:          &db.migrationDB,
+				Description: "Add parent hash to block headers",
+				Version:     15,
+				Action: migrate.SQL{
+					// the existing headers get an empty parent hash, which is skipped by the continuity verification
+					`ALTER TABLE block_headers ADD COLUMN parent_hash bytea NOT NULL DEFAULT '';`,
+					`ALTER TABLE block_headers ALTER COLUMN parent_hash DROP DEFAULT;`,
+				},
+			},
 		},
 	}
 }
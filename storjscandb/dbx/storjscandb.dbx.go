@@ -352,16 +352,71 @@ func (obj *pgxDB) Schema() []string {
 	hash bytea NOT NULL,
 	number bigint NOT NULL,
 	timestamp timestamp with time zone NOT NULL,
+	parent_hash bytea NOT NULL,
 	created_at timestamp with time zone NOT NULL DEFAULT current_timestamp,
 	PRIMARY KEY ( chain_id, hash )
 )`,
 
+		`CREATE TABLE eth_prices (
+	interval_start timestamp with time zone NOT NULL,
+	price bigint NOT NULL,
+	PRIMARY KEY ( interval_start )
+)`,
+
+		`CREATE TABLE indexed_blocks (
+	chain_id bigint NOT NULL,
+	hash bytea NOT NULL,
+	number bigint NOT NULL,
+	timestamp timestamp with time zone NOT NULL,
+	PRIMARY KEY ( chain_id )
+)`,
+
+		`CREATE TABLE reported_transfer_events (
+	satellite text NOT NULL,
+	chain_id bigint NOT NULL,
+	block_hash bytea NOT NULL,
+	block_number bigint NOT NULL,
+	transaction bytea NOT NULL,
+	log_index integer NOT NULL,
+	trace_index integer NOT NULL,
+	from_address bytea NOT NULL,
+	to_address bytea NOT NULL,
+	token_value bytea NOT NULL,
+	contract bytea NOT NULL,
+	removed boolean NOT NULL,
+	removed_block bigint NOT NULL,
+	created_at timestamp with time zone NOT NULL DEFAULT current_timestamp,
+	PRIMARY KEY ( satellite, chain_id, block_hash, log_index, trace_index )
+)`,
+
+		`CREATE TABLE scan_watermarks (
+	satellite text NOT NULL,
+	chain_id bigint NOT NULL,
+	block_number bigint NOT NULL,
+	PRIMARY KEY ( satellite, chain_id )
+)`,
+
 		`CREATE TABLE token_prices (
 	interval_start timestamp with time zone NOT NULL,
 	price bigint NOT NULL,
 	PRIMARY KEY ( interval_start )
 )`,
 
+		`CREATE TABLE transfer_events (
+	chain_id bigint NOT NULL,
+	block_hash bytea NOT NULL,
+	block_number bigint NOT NULL,
+	transaction bytea NOT NULL,
+	log_index integer NOT NULL,
+	trace_index integer NOT NULL,
+	from_address bytea NOT NULL,
+	to_address bytea NOT NULL,
+	token_value bytea NOT NULL,
+	contract bytea NOT NULL,
+	created_at timestamp with time zone NOT NULL DEFAULT current_timestamp,
+	PRIMARY KEY ( chain_id, block_hash, log_index, trace_index )
+)`,
+
 		`CREATE TABLE wallets (
 	id bigserial NOT NULL,
 	address bytea NOT NULL,
@@ -372,6 +427,12 @@ func (obj *pgxDB) Schema() []string {
 	PRIMARY KEY ( id )
 )`,
 
+		`CREATE INDEX reported_transfer_events_chain_id_block_number_index ON reported_transfer_events ( chain_id, block_number )`,
+
+		`CREATE INDEX transfer_events_to_address_block_number_index ON transfer_events ( to_address, block_number )`,
+
+		`CREATE INDEX transfer_events_from_address_block_number_index ON transfer_events ( from_address, block_number )`,
+
 		`CREATE INDEX wallets_satellite_index ON wallets ( satellite )`,
 
 		`CREATE UNIQUE INDEX wallets_address_unique_index ON wallets ( address )`,
@@ -383,8 +444,18 @@ func (obj *pgxDB) DropSchema() []string {
 
 		`DROP TABLE IF EXISTS wallets`,
 
+		`DROP TABLE IF EXISTS transfer_events`,
+
 		`DROP TABLE IF EXISTS token_prices`,
 
+		`DROP TABLE IF EXISTS scan_watermarks`,
+
+		`DROP TABLE IF EXISTS reported_transfer_events`,
+
+		`DROP TABLE IF EXISTS indexed_blocks`,
+
+		`DROP TABLE IF EXISTS eth_prices`,
+
 		`DROP TABLE IF EXISTS block_headers`,
 	}
 }
@@ -493,16 +564,71 @@ func (obj *pgxcockroachDB) Schema() []string {
 	hash bytea NOT NULL,
 	number bigint NOT NULL,
 	timestamp timestamp with time zone NOT NULL,
+	parent_hash bytea NOT NULL,
 	created_at timestamp with time zone NOT NULL DEFAULT current_timestamp,
 	PRIMARY KEY ( chain_id, hash )
 )`,
 
+		`CREATE TABLE eth_prices (
+	interval_start timestamp with time zone NOT NULL,
+	price bigint NOT NULL,
+	PRIMARY KEY ( interval_start )
+)`,
+
+		`CREATE TABLE indexed_blocks (
+	chain_id bigint NOT NULL,
+	hash bytea NOT NULL,
+	number bigint NOT NULL,
+	timestamp timestamp with time zone NOT NULL,
+	PRIMARY KEY ( chain_id )
+)`,
+
+		`CREATE TABLE reported_transfer_events (
+	satellite text NOT NULL,
+	chain_id bigint NOT NULL,
+	block_hash bytea NOT NULL,
+	block_number bigint NOT NULL,
+	transaction bytea NOT NULL,
+	log_index integer NOT NULL,
+	trace_index integer NOT NULL,
+	from_address bytea NOT NULL,
+	to_address bytea NOT NULL,
+	token_value bytea NOT NULL,
+	contract bytea NOT NULL,
+	removed boolean NOT NULL,
+	removed_block bigint NOT NULL,
+	created_at timestamp with time zone NOT NULL DEFAULT current_timestamp,
+	PRIMARY KEY ( satellite, chain_id, block_hash, log_index, trace_index )
+)`,
+
+		`CREATE TABLE scan_watermarks (
+	satellite text NOT NULL,
+	chain_id bigint NOT NULL,
+	block_number bigint NOT NULL,
+	PRIMARY KEY ( satellite, chain_id )
+)`,
+
 		`CREATE TABLE token_prices (
 	interval_start timestamp with time zone NOT NULL,
 	price bigint NOT NULL,
 	PRIMARY KEY ( interval_start )
 )`,
 
+		`CREATE TABLE transfer_events (
+	chain_id bigint NOT NULL,
+	block_hash bytea NOT NULL,
+	block_number bigint NOT NULL,
+	transaction bytea NOT NULL,
+	log_index integer NOT NULL,
+	trace_index integer NOT NULL,
+	from_address bytea NOT NULL,
+	to_address bytea NOT NULL,
+	token_value bytea NOT NULL,
+	contract bytea NOT NULL,
+	created_at timestamp with time zone NOT NULL DEFAULT current_timestamp,
+	PRIMARY KEY ( chain_id, block_hash, log_index, trace_index )
+)`,
+
 		`CREATE TABLE wallets (
 	id bigserial NOT NULL,
 	address bytea NOT NULL,
@@ -513,6 +639,12 @@ func (obj *pgxcockroachDB) Schema() []string {
 	PRIMARY KEY ( id )
 )`,
 
+		`CREATE INDEX reported_transfer_events_chain_id_block_number_index ON reported_transfer_events ( chain_id, block_number )`,
+
+		`CREATE INDEX transfer_events_to_address_block_number_index ON transfer_events ( to_address, block_number )`,
+
+		`CREATE INDEX transfer_events_from_address_block_number_index ON transfer_events ( from_address, block_number )`,
+
 		`CREATE INDEX wallets_satellite_index ON wallets ( satellite )`,
 
 		`CREATE UNIQUE INDEX wallets_address_unique_index ON wallets ( address )`,
@@ -524,8 +656,18 @@ func (obj *pgxcockroachDB) DropSchema() []string {
 
 		`DROP TABLE IF EXISTS wallets`,
 
+		`DROP TABLE IF EXISTS transfer_events`,
+
 		`DROP TABLE IF EXISTS token_prices`,
 
+		`DROP TABLE IF EXISTS scan_watermarks`,
+
+		`DROP TABLE IF EXISTS reported_transfer_events`,
+
+		`DROP TABLE IF EXISTS indexed_blocks`,
+
+		`DROP TABLE IF EXISTS eth_prices`,
+
 		`DROP TABLE IF EXISTS block_headers`,
 	}
 }
@@ -592,11 +734,12 @@ nextval:
 }
 
 type BlockHeader struct {
-	ChainId   int64
-	Hash      []byte
-	Number    int64
-	Timestamp time.Time
-	CreatedAt time.Time
+	ChainId    int64
+	Hash       []byte
+	Number     int64
+	Timestamp  time.Time
+	ParentHash []byte
+	CreatedAt  time.Time
 }
 
 func (BlockHeader) _Table() string { return "block_headers" }
@@ -672,6 +815,23 @@ func (f BlockHeader_Timestamp_Field) value() any {
 	return f._value
 }
 
+type BlockHeader_ParentHash_Field struct {
+	_set   bool
+	_null  bool
+	_value []byte
+}
+
+func BlockHeader_ParentHash(v []byte) BlockHeader_ParentHash_Field {
+	return BlockHeader_ParentHash_Field{_set: true, _value: v}
+}
+
+func (f BlockHeader_ParentHash_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
+
 type BlockHeader_CreatedAt_Field struct {
 	_set   bool
 	_null  bool
@@ -689,266 +849,921 @@ func (f BlockHeader_CreatedAt_Field) value() any {
 	return f._value
 }
 
-type TokenPrice struct {
+type EthPrice struct {
 	IntervalStart time.Time
 	Price         int64
 }
 
-func (TokenPrice) _Table() string { return "token_prices" }
+func (EthPrice) _Table() string { return "eth_prices" }
 
-type TokenPrice_Update_Fields struct {
-	Price TokenPrice_Price_Field
+type EthPrice_Update_Fields struct {
+	Price EthPrice_Price_Field
 }
 
-type TokenPrice_IntervalStart_Field struct {
+type EthPrice_IntervalStart_Field struct {
 	_set   bool
 	_null  bool
 	_value time.Time
 }
 
-func TokenPrice_IntervalStart(v time.Time) TokenPrice_IntervalStart_Field {
-	return TokenPrice_IntervalStart_Field{_set: true, _value: v}
+func EthPrice_IntervalStart(v time.Time) EthPrice_IntervalStart_Field {
+	return EthPrice_IntervalStart_Field{_set: true, _value: v}
 }
 
-func (f TokenPrice_IntervalStart_Field) value() any {
+func (f EthPrice_IntervalStart_Field) value() any {
 	if !f._set || f._null {
 		return nil
 	}
 	return f._value
 }
 
-type TokenPrice_Price_Field struct {
+type EthPrice_Price_Field struct {
 	_set   bool
 	_null  bool
 	_value int64
 }
 
-func TokenPrice_Price(v int64) TokenPrice_Price_Field {
-	return TokenPrice_Price_Field{_set: true, _value: v}
+func EthPrice_Price(v int64) EthPrice_Price_Field {
+	return EthPrice_Price_Field{_set: true, _value: v}
 }
 
-func (f TokenPrice_Price_Field) value() any {
+func (f EthPrice_Price_Field) value() any {
 	if !f._set || f._null {
 		return nil
 	}
 	return f._value
 }
 
-type Wallet struct {
-	Id        int64
-	Address   []byte
-	Claimed   *time.Time
-	Satellite string
-	Info      *string
-	CreatedAt time.Time
+type IndexedBlock struct {
+	ChainId   int64
+	Hash      []byte
+	Number    int64
+	Timestamp time.Time
 }
 
-func (Wallet) _Table() string { return "wallets" }
-
-type Wallet_Create_Fields struct {
-	Claimed Wallet_Claimed_Field
-	Info    Wallet_Info_Field
-}
+func (IndexedBlock) _Table() string { return "indexed_blocks" }
 
-type Wallet_Update_Fields struct {
-	Claimed Wallet_Claimed_Field
-	Info    Wallet_Info_Field
+type IndexedBlock_Update_Fields struct {
 }
 
-type Wallet_Id_Field struct {
+type IndexedBlock_ChainId_Field struct {
 	_set   bool
 	_null  bool
 	_value int64
 }
 
-func Wallet_Id(v int64) Wallet_Id_Field {
-	return Wallet_Id_Field{_set: true, _value: v}
+func IndexedBlock_ChainId(v int64) IndexedBlock_ChainId_Field {
+	return IndexedBlock_ChainId_Field{_set: true, _value: v}
 }
 
-func (f Wallet_Id_Field) value() any {
+func (f IndexedBlock_ChainId_Field) value() any {
 	if !f._set || f._null {
 		return nil
 	}
 	return f._value
 }
 
-type Wallet_Address_Field struct {
+type IndexedBlock_Hash_Field struct {
 	_set   bool
 	_null  bool
 	_value []byte
 }
 
-func Wallet_Address(v []byte) Wallet_Address_Field {
-	return Wallet_Address_Field{_set: true, _value: v}
+func IndexedBlock_Hash(v []byte) IndexedBlock_Hash_Field {
+	return IndexedBlock_Hash_Field{_set: true, _value: v}
 }
 
-func (f Wallet_Address_Field) value() any {
+func (f IndexedBlock_Hash_Field) value() any {
 	if !f._set || f._null {
 		return nil
 	}
 	return f._value
 }
 
-type Wallet_Claimed_Field struct {
+type IndexedBlock_Number_Field struct {
 	_set   bool
 	_null  bool
-	_value *time.Time
+	_value int64
 }
 
-func Wallet_Claimed(v time.Time) Wallet_Claimed_Field {
-	return Wallet_Claimed_Field{_set: true, _value: &v}
+func IndexedBlock_Number(v int64) IndexedBlock_Number_Field {
+	return IndexedBlock_Number_Field{_set: true, _value: v}
 }
 
-func Wallet_Claimed_Raw(v *time.Time) Wallet_Claimed_Field {
-	if v == nil {
-		return Wallet_Claimed_Null()
+func (f IndexedBlock_Number_Field) value() any {
+	if !f._set || f._null {
+		return nil
 	}
-	return Wallet_Claimed(*v)
+	return f._value
 }
 
-func Wallet_Claimed_Null() Wallet_Claimed_Field {
-	return Wallet_Claimed_Field{_set: true, _null: true}
+type IndexedBlock_Timestamp_Field struct {
+	_set   bool
+	_null  bool
+	_value time.Time
 }
 
-func (f Wallet_Claimed_Field) isnull() bool { return !f._set || f._null || f._value == nil }
+func IndexedBlock_Timestamp(v time.Time) IndexedBlock_Timestamp_Field {
+	return IndexedBlock_Timestamp_Field{_set: true, _value: v}
+}
 
-func (f Wallet_Claimed_Field) value() any {
+func (f IndexedBlock_Timestamp_Field) value() any {
 	if !f._set || f._null {
 		return nil
 	}
 	return f._value
 }
 
-type Wallet_Satellite_Field struct {
+type ReportedTransferEvent struct {
+	Satellite    string
+	ChainId      int64
+	BlockHash    []byte
+	BlockNumber  int64
+	Transaction  []byte
+	LogIndex     int
+	TraceIndex   int
+	FromAddress  []byte
+	ToAddress    []byte
+	TokenValue   []byte
+	Contract     []byte
+	Removed      bool
+	RemovedBlock int64
+	CreatedAt    time.Time
+}
+
+func (ReportedTransferEvent) _Table() string { return "reported_transfer_events" }
+
+type ReportedTransferEvent_Update_Fields struct {
+	Removed      ReportedTransferEvent_Removed_Field
+	RemovedBlock ReportedTransferEvent_RemovedBlock_Field
+}
+
+type ReportedTransferEvent_Satellite_Field struct {
 	_set   bool
 	_null  bool
 	_value string
 }
 
-func Wallet_Satellite(v string) Wallet_Satellite_Field {
-	return Wallet_Satellite_Field{_set: true, _value: v}
+func ReportedTransferEvent_Satellite(v string) ReportedTransferEvent_Satellite_Field {
+	return ReportedTransferEvent_Satellite_Field{_set: true, _value: v}
 }
 
-func (f Wallet_Satellite_Field) value() any {
+func (f ReportedTransferEvent_Satellite_Field) value() any {
 	if !f._set || f._null {
 		return nil
 	}
 	return f._value
 }
 
-type Wallet_Info_Field struct {
+type ReportedTransferEvent_ChainId_Field struct {
 	_set   bool
 	_null  bool
-	_value *string
+	_value int64
 }
 
-func Wallet_Info(v string) Wallet_Info_Field {
-	return Wallet_Info_Field{_set: true, _value: &v}
+func ReportedTransferEvent_ChainId(v int64) ReportedTransferEvent_ChainId_Field {
+	return ReportedTransferEvent_ChainId_Field{_set: true, _value: v}
 }
 
-func Wallet_Info_Raw(v *string) Wallet_Info_Field {
-	if v == nil {
-		return Wallet_Info_Null()
+func (f ReportedTransferEvent_ChainId_Field) value() any {
+	if !f._set || f._null {
+		return nil
 	}
-	return Wallet_Info(*v)
+	return f._value
 }
 
-func Wallet_Info_Null() Wallet_Info_Field {
-	return Wallet_Info_Field{_set: true, _null: true}
+type ReportedTransferEvent_BlockHash_Field struct {
+	_set   bool
+	_null  bool
+	_value []byte
 }
 
-func (f Wallet_Info_Field) isnull() bool { return !f._set || f._null || f._value == nil }
+func ReportedTransferEvent_BlockHash(v []byte) ReportedTransferEvent_BlockHash_Field {
+	return ReportedTransferEvent_BlockHash_Field{_set: true, _value: v}
+}
 
-func (f Wallet_Info_Field) value() any {
+func (f ReportedTransferEvent_BlockHash_Field) value() any {
 	if !f._set || f._null {
 		return nil
 	}
 	return f._value
 }
 
-type Wallet_CreatedAt_Field struct {
+type ReportedTransferEvent_BlockNumber_Field struct {
 	_set   bool
 	_null  bool
-	_value time.Time
+	_value int64
 }
 
-func Wallet_CreatedAt(v time.Time) Wallet_CreatedAt_Field {
-	return Wallet_CreatedAt_Field{_set: true, _value: v}
+func ReportedTransferEvent_BlockNumber(v int64) ReportedTransferEvent_BlockNumber_Field {
+	return ReportedTransferEvent_BlockNumber_Field{_set: true, _value: v}
 }
 
-func (f Wallet_CreatedAt_Field) value() any {
+func (f ReportedTransferEvent_BlockNumber_Field) value() any {
 	if !f._set || f._null {
 		return nil
 	}
 	return f._value
 }
 
-func toUTC(t time.Time) time.Time {
-	return t.UTC()
+type ReportedTransferEvent_Transaction_Field struct {
+	_set   bool
+	_null  bool
+	_value []byte
 }
 
-func toDate(t time.Time) time.Time {
-	// keep up the minute portion so that translations between timezones will
-	// continue to reflect properly.
-	return t.Truncate(time.Minute)
+func ReportedTransferEvent_Transaction(v []byte) ReportedTransferEvent_Transaction_Field {
+	return ReportedTransferEvent_Transaction_Field{_set: true, _value: v}
 }
 
-//
-// runtime support for building sql statements
-//
+func (f ReportedTransferEvent_Transaction_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
 
-type __sqlbundle_SQL interface {
-	Render() string
+type ReportedTransferEvent_LogIndex_Field struct {
+	_set   bool
+	_null  bool
+	_value int
+}
 
-	private()
+func ReportedTransferEvent_LogIndex(v int) ReportedTransferEvent_LogIndex_Field {
+	return ReportedTransferEvent_LogIndex_Field{_set: true, _value: v}
 }
 
-type __sqlbundle_Dialect interface {
-	// Rebind gives the opportunity to rewrite provided SQL into a SQL dialect.
-	Rebind(sql string) string
+func (f ReportedTransferEvent_LogIndex_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
 }
 
-type __sqlbundle_RenderOp int
+type ReportedTransferEvent_TraceIndex_Field struct {
+	_set   bool
+	_null  bool
+	_value int
+}
 
-const (
-	__sqlbundle_NoFlatten __sqlbundle_RenderOp = iota
-	__sqlbundle_NoTerminate
-)
+func ReportedTransferEvent_TraceIndex(v int) ReportedTransferEvent_TraceIndex_Field {
+	return ReportedTransferEvent_TraceIndex_Field{_set: true, _value: v}
+}
 
-func __sqlbundle_RenderAll(dialect __sqlbundle_Dialect, sqls []__sqlbundle_SQL, ops ...__sqlbundle_RenderOp) []string {
-	var rs []string
-	for _, sql := range sqls {
-		rs = append(rs, __sqlbundle_Render(dialect, sql, ops...))
+func (f ReportedTransferEvent_TraceIndex_Field) value() any {
+	if !f._set || f._null {
+		return nil
 	}
-	return rs
+	return f._value
 }
 
-func __sqlbundle_Render(dialect __sqlbundle_Dialect, sql __sqlbundle_SQL, ops ...__sqlbundle_RenderOp) string {
-	out := sql.Render()
+type ReportedTransferEvent_FromAddress_Field struct {
+	_set   bool
+	_null  bool
+	_value []byte
+}
 
-	flatten := true
-	terminate := true
-	for _, op := range ops {
-		switch op {
-		case __sqlbundle_NoFlatten:
-			flatten = false
-		case __sqlbundle_NoTerminate:
-			terminate = false
-		}
-	}
+func ReportedTransferEvent_FromAddress(v []byte) ReportedTransferEvent_FromAddress_Field {
+	return ReportedTransferEvent_FromAddress_Field{_set: true, _value: v}
+}
 
-	if flatten {
-		out = __sqlbundle_flattenSQL(out)
-	}
-	if terminate {
-		out += ";"
+func (f ReportedTransferEvent_FromAddress_Field) value() any {
+	if !f._set || f._null {
+		return nil
 	}
+	return f._value
+}
 
-	return dialect.Rebind(out)
+type ReportedTransferEvent_ToAddress_Field struct {
+	_set   bool
+	_null  bool
+	_value []byte
 }
 
-func __sqlbundle_flattenSQL(x string) string {
-	// trim whitespace from beginning and end
+func ReportedTransferEvent_ToAddress(v []byte) ReportedTransferEvent_ToAddress_Field {
+	return ReportedTransferEvent_ToAddress_Field{_set: true, _value: v}
+}
+
+func (f ReportedTransferEvent_ToAddress_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
+
+type ReportedTransferEvent_TokenValue_Field struct {
+	_set   bool
+	_null  bool
+	_value []byte
+}
+
+func ReportedTransferEvent_TokenValue(v []byte) ReportedTransferEvent_TokenValue_Field {
+	return ReportedTransferEvent_TokenValue_Field{_set: true, _value: v}
+}
+
+func (f ReportedTransferEvent_TokenValue_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
+
+type ReportedTransferEvent_Contract_Field struct {
+	_set   bool
+	_null  bool
+	_value []byte
+}
+
+func ReportedTransferEvent_Contract(v []byte) ReportedTransferEvent_Contract_Field {
+	return ReportedTransferEvent_Contract_Field{_set: true, _value: v}
+}
+
+func (f ReportedTransferEvent_Contract_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
+
+type ReportedTransferEvent_Removed_Field struct {
+	_set   bool
+	_null  bool
+	_value bool
+}
+
+func ReportedTransferEvent_Removed(v bool) ReportedTransferEvent_Removed_Field {
+	return ReportedTransferEvent_Removed_Field{_set: true, _value: v}
+}
+
+func (f ReportedTransferEvent_Removed_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
+
+type ReportedTransferEvent_RemovedBlock_Field struct {
+	_set   bool
+	_null  bool
+	_value int64
+}
+
+func ReportedTransferEvent_RemovedBlock(v int64) ReportedTransferEvent_RemovedBlock_Field {
+	return ReportedTransferEvent_RemovedBlock_Field{_set: true, _value: v}
+}
+
+func (f ReportedTransferEvent_RemovedBlock_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
+
+type ReportedTransferEvent_CreatedAt_Field struct {
+	_set   bool
+	_null  bool
+	_value time.Time
+}
+
+func ReportedTransferEvent_CreatedAt(v time.Time) ReportedTransferEvent_CreatedAt_Field {
+	return ReportedTransferEvent_CreatedAt_Field{_set: true, _value: v}
+}
+
+func (f ReportedTransferEvent_CreatedAt_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
+
+type ScanWatermark struct {
+	Satellite   string
+	ChainId     int64
+	BlockNumber int64
+}
+
+func (ScanWatermark) _Table() string { return "scan_watermarks" }
+
+type ScanWatermark_Update_Fields struct {
+}
+
+type ScanWatermark_Satellite_Field struct {
+	_set   bool
+	_null  bool
+	_value string
+}
+
+func ScanWatermark_Satellite(v string) ScanWatermark_Satellite_Field {
+	return ScanWatermark_Satellite_Field{_set: true, _value: v}
+}
+
+func (f ScanWatermark_Satellite_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
+
+type ScanWatermark_ChainId_Field struct {
+	_set   bool
+	_null  bool
+	_value int64
+}
+
+func ScanWatermark_ChainId(v int64) ScanWatermark_ChainId_Field {
+	return ScanWatermark_ChainId_Field{_set: true, _value: v}
+}
+
+func (f ScanWatermark_ChainId_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
+
+type ScanWatermark_BlockNumber_Field struct {
+	_set   bool
+	_null  bool
+	_value int64
+}
+
+func ScanWatermark_BlockNumber(v int64) ScanWatermark_BlockNumber_Field {
+	return ScanWatermark_BlockNumber_Field{_set: true, _value: v}
+}
+
+func (f ScanWatermark_BlockNumber_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
+
+type TokenPrice struct {
+	IntervalStart time.Time
+	Price         int64
+}
+
+func (TokenPrice) _Table() string { return "token_prices" }
+
+type TokenPrice_Update_Fields struct {
+	Price TokenPrice_Price_Field
+}
+
+type TokenPrice_IntervalStart_Field struct {
+	_set   bool
+	_null  bool
+	_value time.Time
+}
+
+func TokenPrice_IntervalStart(v time.Time) TokenPrice_IntervalStart_Field {
+	return TokenPrice_IntervalStart_Field{_set: true, _value: v}
+}
+
+func (f TokenPrice_IntervalStart_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
+
+type TokenPrice_Price_Field struct {
+	_set   bool
+	_null  bool
+	_value int64
+}
+
+func TokenPrice_Price(v int64) TokenPrice_Price_Field {
+	return TokenPrice_Price_Field{_set: true, _value: v}
+}
+
+func (f TokenPrice_Price_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
+
+type TransferEvent struct {
+	ChainId     int64
+	BlockHash   []byte
+	BlockNumber int64
+	Transaction []byte
+	LogIndex    int
+	TraceIndex  int
+	FromAddress []byte
+	ToAddress   []byte
+	TokenValue  []byte
+	Contract    []byte
+	CreatedAt   time.Time
+}
+
+func (TransferEvent) _Table() string { return "transfer_events" }
+
+type TransferEvent_Update_Fields struct {
+}
+
+type TransferEvent_ChainId_Field struct {
+	_set   bool
+	_null  bool
+	_value int64
+}
+
+func TransferEvent_ChainId(v int64) TransferEvent_ChainId_Field {
+	return TransferEvent_ChainId_Field{_set: true, _value: v}
+}
+
+func (f TransferEvent_ChainId_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
+
+type TransferEvent_BlockHash_Field struct {
+	_set   bool
+	_null  bool
+	_value []byte
+}
+
+func TransferEvent_BlockHash(v []byte) TransferEvent_BlockHash_Field {
+	return TransferEvent_BlockHash_Field{_set: true, _value: v}
+}
+
+func (f TransferEvent_BlockHash_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
+
+type TransferEvent_BlockNumber_Field struct {
+	_set   bool
+	_null  bool
+	_value int64
+}
+
+func TransferEvent_BlockNumber(v int64) TransferEvent_BlockNumber_Field {
+	return TransferEvent_BlockNumber_Field{_set: true, _value: v}
+}
+
+func (f TransferEvent_BlockNumber_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
+
+type TransferEvent_Transaction_Field struct {
+	_set   bool
+	_null  bool
+	_value []byte
+}
+
+func TransferEvent_Transaction(v []byte) TransferEvent_Transaction_Field {
+	return TransferEvent_Transaction_Field{_set: true, _value: v}
+}
+
+func (f TransferEvent_Transaction_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
+
+type TransferEvent_LogIndex_Field struct {
+	_set   bool
+	_null  bool
+	_value int
+}
+
+func TransferEvent_LogIndex(v int) TransferEvent_LogIndex_Field {
+	return TransferEvent_LogIndex_Field{_set: true, _value: v}
+}
+
+func (f TransferEvent_LogIndex_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
+
+type TransferEvent_TraceIndex_Field struct {
+	_set   bool
+	_null  bool
+	_value int
+}
+
+func TransferEvent_TraceIndex(v int) TransferEvent_TraceIndex_Field {
+	return TransferEvent_TraceIndex_Field{_set: true, _value: v}
+}
+
+func (f TransferEvent_TraceIndex_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
+
+type TransferEvent_FromAddress_Field struct {
+	_set   bool
+	_null  bool
+	_value []byte
+}
+
+func TransferEvent_FromAddress(v []byte) TransferEvent_FromAddress_Field {
+	return TransferEvent_FromAddress_Field{_set: true, _value: v}
+}
+
+func (f TransferEvent_FromAddress_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
+
+type TransferEvent_ToAddress_Field struct {
+	_set   bool
+	_null  bool
+	_value []byte
+}
+
+func TransferEvent_ToAddress(v []byte) TransferEvent_ToAddress_Field {
+	return TransferEvent_ToAddress_Field{_set: true, _value: v}
+}
+
+func (f TransferEvent_ToAddress_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
+
+type TransferEvent_TokenValue_Field struct {
+	_set   bool
+	_null  bool
+	_value []byte
+}
+
+func TransferEvent_TokenValue(v []byte) TransferEvent_TokenValue_Field {
+	return TransferEvent_TokenValue_Field{_set: true, _value: v}
+}
+
+func (f TransferEvent_TokenValue_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
+
+type TransferEvent_Contract_Field struct {
+	_set   bool
+	_null  bool
+	_value []byte
+}
+
+func TransferEvent_Contract(v []byte) TransferEvent_Contract_Field {
+	return TransferEvent_Contract_Field{_set: true, _value: v}
+}
+
+func (f TransferEvent_Contract_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
+
+type TransferEvent_CreatedAt_Field struct {
+	_set   bool
+	_null  bool
+	_value time.Time
+}
+
+func TransferEvent_CreatedAt(v time.Time) TransferEvent_CreatedAt_Field {
+	return TransferEvent_CreatedAt_Field{_set: true, _value: v}
+}
+
+func (f TransferEvent_CreatedAt_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
+
+type Wallet struct {
+	Id        int64
+	Address   []byte
+	Claimed   *time.Time
+	Satellite string
+	Info      *string
+	CreatedAt time.Time
+}
+
+func (Wallet) _Table() string { return "wallets" }
+
+type Wallet_Create_Fields struct {
+	Claimed Wallet_Claimed_Field
+	Info    Wallet_Info_Field
+}
+
+type Wallet_Update_Fields struct {
+	Claimed Wallet_Claimed_Field
+	Info    Wallet_Info_Field
+}
+
+type Wallet_Id_Field struct {
+	_set   bool
+	_null  bool
+	_value int64
+}
+
+func Wallet_Id(v int64) Wallet_Id_Field {
+	return Wallet_Id_Field{_set: true, _value: v}
+}
+
+func (f Wallet_Id_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
+
+type Wallet_Address_Field struct {
+	_set   bool
+	_null  bool
+	_value []byte
+}
+
+func Wallet_Address(v []byte) Wallet_Address_Field {
+	return Wallet_Address_Field{_set: true, _value: v}
+}
+
+func (f Wallet_Address_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
+
+type Wallet_Claimed_Field struct {
+	_set   bool
+	_null  bool
+	_value *time.Time
+}
+
+func Wallet_Claimed(v time.Time) Wallet_Claimed_Field {
+	return Wallet_Claimed_Field{_set: true, _value: &v}
+}
+
+func Wallet_Claimed_Raw(v *time.Time) Wallet_Claimed_Field {
+	if v == nil {
+		return Wallet_Claimed_Null()
+	}
+	return Wallet_Claimed(*v)
+}
+
+func Wallet_Claimed_Null() Wallet_Claimed_Field {
+	return Wallet_Claimed_Field{_set: true, _null: true}
+}
+
+func (f Wallet_Claimed_Field) isnull() bool { return !f._set || f._null || f._value == nil }
+
+func (f Wallet_Claimed_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
+
+type Wallet_Satellite_Field struct {
+	_set   bool
+	_null  bool
+	_value string
+}
+
+func Wallet_Satellite(v string) Wallet_Satellite_Field {
+	return Wallet_Satellite_Field{_set: true, _value: v}
+}
+
+func (f Wallet_Satellite_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
+
+type Wallet_Info_Field struct {
+	_set   bool
+	_null  bool
+	_value *string
+}
+
+func Wallet_Info(v string) Wallet_Info_Field {
+	return Wallet_Info_Field{_set: true, _value: &v}
+}
+
+func Wallet_Info_Raw(v *string) Wallet_Info_Field {
+	if v == nil {
+		return Wallet_Info_Null()
+	}
+	return Wallet_Info(*v)
+}
+
+func Wallet_Info_Null() Wallet_Info_Field {
+	return Wallet_Info_Field{_set: true, _null: true}
+}
+
+func (f Wallet_Info_Field) isnull() bool { return !f._set || f._null || f._value == nil }
+
+func (f Wallet_Info_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
+
+type Wallet_CreatedAt_Field struct {
+	_set   bool
+	_null  bool
+	_value time.Time
+}
+
+func Wallet_CreatedAt(v time.Time) Wallet_CreatedAt_Field {
+	return Wallet_CreatedAt_Field{_set: true, _value: v}
+}
+
+func (f Wallet_CreatedAt_Field) value() any {
+	if !f._set || f._null {
+		return nil
+	}
+	return f._value
+}
+
+func toUTC(t time.Time) time.Time {
+	return t.UTC()
+}
+
+func toDate(t time.Time) time.Time {
+	// keep up the minute portion so that translations between timezones will
+	// continue to reflect properly.
+	return t.Truncate(time.Minute)
+}
+
+//
+// runtime support for building sql statements
+//
+
+type __sqlbundle_SQL interface {
+	Render() string
+
+	private()
+}
+
+type __sqlbundle_Dialect interface {
+	// Rebind gives the opportunity to rewrite provided SQL into a SQL dialect.
+	Rebind(sql string) string
+}
+
+type __sqlbundle_RenderOp int
+
+const (
+	__sqlbundle_NoFlatten __sqlbundle_RenderOp = iota
+	__sqlbundle_NoTerminate
+)
+
+func __sqlbundle_RenderAll(dialect __sqlbundle_Dialect, sqls []__sqlbundle_SQL, ops ...__sqlbundle_RenderOp) []string {
+	var rs []string
+	for _, sql := range sqls {
+		rs = append(rs, __sqlbundle_Render(dialect, sql, ops...))
+	}
+	return rs
+}
+
+func __sqlbundle_Render(dialect __sqlbundle_Dialect, sql __sqlbundle_SQL, ops ...__sqlbundle_RenderOp) string {
+	out := sql.Render()
+
+	flatten := true
+	terminate := true
+	for _, op := range ops {
+		switch op {
+		case __sqlbundle_NoFlatten:
+			flatten = false
+		case __sqlbundle_NoTerminate:
+			terminate = false
+		}
+	}
+
+	if flatten {
+		out = __sqlbundle_flattenSQL(out)
+	}
+	if terminate {
+		out += ";"
+	}
+
+	return dialect.Rebind(out)
+}
+
+func __sqlbundle_flattenSQL(x string) string {
+	// trim whitespace from beginning and end
 	s, e := 0, len(x)-1
 	for s < len(x) && (x[s] == ' ' || x[s] == '\t' || x[s] == '\n') {
 		s++
@@ -1180,27 +1995,29 @@ func (obj *pgxImpl) Create_BlockHeader(ctx context.Context,
 	block_header_chain_id BlockHeader_ChainId_Field,
 	block_header_hash BlockHeader_Hash_Field,
 	block_header_number BlockHeader_Number_Field,
-	block_header_timestamp BlockHeader_Timestamp_Field) (
+	block_header_timestamp BlockHeader_Timestamp_Field,
+	block_header_parent_hash BlockHeader_ParentHash_Field) (
 	block_header *BlockHeader, err error) {
 	__chain_id_val := block_header_chain_id.value()
 	__hash_val := block_header_hash.value()
 	__number_val := block_header_number.value()
 	__timestamp_val := block_header_timestamp.value()
+	__parent_hash_val := block_header_parent_hash.value()
 
-	var __columns = &__sqlbundle_Hole{SQL: __sqlbundle_Literal("chain_id, hash, number, timestamp")}
-	var __placeholders = &__sqlbundle_Hole{SQL: __sqlbundle_Literal("?, ?, ?, ?")}
+	var __columns = &__sqlbundle_Hole{SQL: __sqlbundle_Literal("chain_id, hash, number, timestamp, parent_hash")}
+	var __placeholders = &__sqlbundle_Hole{SQL: __sqlbundle_Literal("?, ?, ?, ?, ?")}
 	var __clause = &__sqlbundle_Hole{SQL: __sqlbundle_Literals{Join: "", SQLs: []__sqlbundle_SQL{__sqlbundle_Literal("("), __columns, __sqlbundle_Literal(") VALUES ("), __placeholders, __sqlbundle_Literal(")")}}}
 
-	var __embed_stmt = __sqlbundle_Literals{Join: "", SQLs: []__sqlbundle_SQL{__sqlbundle_Literal("INSERT INTO block_headers "), __clause, __sqlbundle_Literal(" RETURNING block_headers.chain_id, block_headers.hash, block_headers.number, block_headers.timestamp, block_headers.created_at")}}
+	var __embed_stmt = __sqlbundle_Literals{Join: "", SQLs: []__sqlbundle_SQL{__sqlbundle_Literal("INSERT INTO block_headers "), __clause, __sqlbundle_Literal(" RETURNING block_headers.chain_id, block_headers.hash, block_headers.number, block_headers.timestamp, block_headers.parent_hash, block_headers.created_at")}}
 
 	var __values []any
-	__values = append(__values, __chain_id_val, __hash_val, __number_val, __timestamp_val)
+	__values = append(__values, __chain_id_val, __hash_val, __number_val, __timestamp_val, __parent_hash_val)
 
 	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
 	obj.logStmt(__stmt, __values...)
 
 	block_header = &BlockHeader{}
-	err = obj.queryRowContext(ctx, __stmt, __values...).Scan(&block_header.ChainId, &block_header.Hash, &block_header.Number, &block_header.Timestamp, &block_header.CreatedAt)
+	err = obj.queryRowContext(ctx, __stmt, __values...).Scan(&block_header.ChainId, &block_header.Hash, &block_header.Number, &block_header.Timestamp, &block_header.ParentHash, &block_header.CreatedAt)
 	if err != nil {
 		return nil, obj.makeErr(err)
 	}
@@ -1262,33 +2079,196 @@ func (obj *pgxImpl) Create_Wallet(ctx context.Context,
 
 }
 
-func (obj *pgxImpl) All_BlockHeader_OrderBy_Desc_Timestamp(ctx context.Context) (
-	rows []*BlockHeader, err error) {
+func (obj *pgxImpl) ReplaceNoReturn_TransferEvent(ctx context.Context,
+	transfer_event_chain_id TransferEvent_ChainId_Field,
+	transfer_event_block_hash TransferEvent_BlockHash_Field,
+	transfer_event_block_number TransferEvent_BlockNumber_Field,
+	transfer_event_transaction TransferEvent_Transaction_Field,
+	transfer_event_log_index TransferEvent_LogIndex_Field,
+	transfer_event_trace_index TransferEvent_TraceIndex_Field,
+	transfer_event_from_address TransferEvent_FromAddress_Field,
+	transfer_event_to_address TransferEvent_ToAddress_Field,
+	transfer_event_token_value TransferEvent_TokenValue_Field,
+	transfer_event_contract TransferEvent_Contract_Field) (
+	err error) {
+	__chain_id_val := transfer_event_chain_id.value()
+	__block_hash_val := transfer_event_block_hash.value()
+	__block_number_val := transfer_event_block_number.value()
+	__transaction_val := transfer_event_transaction.value()
+	__log_index_val := transfer_event_log_index.value()
+	__trace_index_val := transfer_event_trace_index.value()
+	__from_address_val := transfer_event_from_address.value()
+	__to_address_val := transfer_event_to_address.value()
+	__token_value_val := transfer_event_token_value.value()
+	__contract_val := transfer_event_contract.value()
+
+	var __embed_stmt = __sqlbundle_Literal("INSERT INTO transfer_events ( chain_id, block_hash, block_number, transaction, log_index, trace_index, from_address, to_address, token_value, contract ) VALUES ( ?, ?, ?, ?, ?, ?, ?, ?, ?, ? ) ON CONFLICT ( chain_id, block_hash, log_index, trace_index ) DO UPDATE SET chain_id = EXCLUDED.chain_id, block_hash = EXCLUDED.block_hash, block_number = EXCLUDED.block_number, transaction = EXCLUDED.transaction, log_index = EXCLUDED.log_index, trace_index = EXCLUDED.trace_index, from_address = EXCLUDED.from_address, to_address = EXCLUDED.to_address, token_value = EXCLUDED.token_value, contract = EXCLUDED.contract")
+
+	var __values []any
+	__values = append(__values, __chain_id_val, __block_hash_val, __block_number_val, __transaction_val, __log_index_val, __trace_index_val, __from_address_val, __to_address_val, __token_value_val, __contract_val)
+
+	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
+	obj.logStmt(__stmt, __values...)
+
+	_, err = obj.driver.ExecContext(ctx, __stmt, __values...)
+	if err != nil {
+		return obj.makeErr(err)
+	}
+	return nil
+
+}
+
+func (obj *pgxImpl) ReplaceNoReturn_IndexedBlock(ctx context.Context,
+	indexed_block_chain_id IndexedBlock_ChainId_Field,
+	indexed_block_hash IndexedBlock_Hash_Field,
+	indexed_block_number IndexedBlock_Number_Field,
+	indexed_block_timestamp IndexedBlock_Timestamp_Field) (
+	err error) {
+	__chain_id_val := indexed_block_chain_id.value()
+	__hash_val := indexed_block_hash.value()
+	__number_val := indexed_block_number.value()
+	__timestamp_val := indexed_block_timestamp.value()
+
+	var __embed_stmt = __sqlbundle_Literal("INSERT INTO indexed_blocks ( chain_id, hash, number, timestamp ) VALUES ( ?, ?, ?, ? ) ON CONFLICT ( chain_id ) DO UPDATE SET chain_id = EXCLUDED.chain_id, hash = EXCLUDED.hash, number = EXCLUDED.number, timestamp = EXCLUDED.timestamp")
+
+	var __values []any
+	__values = append(__values, __chain_id_val, __hash_val, __number_val, __timestamp_val)
+
+	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
+	obj.logStmt(__stmt, __values...)
+
+	_, err = obj.driver.ExecContext(ctx, __stmt, __values...)
+	if err != nil {
+		return obj.makeErr(err)
+	}
+	return nil
+
+}
+
+func (obj *pgxImpl) ReplaceNoReturn_ScanWatermark(ctx context.Context,
+	scan_watermark_satellite ScanWatermark_Satellite_Field,
+	scan_watermark_chain_id ScanWatermark_ChainId_Field,
+	scan_watermark_block_number ScanWatermark_BlockNumber_Field) (
+	err error) {
+	__satellite_val := scan_watermark_satellite.value()
+	__chain_id_val := scan_watermark_chain_id.value()
+	__block_number_val := scan_watermark_block_number.value()
+
+	var __embed_stmt = __sqlbundle_Literal("INSERT INTO scan_watermarks ( satellite, chain_id, block_number ) VALUES ( ?, ?, ? ) ON CONFLICT ( satellite, chain_id ) DO UPDATE SET satellite = EXCLUDED.satellite, chain_id = EXCLUDED.chain_id, block_number = EXCLUDED.block_number")
+
+	var __values []any
+	__values = append(__values, __satellite_val, __chain_id_val, __block_number_val)
+
+	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
+	obj.logStmt(__stmt, __values...)
+
+	_, err = obj.driver.ExecContext(ctx, __stmt, __values...)
+	if err != nil {
+		return obj.makeErr(err)
+	}
+	return nil
+
+}
+
+func (obj *pgxImpl) ReplaceNoReturn_ReportedTransferEvent(ctx context.Context,
+	reported_transfer_event_satellite ReportedTransferEvent_Satellite_Field,
+	reported_transfer_event_chain_id ReportedTransferEvent_ChainId_Field,
+	reported_transfer_event_block_hash ReportedTransferEvent_BlockHash_Field,
+	reported_transfer_event_block_number ReportedTransferEvent_BlockNumber_Field,
+	reported_transfer_event_transaction ReportedTransferEvent_Transaction_Field,
+	reported_transfer_event_log_index ReportedTransferEvent_LogIndex_Field,
+	reported_transfer_event_trace_index ReportedTransferEvent_TraceIndex_Field,
+	reported_transfer_event_from_address ReportedTransferEvent_FromAddress_Field,
+	reported_transfer_event_to_address ReportedTransferEvent_ToAddress_Field,
+	reported_transfer_event_token_value ReportedTransferEvent_TokenValue_Field,
+	reported_transfer_event_contract ReportedTransferEvent_Contract_Field,
+	reported_transfer_event_removed ReportedTransferEvent_Removed_Field,
+	reported_transfer_event_removed_block ReportedTransferEvent_RemovedBlock_Field) (
+	err error) {
+	__satellite_val := reported_transfer_event_satellite.value()
+	__chain_id_val := reported_transfer_event_chain_id.value()
+	__block_hash_val := reported_transfer_event_block_hash.value()
+	__block_number_val := reported_transfer_event_block_number.value()
+	__transaction_val := reported_transfer_event_transaction.value()
+	__log_index_val := reported_transfer_event_log_index.value()
+	__trace_index_val := reported_transfer_event_trace_index.value()
+	__from_address_val := reported_transfer_event_from_address.value()
+	__to_address_val := reported_transfer_event_to_address.value()
+	__token_value_val := reported_transfer_event_token_value.value()
+	__contract_val := reported_transfer_event_contract.value()
+	__removed_val := reported_transfer_event_removed.value()
+	__removed_block_val := reported_transfer_event_removed_block.value()
+
+	var __embed_stmt = __sqlbundle_Literal("INSERT INTO reported_transfer_events ( satellite, chain_id, block_hash, block_number, transaction, log_index, trace_index, from_address, to_address, token_value, contract, removed, removed_block ) VALUES ( ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ? ) ON CONFLICT ( satellite, chain_id, block_hash, log_index, trace_index ) DO UPDATE SET satellite = EXCLUDED.satellite, chain_id = EXCLUDED.chain_id, block_hash = EXCLUDED.block_hash, block_number = EXCLUDED.block_number, transaction = EXCLUDED.transaction, log_index = EXCLUDED.log_index, trace_index = EXCLUDED.trace_index, from_address = EXCLUDED.from_address, to_address = EXCLUDED.to_address, token_value = EXCLUDED.token_value, contract = EXCLUDED.contract, removed = EXCLUDED.removed, removed_block = EXCLUDED.removed_block")
+
+	var __values []any
+	__values = append(__values, __satellite_val, __chain_id_val, __block_hash_val, __block_number_val, __transaction_val, __log_index_val, __trace_index_val, __from_address_val, __to_address_val, __token_value_val, __contract_val, __removed_val, __removed_block_val)
+
+	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
+	obj.logStmt(__stmt, __values...)
+
+	_, err = obj.driver.ExecContext(ctx, __stmt, __values...)
+	if err != nil {
+		return obj.makeErr(err)
+	}
+	return nil
+
+}
+
+func (obj *pgxImpl) ReplaceNoReturn_EthPrice(ctx context.Context,
+	eth_price_interval_start EthPrice_IntervalStart_Field,
+	eth_price_price EthPrice_Price_Field) (
+	err error) {
+	__interval_start_val := eth_price_interval_start.value()
+	__price_val := eth_price_price.value()
+
+	var __embed_stmt = __sqlbundle_Literal("INSERT INTO eth_prices ( interval_start, price ) VALUES ( ?, ? ) ON CONFLICT ( interval_start ) DO UPDATE SET interval_start = EXCLUDED.interval_start, price = EXCLUDED.price")
+
+	var __values []any
+	__values = append(__values, __interval_start_val, __price_val)
+
+	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
+	obj.logStmt(__stmt, __values...)
+
+	_, err = obj.driver.ExecContext(ctx, __stmt, __values...)
+	if err != nil {
+		return obj.makeErr(err)
+	}
+	return nil
+
+}
+
+func (obj *pgxImpl) First_BlockHeader_By_ChainId_OrderBy_Desc_Number(ctx context.Context,
+	block_header_chain_id BlockHeader_ChainId_Field) (
+	block_header *BlockHeader, err error) {
 
-	var __embed_stmt = __sqlbundle_Literal("SELECT block_headers.chain_id, block_headers.hash, block_headers.number, block_headers.timestamp, block_headers.created_at FROM block_headers ORDER BY block_headers.timestamp DESC")
+	var __embed_stmt = __sqlbundle_Literal("SELECT block_headers.chain_id, block_headers.hash, block_headers.number, block_headers.timestamp, block_headers.parent_hash, block_headers.created_at FROM block_headers WHERE block_headers.chain_id = ? ORDER BY block_headers.number DESC LIMIT 1 OFFSET 0")
 
 	var __values []any
+	__values = append(__values, block_header_chain_id.value())
 
 	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
 	obj.logStmt(__stmt, __values...)
 
 	for {
-		rows, err = func() (rows []*BlockHeader, err error) {
+		block_header, err = func() (block_header *BlockHeader, err error) {
 			__rows, err := obj.driver.QueryContext(ctx, __stmt, __values...)
 			if err != nil {
 				return nil, err
 			}
 			defer closeRows(__rows, &err)
 
-			for __rows.Next() {
-				block_header := &BlockHeader{}
-				err = __rows.Scan(&block_header.ChainId, &block_header.Hash, &block_header.Number, &block_header.Timestamp, &block_header.CreatedAt)
-				if err != nil {
-					return nil, err
-				}
-				rows = append(rows, block_header)
+			if !__rows.Next() {
+				return nil, nil
 			}
-			return rows, nil
+
+			block_header = &BlockHeader{}
+			err = __rows.Scan(&block_header.ChainId, &block_header.Hash, &block_header.Number, &block_header.Timestamp, &block_header.ParentHash, &block_header.CreatedAt)
+			if err != nil {
+				return nil, err
+			}
+
+			return block_header, nil
 		}()
 		if err != nil {
 			if obj.shouldRetry(err) {
@@ -1296,7 +2276,7 @@ func (obj *pgxImpl) All_BlockHeader_OrderBy_Desc_Timestamp(ctx context.Context)
 			}
 			return nil, obj.makeErr(err)
 		}
-		return rows, nil
+		return block_header, nil
 	}
 
 }
@@ -1306,7 +2286,7 @@ func (obj *pgxImpl) Get_BlockHeader_By_ChainId_And_Hash(ctx context.Context,
 	block_header_hash BlockHeader_Hash_Field) (
 	block_header *BlockHeader, err error) {
 
-	var __embed_stmt = __sqlbundle_Literal("SELECT block_headers.chain_id, block_headers.hash, block_headers.number, block_headers.timestamp, block_headers.created_at FROM block_headers WHERE block_headers.chain_id = ? AND block_headers.hash = ?")
+	var __embed_stmt = __sqlbundle_Literal("SELECT block_headers.chain_id, block_headers.hash, block_headers.number, block_headers.timestamp, block_headers.parent_hash, block_headers.created_at FROM block_headers WHERE block_headers.chain_id = ? AND block_headers.hash = ?")
 
 	var __values []any
 	__values = append(__values, block_header_chain_id.value(), block_header_hash.value())
@@ -1315,7 +2295,7 @@ func (obj *pgxImpl) Get_BlockHeader_By_ChainId_And_Hash(ctx context.Context,
 	obj.logStmt(__stmt, __values...)
 
 	block_header = &BlockHeader{}
-	err = obj.queryRowContext(ctx, __stmt, __values...).Scan(&block_header.ChainId, &block_header.Hash, &block_header.Number, &block_header.Timestamp, &block_header.CreatedAt)
+	err = obj.queryRowContext(ctx, __stmt, __values...).Scan(&block_header.ChainId, &block_header.Hash, &block_header.Number, &block_header.Timestamp, &block_header.ParentHash, &block_header.CreatedAt)
 	if err != nil {
 		return (*BlockHeader)(nil), obj.makeErr(err)
 	}
@@ -1328,7 +2308,7 @@ func (obj *pgxImpl) Get_BlockHeader_By_ChainId_And_Number(ctx context.Context,
 	block_header_number BlockHeader_Number_Field) (
 	block_header *BlockHeader, err error) {
 
-	var __embed_stmt = __sqlbundle_Literal("SELECT block_headers.chain_id, block_headers.hash, block_headers.number, block_headers.timestamp, block_headers.created_at FROM block_headers WHERE block_headers.chain_id = ? AND block_headers.number = ? LIMIT 2")
+	var __embed_stmt = __sqlbundle_Literal("SELECT block_headers.chain_id, block_headers.hash, block_headers.number, block_headers.timestamp, block_headers.parent_hash, block_headers.created_at FROM block_headers WHERE block_headers.chain_id = ? AND block_headers.number = ? LIMIT 2")
 
 	var __values []any
 	__values = append(__values, block_header_chain_id.value(), block_header_number.value())
@@ -1349,7 +2329,7 @@ func (obj *pgxImpl) Get_BlockHeader_By_ChainId_And_Number(ctx context.Context,
 			}
 
 			block_header = &BlockHeader{}
-			err = __rows.Scan(&block_header.ChainId, &block_header.Hash, &block_header.Number, &block_header.Timestamp, &block_header.CreatedAt)
+			err = __rows.Scan(&block_header.ChainId, &block_header.Hash, &block_header.Number, &block_header.Timestamp, &block_header.ParentHash, &block_header.CreatedAt)
 			if err != nil {
 				return nil, err
 			}
@@ -1532,75 +2512,222 @@ func (obj *pgxImpl) First_Wallet_By_Claimed_Is_Null_And_Satellite(ctx context.Co
 
 }
 
-func (obj *pgxImpl) Count_Wallet_Address(ctx context.Context) (
-	count int64, err error) {
+func (obj *pgxImpl) Count_Wallet_Address(ctx context.Context) (
+	count int64, err error) {
+
+	var __embed_stmt = __sqlbundle_Literal("SELECT COUNT(*) FROM wallets")
+
+	var __values []any
+
+	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
+	obj.logStmt(__stmt, __values...)
+
+	err = obj.queryRowContext(ctx, __stmt, __values...).Scan(&count)
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+
+	return count, nil
+
+}
+
+func (obj *pgxImpl) Count_Wallet_By_Claimed_IsNot_Null(ctx context.Context) (
+	count int64, err error) {
+
+	var __embed_stmt = __sqlbundle_Literal("SELECT COUNT(*) FROM wallets WHERE wallets.claimed is not NULL")
+
+	var __values []any
+
+	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
+	obj.logStmt(__stmt, __values...)
+
+	err = obj.queryRowContext(ctx, __stmt, __values...).Scan(&count)
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+
+	return count, nil
+
+}
+
+func (obj *pgxImpl) Count_Wallet_By_Claimed_Is_Null(ctx context.Context) (
+	count int64, err error) {
+
+	var __embed_stmt = __sqlbundle_Literal("SELECT COUNT(*) FROM wallets WHERE wallets.claimed is NULL")
+
+	var __values []any
+
+	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
+	obj.logStmt(__stmt, __values...)
+
+	err = obj.queryRowContext(ctx, __stmt, __values...).Scan(&count)
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+
+	return count, nil
+
+}
+
+func (obj *pgxImpl) All_Wallet_By_Claimed_IsNot_Null(ctx context.Context) (
+	rows []*Wallet, err error) {
+
+	var __embed_stmt = __sqlbundle_Literal("SELECT wallets.id, wallets.address, wallets.claimed, wallets.satellite, wallets.info, wallets.created_at FROM wallets WHERE wallets.claimed is not NULL")
+
+	var __values []any
+
+	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
+	obj.logStmt(__stmt, __values...)
+
+	for {
+		rows, err = func() (rows []*Wallet, err error) {
+			__rows, err := obj.driver.QueryContext(ctx, __stmt, __values...)
+			if err != nil {
+				return nil, err
+			}
+			defer closeRows(__rows, &err)
+
+			for __rows.Next() {
+				wallet := &Wallet{}
+				err = __rows.Scan(&wallet.Id, &wallet.Address, &wallet.Claimed, &wallet.Satellite, &wallet.Info, &wallet.CreatedAt)
+				if err != nil {
+					return nil, err
+				}
+				rows = append(rows, wallet)
+			}
+			return rows, nil
+		}()
+		if err != nil {
+			if obj.shouldRetry(err) {
+				continue
+			}
+			return nil, obj.makeErr(err)
+		}
+		return rows, nil
+	}
+
+}
+
+func (obj *pgxImpl) All_Wallet_By_Satellite_And_Claimed_IsNot_Null(ctx context.Context,
+	wallet_satellite Wallet_Satellite_Field) (
+	rows []*Wallet, err error) {
+
+	var __embed_stmt = __sqlbundle_Literal("SELECT wallets.id, wallets.address, wallets.claimed, wallets.satellite, wallets.info, wallets.created_at FROM wallets WHERE wallets.satellite = ? AND wallets.claimed is not NULL")
+
+	var __values []any
+	__values = append(__values, wallet_satellite.value())
+
+	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
+	obj.logStmt(__stmt, __values...)
+
+	for {
+		rows, err = func() (rows []*Wallet, err error) {
+			__rows, err := obj.driver.QueryContext(ctx, __stmt, __values...)
+			if err != nil {
+				return nil, err
+			}
+			defer closeRows(__rows, &err)
+
+			for __rows.Next() {
+				wallet := &Wallet{}
+				err = __rows.Scan(&wallet.Id, &wallet.Address, &wallet.Claimed, &wallet.Satellite, &wallet.Info, &wallet.CreatedAt)
+				if err != nil {
+					return nil, err
+				}
+				rows = append(rows, wallet)
+			}
+			return rows, nil
+		}()
+		if err != nil {
+			if obj.shouldRetry(err) {
+				continue
+			}
+			return nil, obj.makeErr(err)
+		}
+		return rows, nil
+	}
+
+}
+
+func (obj *pgxImpl) Get_IndexedBlock_By_ChainId(ctx context.Context,
+	indexed_block_chain_id IndexedBlock_ChainId_Field) (
+	indexed_block *IndexedBlock, err error) {
 
-	var __embed_stmt = __sqlbundle_Literal("SELECT COUNT(*) FROM wallets")
+	var __embed_stmt = __sqlbundle_Literal("SELECT indexed_blocks.chain_id, indexed_blocks.hash, indexed_blocks.number, indexed_blocks.timestamp FROM indexed_blocks WHERE indexed_blocks.chain_id = ?")
 
 	var __values []any
+	__values = append(__values, indexed_block_chain_id.value())
 
 	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
 	obj.logStmt(__stmt, __values...)
 
-	err = obj.queryRowContext(ctx, __stmt, __values...).Scan(&count)
+	indexed_block = &IndexedBlock{}
+	err = obj.queryRowContext(ctx, __stmt, __values...).Scan(&indexed_block.ChainId, &indexed_block.Hash, &indexed_block.Number, &indexed_block.Timestamp)
 	if err != nil {
-		return 0, obj.makeErr(err)
+		return (*IndexedBlock)(nil), obj.makeErr(err)
 	}
-
-	return count, nil
+	return indexed_block, nil
 
 }
 
-func (obj *pgxImpl) Count_Wallet_By_Claimed_IsNot_Null(ctx context.Context) (
-	count int64, err error) {
+func (obj *pgxImpl) All_ScanWatermark_By_Satellite(ctx context.Context,
+	scan_watermark_satellite ScanWatermark_Satellite_Field) (
+	rows []*ScanWatermark, err error) {
 
-	var __embed_stmt = __sqlbundle_Literal("SELECT COUNT(*) FROM wallets WHERE wallets.claimed is not NULL")
+	var __embed_stmt = __sqlbundle_Literal("SELECT scan_watermarks.satellite, scan_watermarks.chain_id, scan_watermarks.block_number FROM scan_watermarks WHERE scan_watermarks.satellite = ?")
 
 	var __values []any
+	__values = append(__values, scan_watermark_satellite.value())
 
 	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
 	obj.logStmt(__stmt, __values...)
 
-	err = obj.queryRowContext(ctx, __stmt, __values...).Scan(&count)
-	if err != nil {
-		return 0, obj.makeErr(err)
-	}
-
-	return count, nil
-
-}
-
-func (obj *pgxImpl) Count_Wallet_By_Claimed_Is_Null(ctx context.Context) (
-	count int64, err error) {
-
-	var __embed_stmt = __sqlbundle_Literal("SELECT COUNT(*) FROM wallets WHERE wallets.claimed is NULL")
-
-	var __values []any
-
-	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
-	obj.logStmt(__stmt, __values...)
+	for {
+		rows, err = func() (rows []*ScanWatermark, err error) {
+			__rows, err := obj.driver.QueryContext(ctx, __stmt, __values...)
+			if err != nil {
+				return nil, err
+			}
+			defer closeRows(__rows, &err)
 
-	err = obj.queryRowContext(ctx, __stmt, __values...).Scan(&count)
-	if err != nil {
-		return 0, obj.makeErr(err)
+			for __rows.Next() {
+				scan_watermark := &ScanWatermark{}
+				err = __rows.Scan(&scan_watermark.Satellite, &scan_watermark.ChainId, &scan_watermark.BlockNumber)
+				if err != nil {
+					return nil, err
+				}
+				rows = append(rows, scan_watermark)
+			}
+			return rows, nil
+		}()
+		if err != nil {
+			if obj.shouldRetry(err) {
+				continue
+			}
+			return nil, obj.makeErr(err)
+		}
+		return rows, nil
 	}
 
-	return count, nil
-
 }
 
-func (obj *pgxImpl) All_Wallet_By_Claimed_IsNot_Null(ctx context.Context) (
-	rows []*Wallet, err error) {
+func (obj *pgxImpl) All_ReportedTransferEvent_By_Satellite_And_ChainId_And_BlockNumber_GreaterOrEqual_And_Removed_OrderBy_Asc_BlockNumber_Asc_LogIndex(ctx context.Context,
+	reported_transfer_event_satellite ReportedTransferEvent_Satellite_Field,
+	reported_transfer_event_chain_id ReportedTransferEvent_ChainId_Field,
+	reported_transfer_event_block_number_greater_or_equal ReportedTransferEvent_BlockNumber_Field,
+	reported_transfer_event_removed ReportedTransferEvent_Removed_Field) (
+	rows []*ReportedTransferEvent, err error) {
 
-	var __embed_stmt = __sqlbundle_Literal("SELECT wallets.id, wallets.address, wallets.claimed, wallets.satellite, wallets.info, wallets.created_at FROM wallets WHERE wallets.claimed is not NULL")
+	var __embed_stmt = __sqlbundle_Literal("SELECT reported_transfer_events.satellite, reported_transfer_events.chain_id, reported_transfer_events.block_hash, reported_transfer_events.block_number, reported_transfer_events.transaction, reported_transfer_events.log_index, reported_transfer_events.trace_index, reported_transfer_events.from_address, reported_transfer_events.to_address, reported_transfer_events.token_value, reported_transfer_events.contract, reported_transfer_events.removed, reported_transfer_events.removed_block, reported_transfer_events.created_at FROM reported_transfer_events WHERE reported_transfer_events.satellite = ? AND reported_transfer_events.chain_id = ? AND reported_transfer_events.block_number >= ? AND reported_transfer_events.removed = ? ORDER BY reported_transfer_events.block_number, reported_transfer_events.log_index")
 
 	var __values []any
+	__values = append(__values, reported_transfer_event_satellite.value(), reported_transfer_event_chain_id.value(), reported_transfer_event_block_number_greater_or_equal.value(), reported_transfer_event_removed.value())
 
 	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
 	obj.logStmt(__stmt, __values...)
 
 	for {
-		rows, err = func() (rows []*Wallet, err error) {
+		rows, err = func() (rows []*ReportedTransferEvent, err error) {
 			__rows, err := obj.driver.QueryContext(ctx, __stmt, __values...)
 			if err != nil {
 				return nil, err
@@ -1608,12 +2735,12 @@ func (obj *pgxImpl) All_Wallet_By_Claimed_IsNot_Null(ctx context.Context) (
 			defer closeRows(__rows, &err)
 
 			for __rows.Next() {
-				wallet := &Wallet{}
-				err = __rows.Scan(&wallet.Id, &wallet.Address, &wallet.Claimed, &wallet.Satellite, &wallet.Info, &wallet.CreatedAt)
+				reported_transfer_event := &ReportedTransferEvent{}
+				err = __rows.Scan(&reported_transfer_event.Satellite, &reported_transfer_event.ChainId, &reported_transfer_event.BlockHash, &reported_transfer_event.BlockNumber, &reported_transfer_event.Transaction, &reported_transfer_event.LogIndex, &reported_transfer_event.TraceIndex, &reported_transfer_event.FromAddress, &reported_transfer_event.ToAddress, &reported_transfer_event.TokenValue, &reported_transfer_event.Contract, &reported_transfer_event.Removed, &reported_transfer_event.RemovedBlock, &reported_transfer_event.CreatedAt)
 				if err != nil {
 					return nil, err
 				}
-				rows = append(rows, wallet)
+				rows = append(rows, reported_transfer_event)
 			}
 			return rows, nil
 		}()
@@ -1628,35 +2755,37 @@ func (obj *pgxImpl) All_Wallet_By_Claimed_IsNot_Null(ctx context.Context) (
 
 }
 
-func (obj *pgxImpl) All_Wallet_By_Satellite_And_Claimed_IsNot_Null(ctx context.Context,
-	wallet_satellite Wallet_Satellite_Field) (
-	rows []*Wallet, err error) {
+func (obj *pgxImpl) First_EthPrice_By_IntervalStart_Less_OrderBy_Desc_IntervalStart(ctx context.Context,
+	eth_price_interval_start_less EthPrice_IntervalStart_Field) (
+	eth_price *EthPrice, err error) {
 
-	var __embed_stmt = __sqlbundle_Literal("SELECT wallets.id, wallets.address, wallets.claimed, wallets.satellite, wallets.info, wallets.created_at FROM wallets WHERE wallets.satellite = ? AND wallets.claimed is not NULL")
+	var __embed_stmt = __sqlbundle_Literal("SELECT eth_prices.interval_start, eth_prices.price FROM eth_prices WHERE eth_prices.interval_start < ? ORDER BY eth_prices.interval_start DESC LIMIT 1 OFFSET 0")
 
 	var __values []any
-	__values = append(__values, wallet_satellite.value())
+	__values = append(__values, eth_price_interval_start_less.value())
 
 	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
 	obj.logStmt(__stmt, __values...)
 
 	for {
-		rows, err = func() (rows []*Wallet, err error) {
+		eth_price, err = func() (eth_price *EthPrice, err error) {
 			__rows, err := obj.driver.QueryContext(ctx, __stmt, __values...)
 			if err != nil {
 				return nil, err
 			}
 			defer closeRows(__rows, &err)
 
-			for __rows.Next() {
-				wallet := &Wallet{}
-				err = __rows.Scan(&wallet.Id, &wallet.Address, &wallet.Claimed, &wallet.Satellite, &wallet.Info, &wallet.CreatedAt)
-				if err != nil {
-					return nil, err
-				}
-				rows = append(rows, wallet)
+			if !__rows.Next() {
+				return nil, nil
 			}
-			return rows, nil
+
+			eth_price = &EthPrice{}
+			err = __rows.Scan(&eth_price.IntervalStart, &eth_price.Price)
+			if err != nil {
+				return nil, err
+			}
+
+			return eth_price, nil
 		}()
 		if err != nil {
 			if obj.shouldRetry(err) {
@@ -1664,7 +2793,7 @@ func (obj *pgxImpl) All_Wallet_By_Satellite_And_Claimed_IsNot_Null(ctx context.C
 			}
 			return nil, obj.makeErr(err)
 		}
-		return rows, nil
+		return eth_price, nil
 	}
 
 }
@@ -1742,14 +2871,42 @@ func (obj *pgxImpl) Delete_BlockHeader_By_ChainId_And_Hash(ctx context.Context,
 
 }
 
-func (obj *pgxImpl) Delete_BlockHeader_By_Timestamp_Less(ctx context.Context,
+func (obj *pgxImpl) Delete_BlockHeader_By_ChainId_And_Number_Less(ctx context.Context,
+	block_header_chain_id BlockHeader_ChainId_Field,
+	block_header_number_less BlockHeader_Number_Field) (
+	count int64, err error) {
+
+	var __embed_stmt = __sqlbundle_Literal("DELETE FROM block_headers WHERE block_headers.chain_id = ? AND block_headers.number < ?")
+
+	var __values []any
+	__values = append(__values, block_header_chain_id.value(), block_header_number_less.value())
+
+	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
+	obj.logStmt(__stmt, __values...)
+
+	__res, err := obj.driver.ExecContext(ctx, __stmt, __values...)
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+
+	count, err = __res.RowsAffected()
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+
+	return count, nil
+
+}
+
+func (obj *pgxImpl) Delete_BlockHeader_By_ChainId_And_Timestamp_Less(ctx context.Context,
+	block_header_chain_id BlockHeader_ChainId_Field,
 	block_header_timestamp_less BlockHeader_Timestamp_Field) (
 	count int64, err error) {
 
-	var __embed_stmt = __sqlbundle_Literal("DELETE FROM block_headers WHERE block_headers.timestamp < ?")
+	var __embed_stmt = __sqlbundle_Literal("DELETE FROM block_headers WHERE block_headers.chain_id = ? AND block_headers.timestamp < ?")
 
 	var __values []any
-	__values = append(__values, block_header_timestamp_less.value())
+	__values = append(__values, block_header_chain_id.value(), block_header_timestamp_less.value())
 
 	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
 	obj.logStmt(__stmt, __values...)
@@ -1794,6 +2951,32 @@ func (obj *pgxImpl) Delete_TokenPrice_By_IntervalStart_Less(ctx context.Context,
 
 }
 
+func (obj *pgxImpl) Delete_EthPrice_By_IntervalStart_Less(ctx context.Context,
+	eth_price_interval_start_less EthPrice_IntervalStart_Field) (
+	count int64, err error) {
+
+	var __embed_stmt = __sqlbundle_Literal("DELETE FROM eth_prices WHERE eth_prices.interval_start < ?")
+
+	var __values []any
+	__values = append(__values, eth_price_interval_start_less.value())
+
+	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
+	obj.logStmt(__stmt, __values...)
+
+	__res, err := obj.driver.ExecContext(ctx, __stmt, __values...)
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+
+	count, err = __res.RowsAffected()
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+
+	return count, nil
+
+}
+
 func (impl pgxImpl) isConstraintError(err error) (constraint string, ok bool) {
 	if e, ok := err.(*pgconn.PgError); ok {
 		if e.Code[:2] == "23" {
@@ -1811,6 +2994,16 @@ func (obj *pgxImpl) deleteAll(ctx context.Context) (count int64, err error) {
 		return 0, obj.makeErr(err)
 	}
 
+	__count, err = __res.RowsAffected()
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+	count += __count
+	__res, err = obj.driver.ExecContext(ctx, "DELETE FROM transfer_events;")
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+
 	__count, err = __res.RowsAffected()
 	if err != nil {
 		return 0, obj.makeErr(err)
@@ -1821,6 +3014,46 @@ func (obj *pgxImpl) deleteAll(ctx context.Context) (count int64, err error) {
 		return 0, obj.makeErr(err)
 	}
 
+	__count, err = __res.RowsAffected()
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+	count += __count
+	__res, err = obj.driver.ExecContext(ctx, "DELETE FROM scan_watermarks;")
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+
+	__count, err = __res.RowsAffected()
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+	count += __count
+	__res, err = obj.driver.ExecContext(ctx, "DELETE FROM reported_transfer_events;")
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+
+	__count, err = __res.RowsAffected()
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+	count += __count
+	__res, err = obj.driver.ExecContext(ctx, "DELETE FROM indexed_blocks;")
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+
+	__count, err = __res.RowsAffected()
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+	count += __count
+	__res, err = obj.driver.ExecContext(ctx, "DELETE FROM eth_prices;")
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+
 	__count, err = __res.RowsAffected()
 	if err != nil {
 		return 0, obj.makeErr(err)
@@ -1845,27 +3078,29 @@ func (obj *pgxcockroachImpl) Create_BlockHeader(ctx context.Context,
 	block_header_chain_id BlockHeader_ChainId_Field,
 	block_header_hash BlockHeader_Hash_Field,
 	block_header_number BlockHeader_Number_Field,
-	block_header_timestamp BlockHeader_Timestamp_Field) (
+	block_header_timestamp BlockHeader_Timestamp_Field,
+	block_header_parent_hash BlockHeader_ParentHash_Field) (
 	block_header *BlockHeader, err error) {
 	__chain_id_val := block_header_chain_id.value()
 	__hash_val := block_header_hash.value()
 	__number_val := block_header_number.value()
 	__timestamp_val := block_header_timestamp.value()
+	__parent_hash_val := block_header_parent_hash.value()
 
-	var __columns = &__sqlbundle_Hole{SQL: __sqlbundle_Literal("chain_id, hash, number, timestamp")}
-	var __placeholders = &__sqlbundle_Hole{SQL: __sqlbundle_Literal("?, ?, ?, ?")}
+	var __columns = &__sqlbundle_Hole{SQL: __sqlbundle_Literal("chain_id, hash, number, timestamp, parent_hash")}
+	var __placeholders = &__sqlbundle_Hole{SQL: __sqlbundle_Literal("?, ?, ?, ?, ?")}
 	var __clause = &__sqlbundle_Hole{SQL: __sqlbundle_Literals{Join: "", SQLs: []__sqlbundle_SQL{__sqlbundle_Literal("("), __columns, __sqlbundle_Literal(") VALUES ("), __placeholders, __sqlbundle_Literal(")")}}}
 
-	var __embed_stmt = __sqlbundle_Literals{Join: "", SQLs: []__sqlbundle_SQL{__sqlbundle_Literal("INSERT INTO block_headers "), __clause, __sqlbundle_Literal(" RETURNING block_headers.chain_id, block_headers.hash, block_headers.number, block_headers.timestamp, block_headers.created_at")}}
+	var __embed_stmt = __sqlbundle_Literals{Join: "", SQLs: []__sqlbundle_SQL{__sqlbundle_Literal("INSERT INTO block_headers "), __clause, __sqlbundle_Literal(" RETURNING block_headers.chain_id, block_headers.hash, block_headers.number, block_headers.timestamp, block_headers.parent_hash, block_headers.created_at")}}
 
 	var __values []any
-	__values = append(__values, __chain_id_val, __hash_val, __number_val, __timestamp_val)
+	__values = append(__values, __chain_id_val, __hash_val, __number_val, __timestamp_val, __parent_hash_val)
 
 	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
 	obj.logStmt(__stmt, __values...)
 
 	block_header = &BlockHeader{}
-	err = obj.queryRowContext(ctx, __stmt, __values...).Scan(&block_header.ChainId, &block_header.Hash, &block_header.Number, &block_header.Timestamp, &block_header.CreatedAt)
+	err = obj.queryRowContext(ctx, __stmt, __values...).Scan(&block_header.ChainId, &block_header.Hash, &block_header.Number, &block_header.Timestamp, &block_header.ParentHash, &block_header.CreatedAt)
 	if err != nil {
 		return nil, obj.makeErr(err)
 	}
@@ -1906,54 +3141,217 @@ func (obj *pgxcockroachImpl) Create_Wallet(ctx context.Context,
 	__satellite_val := wallet_satellite.value()
 	__info_val := optional.Info.value()
 
-	var __columns = &__sqlbundle_Hole{SQL: __sqlbundle_Literal("address, claimed, satellite, info")}
-	var __placeholders = &__sqlbundle_Hole{SQL: __sqlbundle_Literal("?, ?, ?, ?")}
-	var __clause = &__sqlbundle_Hole{SQL: __sqlbundle_Literals{Join: "", SQLs: []__sqlbundle_SQL{__sqlbundle_Literal("("), __columns, __sqlbundle_Literal(") VALUES ("), __placeholders, __sqlbundle_Literal(")")}}}
+	var __columns = &__sqlbundle_Hole{SQL: __sqlbundle_Literal("address, claimed, satellite, info")}
+	var __placeholders = &__sqlbundle_Hole{SQL: __sqlbundle_Literal("?, ?, ?, ?")}
+	var __clause = &__sqlbundle_Hole{SQL: __sqlbundle_Literals{Join: "", SQLs: []__sqlbundle_SQL{__sqlbundle_Literal("("), __columns, __sqlbundle_Literal(") VALUES ("), __placeholders, __sqlbundle_Literal(")")}}}
+
+	var __embed_stmt = __sqlbundle_Literals{Join: "", SQLs: []__sqlbundle_SQL{__sqlbundle_Literal("INSERT INTO wallets "), __clause, __sqlbundle_Literal(" RETURNING wallets.id, wallets.address, wallets.claimed, wallets.satellite, wallets.info, wallets.created_at")}}
+
+	var __values []any
+	__values = append(__values, __address_val, __claimed_val, __satellite_val, __info_val)
+
+	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
+	obj.logStmt(__stmt, __values...)
+
+	wallet = &Wallet{}
+	err = obj.queryRowContext(ctx, __stmt, __values...).Scan(&wallet.Id, &wallet.Address, &wallet.Claimed, &wallet.Satellite, &wallet.Info, &wallet.CreatedAt)
+	if err != nil {
+		return nil, obj.makeErr(err)
+	}
+	return wallet, nil
+
+}
+
+func (obj *pgxcockroachImpl) ReplaceNoReturn_TransferEvent(ctx context.Context,
+	transfer_event_chain_id TransferEvent_ChainId_Field,
+	transfer_event_block_hash TransferEvent_BlockHash_Field,
+	transfer_event_block_number TransferEvent_BlockNumber_Field,
+	transfer_event_transaction TransferEvent_Transaction_Field,
+	transfer_event_log_index TransferEvent_LogIndex_Field,
+	transfer_event_trace_index TransferEvent_TraceIndex_Field,
+	transfer_event_from_address TransferEvent_FromAddress_Field,
+	transfer_event_to_address TransferEvent_ToAddress_Field,
+	transfer_event_token_value TransferEvent_TokenValue_Field,
+	transfer_event_contract TransferEvent_Contract_Field) (
+	err error) {
+	__chain_id_val := transfer_event_chain_id.value()
+	__block_hash_val := transfer_event_block_hash.value()
+	__block_number_val := transfer_event_block_number.value()
+	__transaction_val := transfer_event_transaction.value()
+	__log_index_val := transfer_event_log_index.value()
+	__trace_index_val := transfer_event_trace_index.value()
+	__from_address_val := transfer_event_from_address.value()
+	__to_address_val := transfer_event_to_address.value()
+	__token_value_val := transfer_event_token_value.value()
+	__contract_val := transfer_event_contract.value()
+
+	var __embed_stmt = __sqlbundle_Literal("INSERT INTO transfer_events ( chain_id, block_hash, block_number, transaction, log_index, trace_index, from_address, to_address, token_value, contract ) VALUES ( ?, ?, ?, ?, ?, ?, ?, ?, ?, ? ) ON CONFLICT ( chain_id, block_hash, log_index, trace_index ) DO UPDATE SET chain_id = EXCLUDED.chain_id, block_hash = EXCLUDED.block_hash, block_number = EXCLUDED.block_number, transaction = EXCLUDED.transaction, log_index = EXCLUDED.log_index, trace_index = EXCLUDED.trace_index, from_address = EXCLUDED.from_address, to_address = EXCLUDED.to_address, token_value = EXCLUDED.token_value, contract = EXCLUDED.contract")
+
+	var __values []any
+	__values = append(__values, __chain_id_val, __block_hash_val, __block_number_val, __transaction_val, __log_index_val, __trace_index_val, __from_address_val, __to_address_val, __token_value_val, __contract_val)
+
+	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
+	obj.logStmt(__stmt, __values...)
+
+	_, err = obj.driver.ExecContext(ctx, __stmt, __values...)
+	if err != nil {
+		return obj.makeErr(err)
+	}
+	return nil
+
+}
+
+func (obj *pgxcockroachImpl) ReplaceNoReturn_IndexedBlock(ctx context.Context,
+	indexed_block_chain_id IndexedBlock_ChainId_Field,
+	indexed_block_hash IndexedBlock_Hash_Field,
+	indexed_block_number IndexedBlock_Number_Field,
+	indexed_block_timestamp IndexedBlock_Timestamp_Field) (
+	err error) {
+	__chain_id_val := indexed_block_chain_id.value()
+	__hash_val := indexed_block_hash.value()
+	__number_val := indexed_block_number.value()
+	__timestamp_val := indexed_block_timestamp.value()
+
+	var __embed_stmt = __sqlbundle_Literal("INSERT INTO indexed_blocks ( chain_id, hash, number, timestamp ) VALUES ( ?, ?, ?, ? ) ON CONFLICT ( chain_id ) DO UPDATE SET chain_id = EXCLUDED.chain_id, hash = EXCLUDED.hash, number = EXCLUDED.number, timestamp = EXCLUDED.timestamp")
+
+	var __values []any
+	__values = append(__values, __chain_id_val, __hash_val, __number_val, __timestamp_val)
+
+	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
+	obj.logStmt(__stmt, __values...)
+
+	_, err = obj.driver.ExecContext(ctx, __stmt, __values...)
+	if err != nil {
+		return obj.makeErr(err)
+	}
+	return nil
+
+}
+
+func (obj *pgxcockroachImpl) ReplaceNoReturn_ScanWatermark(ctx context.Context,
+	scan_watermark_satellite ScanWatermark_Satellite_Field,
+	scan_watermark_chain_id ScanWatermark_ChainId_Field,
+	scan_watermark_block_number ScanWatermark_BlockNumber_Field) (
+	err error) {
+	__satellite_val := scan_watermark_satellite.value()
+	__chain_id_val := scan_watermark_chain_id.value()
+	__block_number_val := scan_watermark_block_number.value()
+
+	var __embed_stmt = __sqlbundle_Literal("INSERT INTO scan_watermarks ( satellite, chain_id, block_number ) VALUES ( ?, ?, ? ) ON CONFLICT ( satellite, chain_id ) DO UPDATE SET satellite = EXCLUDED.satellite, chain_id = EXCLUDED.chain_id, block_number = EXCLUDED.block_number")
+
+	var __values []any
+	__values = append(__values, __satellite_val, __chain_id_val, __block_number_val)
+
+	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
+	obj.logStmt(__stmt, __values...)
+
+	_, err = obj.driver.ExecContext(ctx, __stmt, __values...)
+	if err != nil {
+		return obj.makeErr(err)
+	}
+	return nil
+
+}
+
+func (obj *pgxcockroachImpl) ReplaceNoReturn_ReportedTransferEvent(ctx context.Context,
+	reported_transfer_event_satellite ReportedTransferEvent_Satellite_Field,
+	reported_transfer_event_chain_id ReportedTransferEvent_ChainId_Field,
+	reported_transfer_event_block_hash ReportedTransferEvent_BlockHash_Field,
+	reported_transfer_event_block_number ReportedTransferEvent_BlockNumber_Field,
+	reported_transfer_event_transaction ReportedTransferEvent_Transaction_Field,
+	reported_transfer_event_log_index ReportedTransferEvent_LogIndex_Field,
+	reported_transfer_event_trace_index ReportedTransferEvent_TraceIndex_Field,
+	reported_transfer_event_from_address ReportedTransferEvent_FromAddress_Field,
+	reported_transfer_event_to_address ReportedTransferEvent_ToAddress_Field,
+	reported_transfer_event_token_value ReportedTransferEvent_TokenValue_Field,
+	reported_transfer_event_contract ReportedTransferEvent_Contract_Field,
+	reported_transfer_event_removed ReportedTransferEvent_Removed_Field,
+	reported_transfer_event_removed_block ReportedTransferEvent_RemovedBlock_Field) (
+	err error) {
+	__satellite_val := reported_transfer_event_satellite.value()
+	__chain_id_val := reported_transfer_event_chain_id.value()
+	__block_hash_val := reported_transfer_event_block_hash.value()
+	__block_number_val := reported_transfer_event_block_number.value()
+	__transaction_val := reported_transfer_event_transaction.value()
+	__log_index_val := reported_transfer_event_log_index.value()
+	__trace_index_val := reported_transfer_event_trace_index.value()
+	__from_address_val := reported_transfer_event_from_address.value()
+	__to_address_val := reported_transfer_event_to_address.value()
+	__token_value_val := reported_transfer_event_token_value.value()
+	__contract_val := reported_transfer_event_contract.value()
+	__removed_val := reported_transfer_event_removed.value()
+	__removed_block_val := reported_transfer_event_removed_block.value()
+
+	var __embed_stmt = __sqlbundle_Literal("INSERT INTO reported_transfer_events ( satellite, chain_id, block_hash, block_number, transaction, log_index, trace_index, from_address, to_address, token_value, contract, removed, removed_block ) VALUES ( ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ? ) ON CONFLICT ( satellite, chain_id, block_hash, log_index, trace_index ) DO UPDATE SET satellite = EXCLUDED.satellite, chain_id = EXCLUDED.chain_id, block_hash = EXCLUDED.block_hash, block_number = EXCLUDED.block_number, transaction = EXCLUDED.transaction, log_index = EXCLUDED.log_index, trace_index = EXCLUDED.trace_index, from_address = EXCLUDED.from_address, to_address = EXCLUDED.to_address, token_value = EXCLUDED.token_value, contract = EXCLUDED.contract, removed = EXCLUDED.removed, removed_block = EXCLUDED.removed_block")
+
+	var __values []any
+	__values = append(__values, __satellite_val, __chain_id_val, __block_hash_val, __block_number_val, __transaction_val, __log_index_val, __trace_index_val, __from_address_val, __to_address_val, __token_value_val, __contract_val, __removed_val, __removed_block_val)
+
+	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
+	obj.logStmt(__stmt, __values...)
+
+	_, err = obj.driver.ExecContext(ctx, __stmt, __values...)
+	if err != nil {
+		return obj.makeErr(err)
+	}
+	return nil
+
+}
 
-	var __embed_stmt = __sqlbundle_Literals{Join: "", SQLs: []__sqlbundle_SQL{__sqlbundle_Literal("INSERT INTO wallets "), __clause, __sqlbundle_Literal(" RETURNING wallets.id, wallets.address, wallets.claimed, wallets.satellite, wallets.info, wallets.created_at")}}
+func (obj *pgxcockroachImpl) ReplaceNoReturn_EthPrice(ctx context.Context,
+	eth_price_interval_start EthPrice_IntervalStart_Field,
+	eth_price_price EthPrice_Price_Field) (
+	err error) {
+	__interval_start_val := eth_price_interval_start.value()
+	__price_val := eth_price_price.value()
+
+	var __embed_stmt = __sqlbundle_Literal("INSERT INTO eth_prices ( interval_start, price ) VALUES ( ?, ? ) ON CONFLICT ( interval_start ) DO UPDATE SET interval_start = EXCLUDED.interval_start, price = EXCLUDED.price")
 
 	var __values []any
-	__values = append(__values, __address_val, __claimed_val, __satellite_val, __info_val)
+	__values = append(__values, __interval_start_val, __price_val)
 
 	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
 	obj.logStmt(__stmt, __values...)
 
-	wallet = &Wallet{}
-	err = obj.queryRowContext(ctx, __stmt, __values...).Scan(&wallet.Id, &wallet.Address, &wallet.Claimed, &wallet.Satellite, &wallet.Info, &wallet.CreatedAt)
+	_, err = obj.driver.ExecContext(ctx, __stmt, __values...)
 	if err != nil {
-		return nil, obj.makeErr(err)
+		return obj.makeErr(err)
 	}
-	return wallet, nil
+	return nil
 
 }
 
-func (obj *pgxcockroachImpl) All_BlockHeader_OrderBy_Desc_Timestamp(ctx context.Context) (
-	rows []*BlockHeader, err error) {
+func (obj *pgxcockroachImpl) First_BlockHeader_By_ChainId_OrderBy_Desc_Number(ctx context.Context,
+	block_header_chain_id BlockHeader_ChainId_Field) (
+	block_header *BlockHeader, err error) {
 
-	var __embed_stmt = __sqlbundle_Literal("SELECT block_headers.chain_id, block_headers.hash, block_headers.number, block_headers.timestamp, block_headers.created_at FROM block_headers ORDER BY block_headers.timestamp DESC")
+	var __embed_stmt = __sqlbundle_Literal("SELECT block_headers.chain_id, block_headers.hash, block_headers.number, block_headers.timestamp, block_headers.parent_hash, block_headers.created_at FROM block_headers WHERE block_headers.chain_id = ? ORDER BY block_headers.number DESC LIMIT 1 OFFSET 0")
 
 	var __values []any
+	__values = append(__values, block_header_chain_id.value())
 
 	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
 	obj.logStmt(__stmt, __values...)
 
 	for {
-		rows, err = func() (rows []*BlockHeader, err error) {
+		block_header, err = func() (block_header *BlockHeader, err error) {
 			__rows, err := obj.driver.QueryContext(ctx, __stmt, __values...)
 			if err != nil {
 				return nil, err
 			}
 			defer closeRows(__rows, &err)
 
-			for __rows.Next() {
-				block_header := &BlockHeader{}
-				err = __rows.Scan(&block_header.ChainId, &block_header.Hash, &block_header.Number, &block_header.Timestamp, &block_header.CreatedAt)
-				if err != nil {
-					return nil, err
-				}
-				rows = append(rows, block_header)
+			if !__rows.Next() {
+				return nil, nil
 			}
-			return rows, nil
+
+			block_header = &BlockHeader{}
+			err = __rows.Scan(&block_header.ChainId, &block_header.Hash, &block_header.Number, &block_header.Timestamp, &block_header.ParentHash, &block_header.CreatedAt)
+			if err != nil {
+				return nil, err
+			}
+
+			return block_header, nil
 		}()
 		if err != nil {
 			if obj.shouldRetry(err) {
@@ -1961,7 +3359,7 @@ func (obj *pgxcockroachImpl) All_BlockHeader_OrderBy_Desc_Timestamp(ctx context.
 			}
 			return nil, obj.makeErr(err)
 		}
-		return rows, nil
+		return block_header, nil
 	}
 
 }
@@ -1971,7 +3369,7 @@ func (obj *pgxcockroachImpl) Get_BlockHeader_By_ChainId_And_Hash(ctx context.Con
 	block_header_hash BlockHeader_Hash_Field) (
 	block_header *BlockHeader, err error) {
 
-	var __embed_stmt = __sqlbundle_Literal("SELECT block_headers.chain_id, block_headers.hash, block_headers.number, block_headers.timestamp, block_headers.created_at FROM block_headers WHERE block_headers.chain_id = ? AND block_headers.hash = ?")
+	var __embed_stmt = __sqlbundle_Literal("SELECT block_headers.chain_id, block_headers.hash, block_headers.number, block_headers.timestamp, block_headers.parent_hash, block_headers.created_at FROM block_headers WHERE block_headers.chain_id = ? AND block_headers.hash = ?")
 
 	var __values []any
 	__values = append(__values, block_header_chain_id.value(), block_header_hash.value())
@@ -1980,7 +3378,7 @@ func (obj *pgxcockroachImpl) Get_BlockHeader_By_ChainId_And_Hash(ctx context.Con
 	obj.logStmt(__stmt, __values...)
 
 	block_header = &BlockHeader{}
-	err = obj.queryRowContext(ctx, __stmt, __values...).Scan(&block_header.ChainId, &block_header.Hash, &block_header.Number, &block_header.Timestamp, &block_header.CreatedAt)
+	err = obj.queryRowContext(ctx, __stmt, __values...).Scan(&block_header.ChainId, &block_header.Hash, &block_header.Number, &block_header.Timestamp, &block_header.ParentHash, &block_header.CreatedAt)
 	if err != nil {
 		return (*BlockHeader)(nil), obj.makeErr(err)
 	}
@@ -1993,7 +3391,7 @@ func (obj *pgxcockroachImpl) Get_BlockHeader_By_ChainId_And_Number(ctx context.C
 	block_header_number BlockHeader_Number_Field) (
 	block_header *BlockHeader, err error) {
 
-	var __embed_stmt = __sqlbundle_Literal("SELECT block_headers.chain_id, block_headers.hash, block_headers.number, block_headers.timestamp, block_headers.created_at FROM block_headers WHERE block_headers.chain_id = ? AND block_headers.number = ? LIMIT 2")
+	var __embed_stmt = __sqlbundle_Literal("SELECT block_headers.chain_id, block_headers.hash, block_headers.number, block_headers.timestamp, block_headers.parent_hash, block_headers.created_at FROM block_headers WHERE block_headers.chain_id = ? AND block_headers.number = ? LIMIT 2")
 
 	var __values []any
 	__values = append(__values, block_header_chain_id.value(), block_header_number.value())
@@ -2014,7 +3412,7 @@ func (obj *pgxcockroachImpl) Get_BlockHeader_By_ChainId_And_Number(ctx context.C
 			}
 
 			block_header = &BlockHeader{}
-			err = __rows.Scan(&block_header.ChainId, &block_header.Hash, &block_header.Number, &block_header.Timestamp, &block_header.CreatedAt)
+			err = __rows.Scan(&block_header.ChainId, &block_header.Hash, &block_header.Number, &block_header.Timestamp, &block_header.ParentHash, &block_header.CreatedAt)
 			if err != nil {
 				return nil, err
 			}
@@ -2334,6 +3732,155 @@ func (obj *pgxcockroachImpl) All_Wallet_By_Satellite_And_Claimed_IsNot_Null(ctx
 
 }
 
+func (obj *pgxcockroachImpl) Get_IndexedBlock_By_ChainId(ctx context.Context,
+	indexed_block_chain_id IndexedBlock_ChainId_Field) (
+	indexed_block *IndexedBlock, err error) {
+
+	var __embed_stmt = __sqlbundle_Literal("SELECT indexed_blocks.chain_id, indexed_blocks.hash, indexed_blocks.number, indexed_blocks.timestamp FROM indexed_blocks WHERE indexed_blocks.chain_id = ?")
+
+	var __values []any
+	__values = append(__values, indexed_block_chain_id.value())
+
+	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
+	obj.logStmt(__stmt, __values...)
+
+	indexed_block = &IndexedBlock{}
+	err = obj.queryRowContext(ctx, __stmt, __values...).Scan(&indexed_block.ChainId, &indexed_block.Hash, &indexed_block.Number, &indexed_block.Timestamp)
+	if err != nil {
+		return (*IndexedBlock)(nil), obj.makeErr(err)
+	}
+	return indexed_block, nil
+
+}
+
+func (obj *pgxcockroachImpl) All_ScanWatermark_By_Satellite(ctx context.Context,
+	scan_watermark_satellite ScanWatermark_Satellite_Field) (
+	rows []*ScanWatermark, err error) {
+
+	var __embed_stmt = __sqlbundle_Literal("SELECT scan_watermarks.satellite, scan_watermarks.chain_id, scan_watermarks.block_number FROM scan_watermarks WHERE scan_watermarks.satellite = ?")
+
+	var __values []any
+	__values = append(__values, scan_watermark_satellite.value())
+
+	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
+	obj.logStmt(__stmt, __values...)
+
+	for {
+		rows, err = func() (rows []*ScanWatermark, err error) {
+			__rows, err := obj.driver.QueryContext(ctx, __stmt, __values...)
+			if err != nil {
+				return nil, err
+			}
+			defer closeRows(__rows, &err)
+
+			for __rows.Next() {
+				scan_watermark := &ScanWatermark{}
+				err = __rows.Scan(&scan_watermark.Satellite, &scan_watermark.ChainId, &scan_watermark.BlockNumber)
+				if err != nil {
+					return nil, err
+				}
+				rows = append(rows, scan_watermark)
+			}
+			return rows, nil
+		}()
+		if err != nil {
+			if obj.shouldRetry(err) {
+				continue
+			}
+			return nil, obj.makeErr(err)
+		}
+		return rows, nil
+	}
+
+}
+
+func (obj *pgxcockroachImpl) All_ReportedTransferEvent_By_Satellite_And_ChainId_And_BlockNumber_GreaterOrEqual_And_Removed_OrderBy_Asc_BlockNumber_Asc_LogIndex(ctx context.Context,
+	reported_transfer_event_satellite ReportedTransferEvent_Satellite_Field,
+	reported_transfer_event_chain_id ReportedTransferEvent_ChainId_Field,
+	reported_transfer_event_block_number_greater_or_equal ReportedTransferEvent_BlockNumber_Field,
+	reported_transfer_event_removed ReportedTransferEvent_Removed_Field) (
+	rows []*ReportedTransferEvent, err error) {
+
+	var __embed_stmt = __sqlbundle_Literal("SELECT reported_transfer_events.satellite, reported_transfer_events.chain_id, reported_transfer_events.block_hash, reported_transfer_events.block_number, reported_transfer_events.transaction, reported_transfer_events.log_index, reported_transfer_events.trace_index, reported_transfer_events.from_address, reported_transfer_events.to_address, reported_transfer_events.token_value, reported_transfer_events.contract, reported_transfer_events.removed, reported_transfer_events.removed_block, reported_transfer_events.created_at FROM reported_transfer_events WHERE reported_transfer_events.satellite = ? AND reported_transfer_events.chain_id = ? AND reported_transfer_events.block_number >= ? AND reported_transfer_events.removed = ? ORDER BY reported_transfer_events.block_number, reported_transfer_events.log_index")
+
+	var __values []any
+	__values = append(__values, reported_transfer_event_satellite.value(), reported_transfer_event_chain_id.value(), reported_transfer_event_block_number_greater_or_equal.value(), reported_transfer_event_removed.value())
+
+	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
+	obj.logStmt(__stmt, __values...)
+
+	for {
+		rows, err = func() (rows []*ReportedTransferEvent, err error) {
+			__rows, err := obj.driver.QueryContext(ctx, __stmt, __values...)
+			if err != nil {
+				return nil, err
+			}
+			defer closeRows(__rows, &err)
+
+			for __rows.Next() {
+				reported_transfer_event := &ReportedTransferEvent{}
+				err = __rows.Scan(&reported_transfer_event.Satellite, &reported_transfer_event.ChainId, &reported_transfer_event.BlockHash, &reported_transfer_event.BlockNumber, &reported_transfer_event.Transaction, &reported_transfer_event.LogIndex, &reported_transfer_event.TraceIndex, &reported_transfer_event.FromAddress, &reported_transfer_event.ToAddress, &reported_transfer_event.TokenValue, &reported_transfer_event.Contract, &reported_transfer_event.Removed, &reported_transfer_event.RemovedBlock, &reported_transfer_event.CreatedAt)
+				if err != nil {
+					return nil, err
+				}
+				rows = append(rows, reported_transfer_event)
+			}
+			return rows, nil
+		}()
+		if err != nil {
+			if obj.shouldRetry(err) {
+				continue
+			}
+			return nil, obj.makeErr(err)
+		}
+		return rows, nil
+	}
+
+}
+
+func (obj *pgxcockroachImpl) First_EthPrice_By_IntervalStart_Less_OrderBy_Desc_IntervalStart(ctx context.Context,
+	eth_price_interval_start_less EthPrice_IntervalStart_Field) (
+	eth_price *EthPrice, err error) {
+
+	var __embed_stmt = __sqlbundle_Literal("SELECT eth_prices.interval_start, eth_prices.price FROM eth_prices WHERE eth_prices.interval_start < ? ORDER BY eth_prices.interval_start DESC LIMIT 1 OFFSET 0")
+
+	var __values []any
+	__values = append(__values, eth_price_interval_start_less.value())
+
+	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
+	obj.logStmt(__stmt, __values...)
+
+	for {
+		eth_price, err = func() (eth_price *EthPrice, err error) {
+			__rows, err := obj.driver.QueryContext(ctx, __stmt, __values...)
+			if err != nil {
+				return nil, err
+			}
+			defer closeRows(__rows, &err)
+
+			if !__rows.Next() {
+				return nil, nil
+			}
+
+			eth_price = &EthPrice{}
+			err = __rows.Scan(&eth_price.IntervalStart, &eth_price.Price)
+			if err != nil {
+				return nil, err
+			}
+
+			return eth_price, nil
+		}()
+		if err != nil {
+			if obj.shouldRetry(err) {
+				continue
+			}
+			return nil, obj.makeErr(err)
+		}
+		return eth_price, nil
+	}
+
+}
+
 func (obj *pgxcockroachImpl) Update_Wallet_By_Id(ctx context.Context,
 	wallet_id Wallet_Id_Field,
 	update Wallet_Update_Fields) (
@@ -2407,14 +3954,42 @@ func (obj *pgxcockroachImpl) Delete_BlockHeader_By_ChainId_And_Hash(ctx context.
 
 }
 
-func (obj *pgxcockroachImpl) Delete_BlockHeader_By_Timestamp_Less(ctx context.Context,
+func (obj *pgxcockroachImpl) Delete_BlockHeader_By_ChainId_And_Number_Less(ctx context.Context,
+	block_header_chain_id BlockHeader_ChainId_Field,
+	block_header_number_less BlockHeader_Number_Field) (
+	count int64, err error) {
+
+	var __embed_stmt = __sqlbundle_Literal("DELETE FROM block_headers WHERE block_headers.chain_id = ? AND block_headers.number < ?")
+
+	var __values []any
+	__values = append(__values, block_header_chain_id.value(), block_header_number_less.value())
+
+	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
+	obj.logStmt(__stmt, __values...)
+
+	__res, err := obj.driver.ExecContext(ctx, __stmt, __values...)
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+
+	count, err = __res.RowsAffected()
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+
+	return count, nil
+
+}
+
+func (obj *pgxcockroachImpl) Delete_BlockHeader_By_ChainId_And_Timestamp_Less(ctx context.Context,
+	block_header_chain_id BlockHeader_ChainId_Field,
 	block_header_timestamp_less BlockHeader_Timestamp_Field) (
 	count int64, err error) {
 
-	var __embed_stmt = __sqlbundle_Literal("DELETE FROM block_headers WHERE block_headers.timestamp < ?")
+	var __embed_stmt = __sqlbundle_Literal("DELETE FROM block_headers WHERE block_headers.chain_id = ? AND block_headers.timestamp < ?")
 
 	var __values []any
-	__values = append(__values, block_header_timestamp_less.value())
+	__values = append(__values, block_header_chain_id.value(), block_header_timestamp_less.value())
 
 	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
 	obj.logStmt(__stmt, __values...)
@@ -2459,6 +4034,32 @@ func (obj *pgxcockroachImpl) Delete_TokenPrice_By_IntervalStart_Less(ctx context
 
 }
 
+func (obj *pgxcockroachImpl) Delete_EthPrice_By_IntervalStart_Less(ctx context.Context,
+	eth_price_interval_start_less EthPrice_IntervalStart_Field) (
+	count int64, err error) {
+
+	var __embed_stmt = __sqlbundle_Literal("DELETE FROM eth_prices WHERE eth_prices.interval_start < ?")
+
+	var __values []any
+	__values = append(__values, eth_price_interval_start_less.value())
+
+	var __stmt = __sqlbundle_Render(obj.dialect, __embed_stmt)
+	obj.logStmt(__stmt, __values...)
+
+	__res, err := obj.driver.ExecContext(ctx, __stmt, __values...)
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+
+	count, err = __res.RowsAffected()
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+
+	return count, nil
+
+}
+
 func (impl pgxcockroachImpl) isConstraintError(err error) (constraint string, ok bool) {
 	if e, ok := err.(*pgconn.PgError); ok {
 		if e.Code[:2] == "23" {
@@ -2476,6 +4077,16 @@ func (obj *pgxcockroachImpl) deleteAll(ctx context.Context) (count int64, err er
 		return 0, obj.makeErr(err)
 	}
 
+	__count, err = __res.RowsAffected()
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+	count += __count
+	__res, err = obj.driver.ExecContext(ctx, "DELETE FROM transfer_events;")
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+
 	__count, err = __res.RowsAffected()
 	if err != nil {
 		return 0, obj.makeErr(err)
@@ -2486,6 +4097,46 @@ func (obj *pgxcockroachImpl) deleteAll(ctx context.Context) (count int64, err er
 		return 0, obj.makeErr(err)
 	}
 
+	__count, err = __res.RowsAffected()
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+	count += __count
+	__res, err = obj.driver.ExecContext(ctx, "DELETE FROM scan_watermarks;")
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+
+	__count, err = __res.RowsAffected()
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+	count += __count
+	__res, err = obj.driver.ExecContext(ctx, "DELETE FROM reported_transfer_events;")
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+
+	__count, err = __res.RowsAffected()
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+	count += __count
+	__res, err = obj.driver.ExecContext(ctx, "DELETE FROM indexed_blocks;")
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+
+	__count, err = __res.RowsAffected()
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+	count += __count
+	__res, err = obj.driver.ExecContext(ctx, "DELETE FROM eth_prices;")
+	if err != nil {
+		return 0, obj.makeErr(err)
+	}
+
 	__count, err = __res.RowsAffected()
 	if err != nil {
 		return 0, obj.makeErr(err)
@@ -2507,8 +4158,16 @@ func (obj *pgxcockroachImpl) deleteAll(ctx context.Context) (count int64, err er
 }
 
 type Methods interface {
-	All_BlockHeader_OrderBy_Desc_Timestamp(ctx context.Context) (
-		rows []*BlockHeader, err error)
+	All_ReportedTransferEvent_By_Satellite_And_ChainId_And_BlockNumber_GreaterOrEqual_And_Removed_OrderBy_Asc_BlockNumber_Asc_LogIndex(ctx context.Context,
+		reported_transfer_event_satellite ReportedTransferEvent_Satellite_Field,
+		reported_transfer_event_chain_id ReportedTransferEvent_ChainId_Field,
+		reported_transfer_event_block_number_greater_or_equal ReportedTransferEvent_BlockNumber_Field,
+		reported_transfer_event_removed ReportedTransferEvent_Removed_Field) (
+		rows []*ReportedTransferEvent, err error)
+
+	All_ScanWatermark_By_Satellite(ctx context.Context,
+		scan_watermark_satellite ScanWatermark_Satellite_Field) (
+		rows []*ScanWatermark, err error)
 
 	All_Wallet_By_Claimed_IsNot_Null(ctx context.Context) (
 		rows []*Wallet, err error)
@@ -2530,7 +4189,8 @@ type Methods interface {
 		block_header_chain_id BlockHeader_ChainId_Field,
 		block_header_hash BlockHeader_Hash_Field,
 		block_header_number BlockHeader_Number_Field,
-		block_header_timestamp BlockHeader_Timestamp_Field) (
+		block_header_timestamp BlockHeader_Timestamp_Field,
+		block_header_parent_hash BlockHeader_ParentHash_Field) (
 		block_header *BlockHeader, err error)
 
 	Create_Wallet(ctx context.Context,
@@ -2544,14 +4204,32 @@ type Methods interface {
 		block_header_hash BlockHeader_Hash_Field) (
 		deleted bool, err error)
 
-	Delete_BlockHeader_By_Timestamp_Less(ctx context.Context,
+	Delete_BlockHeader_By_ChainId_And_Number_Less(ctx context.Context,
+		block_header_chain_id BlockHeader_ChainId_Field,
+		block_header_number_less BlockHeader_Number_Field) (
+		count int64, err error)
+
+	Delete_BlockHeader_By_ChainId_And_Timestamp_Less(ctx context.Context,
+		block_header_chain_id BlockHeader_ChainId_Field,
 		block_header_timestamp_less BlockHeader_Timestamp_Field) (
 		count int64, err error)
 
+	Delete_EthPrice_By_IntervalStart_Less(ctx context.Context,
+		eth_price_interval_start_less EthPrice_IntervalStart_Field) (
+		count int64, err error)
+
 	Delete_TokenPrice_By_IntervalStart_Less(ctx context.Context,
 		token_price_interval_start_less TokenPrice_IntervalStart_Field) (
 		count int64, err error)
 
+	First_BlockHeader_By_ChainId_OrderBy_Desc_Number(ctx context.Context,
+		block_header_chain_id BlockHeader_ChainId_Field) (
+		block_header *BlockHeader, err error)
+
+	First_EthPrice_By_IntervalStart_Less_OrderBy_Desc_IntervalStart(ctx context.Context,
+		eth_price_interval_start_less EthPrice_IntervalStart_Field) (
+		eth_price *EthPrice, err error)
+
 	First_TokenPrice_By_IntervalStart_Less_OrderBy_Desc_IntervalStart(ctx context.Context,
 		token_price_interval_start_less TokenPrice_IntervalStart_Field) (
 		token_price *TokenPrice, err error)
@@ -2570,6 +4248,10 @@ type Methods interface {
 		block_header_number BlockHeader_Number_Field) (
 		block_header *BlockHeader, err error)
 
+	Get_IndexedBlock_By_ChainId(ctx context.Context,
+		indexed_block_chain_id IndexedBlock_ChainId_Field) (
+		indexed_block *IndexedBlock, err error)
+
 	Get_TokenPrice_By_IntervalStart(ctx context.Context,
 		token_price_interval_start TokenPrice_IntervalStart_Field) (
 		token_price *TokenPrice, err error)
@@ -2579,11 +4261,58 @@ type Methods interface {
 		wallet_satellite Wallet_Satellite_Field) (
 		wallet *Wallet, err error)
 
+	ReplaceNoReturn_EthPrice(ctx context.Context,
+		eth_price_interval_start EthPrice_IntervalStart_Field,
+		eth_price_price EthPrice_Price_Field) (
+		err error)
+
+	ReplaceNoReturn_IndexedBlock(ctx context.Context,
+		indexed_block_chain_id IndexedBlock_ChainId_Field,
+		indexed_block_hash IndexedBlock_Hash_Field,
+		indexed_block_number IndexedBlock_Number_Field,
+		indexed_block_timestamp IndexedBlock_Timestamp_Field) (
+		err error)
+
+	ReplaceNoReturn_ReportedTransferEvent(ctx context.Context,
+		reported_transfer_event_satellite ReportedTransferEvent_Satellite_Field,
+		reported_transfer_event_chain_id ReportedTransferEvent_ChainId_Field,
+		reported_transfer_event_block_hash ReportedTransferEvent_BlockHash_Field,
+		reported_transfer_event_block_number ReportedTransferEvent_BlockNumber_Field,
+		reported_transfer_event_transaction ReportedTransferEvent_Transaction_Field,
+		reported_transfer_event_log_index ReportedTransferEvent_LogIndex_Field,
+		reported_transfer_event_trace_index ReportedTransferEvent_TraceIndex_Field,
+		reported_transfer_event_from_address ReportedTransferEvent_FromAddress_Field,
+		reported_transfer_event_to_address ReportedTransferEvent_ToAddress_Field,
+		reported_transfer_event_token_value ReportedTransferEvent_TokenValue_Field,
+		reported_transfer_event_contract ReportedTransferEvent_Contract_Field,
+		reported_transfer_event_removed ReportedTransferEvent_Removed_Field,
+		reported_transfer_event_removed_block ReportedTransferEvent_RemovedBlock_Field) (
+		err error)
+
+	ReplaceNoReturn_ScanWatermark(ctx context.Context,
+		scan_watermark_satellite ScanWatermark_Satellite_Field,
+		scan_watermark_chain_id ScanWatermark_ChainId_Field,
+		scan_watermark_block_number ScanWatermark_BlockNumber_Field) (
+		err error)
+
 	ReplaceNoReturn_TokenPrice(ctx context.Context,
 		token_price_interval_start TokenPrice_IntervalStart_Field,
 		token_price_price TokenPrice_Price_Field) (
 		err error)
 
+	ReplaceNoReturn_TransferEvent(ctx context.Context,
+		transfer_event_chain_id TransferEvent_ChainId_Field,
+		transfer_event_block_hash TransferEvent_BlockHash_Field,
+		transfer_event_block_number TransferEvent_BlockNumber_Field,
+		transfer_event_transaction TransferEvent_Transaction_Field,
+		transfer_event_log_index TransferEvent_LogIndex_Field,
+		transfer_event_trace_index TransferEvent_TraceIndex_Field,
+		transfer_event_from_address TransferEvent_FromAddress_Field,
+		transfer_event_to_address TransferEvent_ToAddress_Field,
+		transfer_event_token_value TransferEvent_TokenValue_Field,
+		transfer_event_contract TransferEvent_Contract_Field) (
+		err error)
+
 	Update_Wallet_By_Id(ctx context.Context,
 		wallet_id Wallet_Id_Field,
 		update Wallet_Update_Fields) (
@@ -5,14 +5,64 @@ CREATE TABLE block_headers (
 	hash bytea NOT NULL,
 	number bigint NOT NULL,
 	timestamp timestamp with time zone NOT NULL,
+	parent_hash bytea NOT NULL,
 	created_at timestamp with time zone NOT NULL DEFAULT current_timestamp,
 	PRIMARY KEY ( chain_id, hash )
 ) ;
+CREATE TABLE eth_prices (
+	interval_start timestamp with time zone NOT NULL,
+	price bigint NOT NULL,
+	PRIMARY KEY ( interval_start )
+) ;
+CREATE TABLE indexed_blocks (
+	chain_id bigint NOT NULL,
+	hash bytea NOT NULL,
+	number bigint NOT NULL,
+	timestamp timestamp with time zone NOT NULL,
+	PRIMARY KEY ( chain_id )
+) ;
+CREATE TABLE reported_transfer_events (
+	satellite text NOT NULL,
+	chain_id bigint NOT NULL,
+	block_hash bytea NOT NULL,
+	block_number bigint NOT NULL,
+	transaction bytea NOT NULL,
+	log_index integer NOT NULL,
+	trace_index integer NOT NULL,
+	from_address bytea NOT NULL,
+	to_address bytea NOT NULL,
+	token_value bytea NOT NULL,
+	contract bytea NOT NULL,
+	removed boolean NOT NULL,
+	removed_block bigint NOT NULL,
+	created_at timestamp with time zone NOT NULL DEFAULT current_timestamp,
+	PRIMARY KEY ( satellite, chain_id, block_hash, log_index, trace_index )
+) ;
+CREATE TABLE scan_watermarks (
+	satellite text NOT NULL,
+	chain_id bigint NOT NULL,
+	block_number bigint NOT NULL,
+	PRIMARY KEY ( satellite, chain_id )
+) ;
 CREATE TABLE token_prices (
 	interval_start timestamp with time zone NOT NULL,
 	price bigint NOT NULL,
 	PRIMARY KEY ( interval_start )
 ) ;
+CREATE TABLE transfer_events (
+	chain_id bigint NOT NULL,
+	block_hash bytea NOT NULL,
+	block_number bigint NOT NULL,
+	transaction bytea NOT NULL,
+	log_index integer NOT NULL,
+	trace_index integer NOT NULL,
+	from_address bytea NOT NULL,
+	to_address bytea NOT NULL,
+	token_value bytea NOT NULL,
+	contract bytea NOT NULL,
+	created_at timestamp with time zone NOT NULL DEFAULT current_timestamp,
+	PRIMARY KEY ( chain_id, block_hash, log_index, trace_index )
+) ;
 CREATE TABLE wallets (
 	id bigserial NOT NULL,
 	address bytea NOT NULL,
@@ -22,5 +72,8 @@ CREATE TABLE wallets (
 	created_at timestamp with time zone NOT NULL DEFAULT current_timestamp,
 	PRIMARY KEY ( id )
 ) ;
+CREATE INDEX reported_transfer_events_chain_id_block_number_index ON reported_transfer_events ( chain_id, block_number ) ;
+CREATE INDEX transfer_events_to_address_block_number_index ON transfer_events ( to_address, block_number ) ;
+CREATE INDEX transfer_events_from_address_block_number_index ON transfer_events ( from_address, block_number ) ;
 CREATE INDEX wallets_satellite_index ON wallets ( satellite ) ;
 CREATE UNIQUE INDEX wallets_address_unique_index ON wallets ( address )
@@ -0,0 +1,59 @@
+// Copyright (C) 2024 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package storjscandb
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/common/currency"
+	"storj.io/storjscan/storjscandb/dbx"
+	"storj.io/storjscan/tokenprice"
+)
+
+// ErrETHPriceDB indicates about internal ETH price DB error.
+var ErrETHPriceDB = errs.Class("ETHPriceDB")
+
+// ensures that ethPriceDB implements tokenprice.PriceQuoteDB.
+var _ tokenprice.PriceQuoteDB = (*ethPriceDB)(nil)
+
+// ethPriceDB provides access to the database that stores ETH price information.
+//
+// architecture: Database
+type ethPriceDB struct {
+	db *dbx.DB
+}
+
+// Update updates the stored ETH price for the given time window, or creates a new entry if it does not exist.
+func (ethPriceDB *ethPriceDB) Update(ctx context.Context, window time.Time, price int64) (err error) {
+	defer mon.Task()(&ctx)(&err)
+	err = ethPriceDB.db.ReplaceNoReturn_EthPrice(ctx, dbx.EthPrice_IntervalStart(window.UTC()), dbx.EthPrice_Price(price))
+	return ErrETHPriceDB.Wrap(err)
+}
+
+// Before gets the first ETH price with timestamp before provided timestamp.
+func (ethPriceDB *ethPriceDB) Before(ctx context.Context, before time.Time) (_ tokenprice.PriceQuote, err error) {
+	defer mon.Task()(&ctx)(&err)
+	row, err := ethPriceDB.db.First_EthPrice_By_IntervalStart_Less_OrderBy_Desc_IntervalStart(ctx,
+		dbx.EthPrice_IntervalStart(before.UTC()))
+	if err != nil {
+		return tokenprice.PriceQuote{}, ErrETHPriceDB.Wrap(err)
+	}
+	if row == nil {
+		return tokenprice.PriceQuote{}, tokenprice.ErrNoQuotes
+	}
+	return tokenprice.PriceQuote{
+		Timestamp: row.IntervalStart.UTC(),
+		Price:     currency.AmountFromBaseUnits(row.Price, currency.USDollarsMicro),
+	}, nil
+}
+
+// DeleteBefore deletes ETH prices before the given time.
+func (ethPriceDB *ethPriceDB) DeleteBefore(ctx context.Context, before time.Time) (err error) {
+	defer mon.Task()(&ctx)(&err)
+	_, err = ethPriceDB.db.Delete_EthPrice_By_IntervalStart_Less(ctx, dbx.EthPrice_IntervalStart(before.UTC()))
+	return ErrETHPriceDB.Wrap(err)
+}
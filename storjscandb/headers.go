@@ -6,6 +6,7 @@ package storjscandb
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"time"
 
 	"github.com/zeebo/errs"
@@ -37,11 +38,36 @@ func (headers *headersDB) Insert(ctx context.Context, header blockchain.Header)
 		dbx.BlockHeader_ChainId(header.ChainID),
 		dbx.BlockHeader_Hash(header.Hash.Bytes()),
 		dbx.BlockHeader_Number(header.Number),
-		dbx.BlockHeader_Timestamp(header.Timestamp.UTC()))
+		dbx.BlockHeader_Timestamp(header.Timestamp.UTC()),
+		dbx.BlockHeader_ParentHash(header.ParentHash.Bytes()))
 
 	return ErrHeadersDB.Wrap(err)
 }
 
+// InsertMany inserts the block headers into db in bulk, skipping the headers already in it.
+func (headers *headersDB) InsertMany(ctx context.Context, list []blockchain.Header) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if len(list) == 0 {
+		return nil
+	}
+
+	values := make([]string, 0, len(list))
+	args := make([]any, 0, 5*len(list))
+	for _, header := range list {
+		if header.ChainID == 0 {
+			return ErrHeadersDB.New("invalid chainID 0 specified")
+		}
+		values = append(values, "(?, ?, ?, ?, ?)")
+		args = append(args, header.ChainID, header.Hash.Bytes(), header.Number, header.Timestamp.UTC(), header.ParentHash.Bytes())
+	}
+
+	query := `INSERT INTO block_headers (chain_id, hash, number, timestamp, parent_hash) VALUES ` + strings.Join(values, ", ") + `
+		ON CONFLICT DO NOTHING`
+	_, err = headers.db.ExecContext(ctx, headers.db.Rebind(query), args...)
+	return ErrHeadersDB.Wrap(err)
+}
+
 // Delete deletes block header from the db by block hash.
 func (headers *headersDB) Delete(ctx context.Context, chainID int64, hash common.Hash) error {
 	if chainID == 0 {
@@ -59,9 +85,19 @@ func (headers *headersDB) Delete(ctx context.Context, chainID int64, hash common
 	return nil
 }
 
-// DeleteBefore deletes headers before the given time.
-func (headers *headersDB) DeleteBefore(ctx context.Context, before time.Time) (err error) {
-	_, err = headers.db.Delete_BlockHeader_By_Timestamp_Less(ctx, dbx.BlockHeader_Timestamp(before.UTC()))
+// DeleteBefore deletes headers of the chain before the given time.
+func (headers *headersDB) DeleteBefore(ctx context.Context, chainID int64, before time.Time) (err error) {
+	_, err = headers.db.Delete_BlockHeader_By_ChainId_And_Timestamp_Less(ctx,
+		dbx.BlockHeader_ChainId(chainID),
+		dbx.BlockHeader_Timestamp(before.UTC()))
+	return ErrHeadersDB.Wrap(err)
+}
+
+// DeleteBeforeNumber deletes headers of the chain with a number lower than before.
+func (headers *headersDB) DeleteBeforeNumber(ctx context.Context, chainID int64, before int64) (err error) {
+	_, err = headers.db.Delete_BlockHeader_By_ChainId_And_Number_Less(ctx,
+		dbx.BlockHeader_ChainId(chainID),
+		dbx.BlockHeader_Number(before))
 	return ErrHeadersDB.Wrap(err)
 }
 
@@ -103,27 +139,97 @@ func (headers *headersDB) GetByNumber(ctx context.Context, chainID int64, number
 	return fromDBXHeader(dbxHeader), nil
 }
 
-// List retrieves all block headers from the db.
-func (headers *headersDB) List(ctx context.Context) ([]blockchain.Header, error) {
-	dbxHeaders, err := headers.db.All_BlockHeader_OrderBy_Desc_Timestamp(ctx)
+// GetLatest retrieves the block header of the chain with the highest number.
+func (headers *headersDB) GetLatest(ctx context.Context, chainID int64) (blockchain.Header, error) {
+	dbxHeader, err := headers.db.First_BlockHeader_By_ChainId_OrderBy_Desc_Number(ctx, dbx.BlockHeader_ChainId(chainID))
 	if err != nil {
-		return nil, ErrHeadersDB.Wrap(err)
+		return blockchain.Header{}, ErrHeadersDB.Wrap(err)
+	}
+	if dbxHeader == nil {
+		return blockchain.Header{}, blockchain.ErrNoHeader
+	}
+	return fromDBXHeader(dbxHeader), nil
+}
+
+// List retrieves a page of at most limit block headers of the chain, ordered by number and hash, after the cursor or
+// from the first header if the cursor is nil.
+func (headers *headersDB) List(ctx context.Context, chainID int64, cursor *blockchain.HeadersCursor, limit int) (_ blockchain.HeadersPage, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if limit <= 0 {
+		return blockchain.HeadersPage{}, ErrHeadersDB.New("invalid limit %d specified", limit)
+	}
+
+	query := `
+		SELECT chain_id, hash, number, timestamp, parent_hash
+		FROM block_headers
+		WHERE chain_id = ?`
+	args := []any{chainID}
+	if cursor != nil {
+		query += ` AND (number, hash) > (?, ?)`
+		args = append(args, cursor.Number, cursor.Hash.Bytes())
 	}
+	// one more header is read to know whether there is a next page
+	query += `
+		ORDER BY number, hash
+		LIMIT ?`
+	args = append(args, limit+1)
 
-	var list []blockchain.Header
-	for _, dbxHeader := range dbxHeaders {
-		list = append(list, fromDBXHeader(dbxHeader))
+	rows, err := headers.db.QueryContext(ctx, headers.db.Rebind(query), args...)
+	if err != nil {
+		return blockchain.HeadersPage{}, ErrHeadersDB.Wrap(err)
+	}
+	defer func() { err = errs.Combine(err, ErrHeadersDB.Wrap(rows.Close())) }()
+
+	var page blockchain.HeadersPage
+	for rows.Next() {
+		var dbxHeader dbx.BlockHeader
+		err = rows.Scan(&dbxHeader.ChainId, &dbxHeader.Hash, &dbxHeader.Number, &dbxHeader.Timestamp, &dbxHeader.ParentHash)
+		if err != nil {
+			return blockchain.HeadersPage{}, ErrHeadersDB.Wrap(err)
+		}
+		page.Headers = append(page.Headers, fromDBXHeader(&dbxHeader))
+	}
+	if err = rows.Err(); err != nil {
+		return blockchain.HeadersPage{}, ErrHeadersDB.Wrap(err)
 	}
 
-	return list, nil
+	if len(page.Headers) > limit {
+		page.Headers = page.Headers[:limit]
+		last := page.Headers[limit-1]
+		page.Next = &blockchain.HeadersCursor{Number: last.Number, Hash: last.Hash}
+	}
+	return page, nil
+}
+
+// ListChainIDs retrieves the IDs of the chains with block headers in the db.
+func (headers *headersDB) ListChainIDs(ctx context.Context) (_ []int64, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	rows, err := headers.db.QueryContext(ctx, `SELECT DISTINCT chain_id FROM block_headers ORDER BY chain_id`)
+	if err != nil {
+		return nil, ErrHeadersDB.Wrap(err)
+	}
+	defer func() { err = errs.Combine(err, ErrHeadersDB.Wrap(rows.Close())) }()
+
+	var chainIDs []int64
+	for rows.Next() {
+		var chainID int64
+		if err = rows.Scan(&chainID); err != nil {
+			return nil, ErrHeadersDB.Wrap(err)
+		}
+		chainIDs = append(chainIDs, chainID)
+	}
+	return chainIDs, ErrHeadersDB.Wrap(rows.Err())
 }
 
 // fromDBXHeader converts dbx block header to blockchain.Header type.
 func fromDBXHeader(dbxHeader *dbx.BlockHeader) blockchain.Header {
 	return blockchain.Header{
-		ChainID:   dbxHeader.ChainId,
-		Hash:      common.HashFromBytes(dbxHeader.Hash),
-		Number:    dbxHeader.Number,
-		Timestamp: dbxHeader.Timestamp.UTC(),
+		ChainID:    dbxHeader.ChainId,
+		Hash:       common.HashFromBytes(dbxHeader.Hash),
+		Number:     dbxHeader.Number,
+		Timestamp:  dbxHeader.Timestamp.UTC(),
+		ParentHash: common.HashFromBytes(dbxHeader.ParentHash),
 	}
 }
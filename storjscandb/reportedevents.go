@@ -0,0 +1,146 @@
+// Copyright (C) 2024 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package storjscandb
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/common/currency"
+	"storj.io/storjscan/blockchain/events"
+	"storj.io/storjscan/common"
+	"storj.io/storjscan/storjscandb/dbx"
+)
+
+// ErrReportedEventsDB indicates about internal reported transfer events DB error.
+var ErrReportedEventsDB = errs.Class("ReportedEventsDB")
+
+// ensures that reportedEventsDB implements events.ReportedDB.
+var _ events.ReportedDB = (*reportedEventsDB)(nil)
+
+// reportedEventsDB contains access to the transfer events reported to the satellites.
+//
+// architecture: Database
+type reportedEventsDB struct {
+	db *dbx.DB
+}
+
+// Insert records transfer events reported to the satellite, reviving them if they were removed before.
+func (reportedDB *reportedEventsDB) Insert(ctx context.Context, satellite string, transferEvents []events.TransferEvent) (err error) {
+	defer mon.Task()(&ctx)(&err)
+	if len(transferEvents) == 0 {
+		return nil
+	}
+	err = reportedDB.db.WithTx(ctx, func(ctx context.Context, tx *dbx.Tx) error {
+		for _, event := range transferEvents {
+			err := tx.ReplaceNoReturn_ReportedTransferEvent(ctx,
+				dbx.ReportedTransferEvent_Satellite(satellite),
+				dbx.ReportedTransferEvent_ChainId(event.ChainID),
+				dbx.ReportedTransferEvent_BlockHash(event.BlockHash.Bytes()),
+				dbx.ReportedTransferEvent_BlockNumber(event.BlockNumber),
+				dbx.ReportedTransferEvent_Transaction(event.TxHash.Bytes()),
+				dbx.ReportedTransferEvent_LogIndex(event.LogIndex),
+				dbx.ReportedTransferEvent_TraceIndex(event.TraceIndex),
+				dbx.ReportedTransferEvent_FromAddress(event.From.Bytes()),
+				dbx.ReportedTransferEvent_ToAddress(event.To.Bytes()),
+				dbx.ReportedTransferEvent_TokenValue(event.TokenValue.BaseUnitsBig().Bytes()),
+				dbx.ReportedTransferEvent_Contract(event.Contract.Bytes()),
+				dbx.ReportedTransferEvent_Removed(false),
+				dbx.ReportedTransferEvent_RemovedBlock(0))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return ErrReportedEventsDB.Wrap(err)
+}
+
+// List returns the transfer events of the chain reported to the satellite from the given block number, which are not
+// removed.
+func (reportedDB *reportedEventsDB) List(ctx context.Context, satellite string, chainID, from int64) (_ []events.TransferEvent, err error) {
+	defer mon.Task()(&ctx)(&err)
+	list, err := reportedDB.list(ctx, satellite, chainID, from, false)
+	return list, ErrReportedEventsDB.Wrap(err)
+}
+
+// ListRemoved returns the removed transfer events of the chain reported to the satellite, whatever their block number.
+func (reportedDB *reportedEventsDB) ListRemoved(ctx context.Context, satellite string, chainID int64) (_ []events.TransferEvent, err error) {
+	defer mon.Task()(&ctx)(&err)
+	list, err := reportedDB.list(ctx, satellite, chainID, 0, true)
+	return list, ErrReportedEventsDB.Wrap(err)
+}
+
+// list returns the removed or not removed transfer events of the chain reported to the satellite from the given
+// block number.
+func (reportedDB *reportedEventsDB) list(ctx context.Context, satellite string, chainID, from int64, removed bool) (_ []events.TransferEvent, err error) {
+	dbxEvents, err := reportedDB.db.All_ReportedTransferEvent_By_Satellite_And_ChainId_And_BlockNumber_GreaterOrEqual_And_Removed_OrderBy_Asc_BlockNumber_Asc_LogIndex(ctx,
+		dbx.ReportedTransferEvent_Satellite(satellite),
+		dbx.ReportedTransferEvent_ChainId(chainID),
+		dbx.ReportedTransferEvent_BlockNumber(from),
+		dbx.ReportedTransferEvent_Removed(removed))
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]events.TransferEvent, 0, len(dbxEvents))
+	for _, dbxEvent := range dbxEvents {
+		event, err := fromDBXReportedTransferEvent(dbxEvent)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, event)
+	}
+	return list, nil
+}
+
+// Remove marks the transfer events of the block reported to the satellite as removed at the given latest block
+// number of the chain.
+func (reportedDB *reportedEventsDB) Remove(ctx context.Context, satellite string, chainID int64, blockHash common.Hash, latest int64) (err error) {
+	defer mon.Task()(&ctx)(&err)
+	_, err = reportedDB.db.ExecContext(ctx, reportedDB.db.Rebind(`
+		UPDATE reported_transfer_events SET removed = true, removed_block = ?
+		WHERE satellite = ? AND chain_id = ? AND block_hash = ? AND NOT removed`),
+		latest, satellite, chainID, blockHash.Bytes())
+	return ErrReportedEventsDB.Wrap(err)
+}
+
+// DeleteBefore deletes the reported transfer events of the chain before the given block number, and the removed ones
+// which were removed before it.
+func (reportedDB *reportedEventsDB) DeleteBefore(ctx context.Context, chainID, before int64) (err error) {
+	defer mon.Task()(&ctx)(&err)
+	_, err = reportedDB.db.ExecContext(ctx, reportedDB.db.Rebind(`
+		DELETE FROM reported_transfer_events
+		WHERE chain_id = ? AND ((NOT removed AND block_number < ?) OR (removed AND removed_block < ?))`),
+		chainID, before, before)
+	return ErrReportedEventsDB.Wrap(err)
+}
+
+// fromDBXReportedTransferEvent converts dbx reported transfer event to events.TransferEvent type.
+func fromDBXReportedTransferEvent(dbxEvent *dbx.ReportedTransferEvent) (_ events.TransferEvent, err error) {
+	event := events.TransferEvent{
+		ChainID:     dbxEvent.ChainId,
+		BlockHash:   common.HashFromBytes(dbxEvent.BlockHash),
+		BlockNumber: dbxEvent.BlockNumber,
+		TxHash:      common.HashFromBytes(dbxEvent.Transaction),
+		LogIndex:    dbxEvent.LogIndex,
+		TraceIndex:  dbxEvent.TraceIndex,
+		TokenValue:  common.TokenAmountFromBig(new(big.Int).SetBytes(dbxEvent.TokenValue), currency.StorjToken),
+	}
+	if event.From, err = common.AddressFromBytes(dbxEvent.FromAddress); err != nil {
+		return events.TransferEvent{}, err
+	}
+	if event.To, err = common.AddressFromBytes(dbxEvent.ToAddress); err != nil {
+		return events.TransferEvent{}, err
+	}
+	// events reported before the token contract was tracked don't have one
+	if len(dbxEvent.Contract) > 0 {
+		if event.Contract, err = common.AddressFromBytes(dbxEvent.Contract); err != nil {
+			return events.TransferEvent{}, err
+		}
+	}
+	return event, nil
+}
@@ -0,0 +1,61 @@
+// Copyright (C) 2024 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package storjscandb
+
+import (
+	"context"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/storjscan/blockchain/events"
+	"storj.io/storjscan/storjscandb/dbx"
+)
+
+// ErrScanWatermarksDB indicates about internal scan watermarks DB error.
+var ErrScanWatermarksDB = errs.Class("ScanWatermarksDB")
+
+// ensures that scanWatermarksDB implements events.WatermarksDB.
+var _ events.WatermarksDB = (*scanWatermarksDB)(nil)
+
+// scanWatermarksDB contains access to the per satellite scan watermarks.
+//
+// architecture: Database
+type scanWatermarksDB struct {
+	db *dbx.DB
+}
+
+// Get returns the scan watermarks of the satellite, keyed by chain id.
+func (watermarksDB *scanWatermarksDB) Get(ctx context.Context, satellite string) (_ map[int64]int64, err error) {
+	defer mon.Task()(&ctx)(&err)
+	dbxWatermarks, err := watermarksDB.db.All_ScanWatermark_By_Satellite(ctx, dbx.ScanWatermark_Satellite(satellite))
+	if err != nil {
+		return nil, ErrScanWatermarksDB.Wrap(err)
+	}
+	watermarks := make(map[int64]int64, len(dbxWatermarks))
+	for _, dbxWatermark := range dbxWatermarks {
+		watermarks[dbxWatermark.ChainId] = dbxWatermark.BlockNumber
+	}
+	return watermarks, nil
+}
+
+// Set stores the scan watermarks of the satellite, keyed by chain id.
+func (watermarksDB *scanWatermarksDB) Set(ctx context.Context, satellite string, watermarks map[int64]int64) (err error) {
+	defer mon.Task()(&ctx)(&err)
+	if len(watermarks) == 0 {
+		return nil
+	}
+	err = watermarksDB.db.WithTx(ctx, func(ctx context.Context, tx *dbx.Tx) error {
+		for chainID, blockNumber := range watermarks {
+			err := tx.ReplaceNoReturn_ScanWatermark(ctx,
+				dbx.ScanWatermark_Satellite(satellite),
+				dbx.ScanWatermark_ChainId(chainID),
+				dbx.ScanWatermark_BlockNumber(blockNumber))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return ErrScanWatermarksDB.Wrap(err)
+}
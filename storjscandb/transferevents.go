@@ -0,0 +1,185 @@
+// Copyright (C) 2024 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package storjscandb
+
+import (
+	"context"
+	"database/sql"
+	"math/big"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/common/currency"
+	"storj.io/storj/shared/dbutil/pgutil"
+	"storj.io/storj/shared/tagsql"
+	"storj.io/storjscan/blockchain"
+	"storj.io/storjscan/blockchain/events"
+	"storj.io/storjscan/common"
+	"storj.io/storjscan/storjscandb/dbx"
+)
+
+// ErrTransferEventsDB indicates about internal transfer events DB error.
+var ErrTransferEventsDB = errs.Class("TransferEventsDB")
+
+// ensures that transferEventsDB implements events.DB.
+var _ events.DB = (*transferEventsDB)(nil)
+
+// transferEventsDB contains access to the transfer events ledger.
+//
+// architecture: Database
+type transferEventsDB struct {
+	db *dbx.DB
+}
+
+// Replace replaces the transfer events of the chain within the given block range (inclusive), dropping the
+// events of blocks which are no longer part of the chain.
+func (ledger *transferEventsDB) Replace(ctx context.Context, chainID, from, to int64, transferEvents []events.TransferEvent) (err error) {
+	defer mon.Task()(&ctx)(&err)
+	err = ledger.db.WithTx(ctx, func(ctx context.Context, tx *dbx.Tx) error {
+		_, err := tx.Tx.ExecContext(ctx, tx.Rebind(`
+			DELETE FROM transfer_events
+			WHERE chain_id = ? AND block_number >= ? AND block_number <= ?`),
+			chainID, from, to)
+		if err != nil {
+			return err
+		}
+		for _, event := range transferEvents {
+			err := tx.ReplaceNoReturn_TransferEvent(ctx,
+				dbx.TransferEvent_ChainId(event.ChainID),
+				dbx.TransferEvent_BlockHash(event.BlockHash.Bytes()),
+				dbx.TransferEvent_BlockNumber(event.BlockNumber),
+				dbx.TransferEvent_Transaction(event.TxHash.Bytes()),
+				dbx.TransferEvent_LogIndex(event.LogIndex),
+				dbx.TransferEvent_TraceIndex(event.TraceIndex),
+				dbx.TransferEvent_FromAddress(event.From.Bytes()),
+				dbx.TransferEvent_ToAddress(event.To.Bytes()),
+				dbx.TransferEvent_TokenValue(event.TokenValue.BaseUnitsBig().Bytes()),
+				dbx.TransferEvent_Contract(event.Contract.Bytes()))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return ErrTransferEventsDB.Wrap(err)
+}
+
+// ListBySatellite returns transfer events to wallets claimed by the satellite, or from them if outgoing is set,
+// within the given block range (inclusive).
+func (ledger *transferEventsDB) ListBySatellite(ctx context.Context, satellite string, chainID, from, to int64, outgoing bool) (_ []events.TransferEvent, err error) {
+	defer mon.Task()(&ctx)(&err)
+	rows, err := ledger.db.QueryContext(ctx, ledger.db.Rebind(`
+		SELECT te.chain_id, te.block_hash, te.block_number, te.transaction, te.log_index, te.trace_index, te.from_address, te.to_address, te.token_value, te.contract
+		FROM transfer_events te
+		JOIN wallets w ON w.address = te.`+walletColumn(outgoing)+`
+		WHERE w.satellite = ? AND w.claimed IS NOT NULL
+			AND te.chain_id = ? AND te.block_number >= ? AND te.block_number <= ?
+		ORDER BY te.block_number, te.log_index, te.trace_index`),
+		satellite, chainID, from, to)
+	if err != nil {
+		return nil, ErrTransferEventsDB.Wrap(err)
+	}
+	list, err := scanTransferEvents(rows, outgoing)
+	return list, ErrTransferEventsDB.Wrap(err)
+}
+
+// ListByAddress returns transfer events to the given addresses, or from them if outgoing is set,
+// within the given block range (inclusive).
+func (ledger *transferEventsDB) ListByAddress(ctx context.Context, addresses []common.Address, chainID, from, to int64, outgoing bool) (_ []events.TransferEvent, err error) {
+	defer mon.Task()(&ctx)(&err)
+	if len(addresses) == 0 {
+		return nil, nil
+	}
+	addressBytes := make([][]byte, 0, len(addresses))
+	for _, address := range addresses {
+		addressBytes = append(addressBytes, address.Bytes())
+	}
+	rows, err := ledger.db.QueryContext(ctx, ledger.db.Rebind(`
+		SELECT chain_id, block_hash, block_number, transaction, log_index, trace_index, from_address, to_address, token_value, contract
+		FROM transfer_events
+		WHERE `+walletColumn(outgoing)+` = ANY(?)
+			AND chain_id = ? AND block_number >= ? AND block_number <= ?
+		ORDER BY block_number, log_index, trace_index`),
+		pgutil.ByteaArray(addressBytes), chainID, from, to)
+	if err != nil {
+		return nil, ErrTransferEventsDB.Wrap(err)
+	}
+	list, err := scanTransferEvents(rows, outgoing)
+	return list, ErrTransferEventsDB.Wrap(err)
+}
+
+// walletColumn returns the transfer events column holding the wallet address for the given direction.
+func walletColumn(outgoing bool) string {
+	if outgoing {
+		return "from_address"
+	}
+	return "to_address"
+}
+
+// GetIndexedBlock returns the header of the last block indexed for the chain.
+func (ledger *transferEventsDB) GetIndexedBlock(ctx context.Context, chainID int64) (_ blockchain.Header, err error) {
+	defer mon.Task()(&ctx)(&err)
+	dbxBlock, err := ledger.db.Get_IndexedBlock_By_ChainId(ctx, dbx.IndexedBlock_ChainId(chainID))
+	if err != nil {
+		if errs.Is(err, sql.ErrNoRows) {
+			return blockchain.Header{}, events.ErrNoIndexedBlock
+		}
+		return blockchain.Header{}, ErrTransferEventsDB.Wrap(err)
+	}
+	return blockchain.Header{
+		ChainID:   dbxBlock.ChainId,
+		Hash:      common.HashFromBytes(dbxBlock.Hash),
+		Number:    dbxBlock.Number,
+		Timestamp: dbxBlock.Timestamp.UTC(),
+	}, nil
+}
+
+// SetIndexedBlock stores the header of the last block indexed for its chain.
+func (ledger *transferEventsDB) SetIndexedBlock(ctx context.Context, header blockchain.Header) (err error) {
+	defer mon.Task()(&ctx)(&err)
+	if header.ChainID == 0 {
+		return ErrTransferEventsDB.New("invalid chainID 0 specified")
+	}
+	err = ledger.db.ReplaceNoReturn_IndexedBlock(ctx,
+		dbx.IndexedBlock_ChainId(header.ChainID),
+		dbx.IndexedBlock_Hash(header.Hash.Bytes()),
+		dbx.IndexedBlock_Number(header.Number),
+		dbx.IndexedBlock_Timestamp(header.Timestamp.UTC()))
+	return ErrTransferEventsDB.Wrap(err)
+}
+
+// scanTransferEvents reads transfer events of the given direction from the result rows and closes them.
+func scanTransferEvents(rows tagsql.Rows, outgoing bool) (_ []events.TransferEvent, err error) {
+	defer func() { err = errs.Combine(err, rows.Close()) }()
+
+	var list []events.TransferEvent
+	for rows.Next() {
+		var (
+			event                                     events.TransferEvent
+			blockHash, txHash, from, to, tv, contract []byte
+		)
+		err = rows.Scan(&event.ChainID, &blockHash, &event.BlockNumber, &txHash, &event.LogIndex, &event.TraceIndex, &from, &to, &tv, &contract)
+		if err != nil {
+			return nil, err
+		}
+		event.BlockHash = common.HashFromBytes(blockHash)
+		event.TxHash = common.HashFromBytes(txHash)
+		if event.From, err = common.AddressFromBytes(from); err != nil {
+			return nil, err
+		}
+		if event.To, err = common.AddressFromBytes(to); err != nil {
+			return nil, err
+		}
+		// events recorded before the token contract was tracked don't have one
+		if len(contract) > 0 {
+			if event.Contract, err = common.AddressFromBytes(contract); err != nil {
+				return nil, err
+			}
+		}
+		event.TokenValue = common.TokenAmountFromBig(new(big.Int).SetBytes(tv), currency.StorjToken)
+		event.Outgoing = outgoing
+		list = append(list, event)
+	}
+	return list, rows.Err()
+}
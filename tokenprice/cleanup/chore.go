@@ -19,27 +19,27 @@ var mon = monkit.Package()
 
 // Config is a configuration struct for the Chore.
 type Config struct {
-	Interval   time.Duration `help:"how often to remove old token prices" default:"336h" testDefault:"$TESTINTERVAL"`
-	RetainDays int           `help:"number of days of token prices to retain" default:"30"`
+	Interval   time.Duration `help:"how often to remove old token and ETH prices" default:"336h" testDefault:"$TESTINTERVAL"`
+	RetainDays int           `help:"number of days of token and ETH prices to retain" default:"30"`
 }
 
-// Chore to remove old token prices.
+// Chore to remove old token prices from each of the price databases, such as the STORJ and the ETH prices.
 //
 // architecture: Chore
 type Chore struct {
 	log    *zap.Logger
-	db     tokenprice.PriceQuoteDB
+	dbs    []tokenprice.PriceQuoteDB
 	config Config
 
 	Loop *sync2.Cycle
 }
 
-// NewChore creates new chore for removing old token prices.
-func NewChore(log *zap.Logger, db tokenprice.PriceQuoteDB, config Config) *Chore {
+// NewChore creates new chore for removing old token prices from the given databases.
+func NewChore(log *zap.Logger, dbs []tokenprice.PriceQuoteDB, config Config) *Chore {
 
 	return &Chore{
 		log:    log,
-		db:     db,
+		dbs:    dbs,
 		config: config,
 
 		Loop: sync2.NewCycle(config.Interval),
@@ -67,9 +67,11 @@ func (chore *Chore) RunOnce(ctx context.Context) (err error) {
 	}
 
 	beforeDays := time.Now().UTC().AddDate(0, 0, -chore.config.RetainDays)
-	err = chore.db.DeleteBefore(ctx, beforeDays)
-	if err != nil {
-		chore.log.Error("error removing old token prices", zap.Error(err))
+	for _, db := range chore.dbs {
+		err = db.DeleteBefore(ctx, beforeDays)
+		if err != nil {
+			chore.log.Error("error removing old token prices", zap.Error(err))
+		}
 	}
 
 	return nil
@@ -25,17 +25,20 @@ func TestChore(t *testing.T) {
 			currentTime.AddDate(0, 0, -31),
 			currentTime.AddDate(-1, 0, 0),
 		}
-		for _, date := range tokenPriceDates {
-			err := db.TokenPrice().Update(ctx, date, 1)
+		priceDBs := []tokenprice.PriceQuoteDB{db.TokenPrice(), db.ETHPrice()}
+		for _, priceDB := range priceDBs {
+			for _, date := range tokenPriceDates {
+				err := priceDB.Update(ctx, date, 1)
+				require.NoError(t, err)
+			}
+
+			// first price quote prior to 30 days should return the record 31 days ago
+			price, err := priceDB.Before(ctx, time.Now().AddDate(0, 0, -30))
 			require.NoError(t, err)
+			require.Equal(t, currentTime.AddDate(0, 0, -31), price.Timestamp.Local())
 		}
 
-		// first price quote prior to 30 days should return the record 31 days ago
-		price, err := db.TokenPrice().Before(ctx, time.Now().AddDate(0, 0, -30))
-		require.NoError(t, err)
-		require.Equal(t, currentTime.AddDate(0, 0, -31), price.Timestamp.Local())
-
-		chore := cleanup.NewChore(zaptest.NewLogger(t), db.TokenPrice(), cleanup.Config{
+		chore := cleanup.NewChore(zaptest.NewLogger(t), priceDBs, cleanup.Config{
 			Interval:   336 * time.Hour,
 			RetainDays: 30,
 		})
@@ -48,13 +51,15 @@ func TestChore(t *testing.T) {
 		chore.Loop.Pause()
 		chore.Loop.TriggerWait()
 
-		// after chore, all records 30 days ago or older should be gone.
-		price, err = db.TokenPrice().Before(ctx, time.Now().AddDate(0, 0, -30))
-		require.Equal(t, err, tokenprice.ErrNoQuotes)
-		require.Equal(t, tokenprice.PriceQuote{}, price)
-		// but record 29 days ago should still be present
-		price, err = db.TokenPrice().Before(ctx, time.Now().AddDate(0, 0, -28))
-		require.NoError(t, err)
-		require.Equal(t, currentTime.AddDate(0, 0, -29), price.Timestamp.Local())
+		for _, priceDB := range priceDBs {
+			// after chore, all records 30 days ago or older should be gone.
+			price, err := priceDB.Before(ctx, time.Now().AddDate(0, 0, -30))
+			require.Equal(t, err, tokenprice.ErrNoQuotes)
+			require.Equal(t, tokenprice.PriceQuote{}, price)
+			// but record 29 days ago should still be present
+			price, err = priceDB.Before(ctx, time.Now().AddDate(0, 0, -28))
+			require.NoError(t, err)
+			require.Equal(t, currentTime.AddDate(0, 0, -29), price.Timestamp.Local())
+		}
 	})
 }
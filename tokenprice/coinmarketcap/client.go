@@ -22,6 +22,8 @@ var ErrClient = errs.Class("Client")
 const (
 	// storjID is the permanent CoinMarketCap ID associated with STORJ token.
 	storjID = "1772"
+	// ethID is the permanent CoinMarketCap ID associated with ETH.
+	ethID = "1027"
 	// usdSymbol is the ticker symbol for U.S. Dollars.
 	usdSymbol = "USD"
 )
@@ -33,22 +35,33 @@ type Config struct {
 	Timeout time.Duration `help:"coinmarketcap API response timeout" default:"10s" testDefault:"$TESTTIMEOUT"`
 }
 
-// Client is used to query the coinmarketcap API for the STORJ token price.
+// Client is used to query the coinmarketcap API for the STORJ token price, or the price of another cryptocurrency.
 // implements tokenprice.Client interface.
 type Client struct {
 	httpClient *http.Client
 	baseURL    string
 	apiKey     string
+	id         string
 }
 
 // NewClient returns a new token price client.
 func NewClient(config Config) *Client {
+	return newClient(config, storjID)
+}
+
+// NewETHClient returns a new price client for ETH.
+func NewETHClient(config Config) *Client {
+	return newClient(config, ethID)
+}
+
+func newClient(config Config, id string) *Client {
 	return &Client{
 		httpClient: &http.Client{
 			Timeout: config.Timeout,
 		},
 		baseURL: config.BaseURL,
 		apiKey:  config.APIKey,
+		id:      id,
 	}
 }
 
@@ -56,7 +69,7 @@ func NewClient(config Config) *Client {
 // todo - verify fields in status, and add alerts.
 func (c *Client) GetLatestPrice(ctx context.Context) (time.Time, currency.Amount, error) {
 	q := url.Values{}
-	q.Add("id", storjID)
+	q.Add("id", c.id)
 	q.Add("convert", usdSymbol)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/v2/cryptocurrency/quotes/latest", nil)
@@ -88,12 +101,12 @@ func (c *Client) GetLatestPrice(ctx context.Context) (time.Time, currency.Amount
 		return time.Time{}, currency.Amount{}, ErrClient.New("unexpected status code: %d", resp.StatusCode)
 	}
 
-	timestamp, err := time.Parse(time.RFC3339Nano, formattedResp.Data[storjID].Quote[usdSymbol].LastUpdated)
+	timestamp, err := time.Parse(time.RFC3339Nano, formattedResp.Data[c.id].Quote[usdSymbol].LastUpdated)
 	if err != nil {
 		return time.Time{}, currency.Amount{}, ErrClient.Wrap(err)
 	}
 
-	amount := currency.AmountFromDecimal(formattedResp.Data[storjID].Quote[usdSymbol].Price, currency.USDollarsMicro)
+	amount := currency.AmountFromDecimal(formattedResp.Data[c.id].Quote[usdSymbol].Price, currency.USDollarsMicro)
 	return timestamp, amount, nil
 }
 
@@ -101,7 +114,7 @@ func (c *Client) GetLatestPrice(ctx context.Context) (time.Time, currency.Amount
 // todo - verify fields in status, and add alerts.
 func (c *Client) GetPriceAt(ctx context.Context, requestedTimestamp time.Time) (time.Time, currency.Amount, error) {
 	q := url.Values{}
-	q.Add("id", storjID)
+	q.Add("id", c.id)
 	q.Add("convert", usdSymbol)
 	q.Add("time_end", strconv.FormatInt(requestedTimestamp.UnixMilli(), 10))
 
@@ -149,7 +162,7 @@ func (c *Client) GetPriceAt(ctx context.Context, requestedTimestamp time.Time) (
 // Ping checks that the coinmarketcap third-party api is available for use.
 func (c *Client) Ping(ctx context.Context) (statusCode int, err error) {
 	q := url.Values{}
-	q.Add("id", storjID)
+	q.Add("id", c.id)
 	q.Add("convert", usdSymbol)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/v1/key/info", nil)
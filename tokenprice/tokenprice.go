@@ -5,14 +5,31 @@ package tokenprice
 
 import (
 	"context"
+	"math"
 	"time"
 
+	"github.com/shopspring/decimal"
+
 	"storj.io/common/currency"
+	"storj.io/storjscan/common"
+)
+
+var (
+	maxBaseUnits = decimal.NewFromInt(math.MaxInt64)
+	minBaseUnits = decimal.NewFromInt(math.MinInt64)
 )
 
-// CalculateValue calculates value from given token value and price.
-func CalculateValue(value, price currency.Amount) currency.Amount {
+// CalculateValue calculates value from given token value and price. Values which don't fit into the base units of the
+// price currency are clamped to the largest amount which does.
+func CalculateValue(value common.TokenAmount, price currency.Amount) currency.Amount {
 	val := value.AsDecimal().Mul(price.AsDecimal())
+	baseUnits := val.Shift(price.Currency().DecimalPlaces()).Round(0)
+	switch {
+	case baseUnits.GreaterThan(maxBaseUnits):
+		return currency.AmountFromBaseUnits(math.MaxInt64, price.Currency())
+	case baseUnits.LessThan(minBaseUnits):
+		return currency.AmountFromBaseUnits(math.MinInt64, price.Currency())
+	}
 	return currency.AmountFromDecimal(val, price.Currency())
 }
 
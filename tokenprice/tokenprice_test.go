@@ -4,18 +4,21 @@
 package tokenprice_test
 
 import (
+	"math"
+	"math/big"
 	"testing"
 
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
 
 	"storj.io/common/currency"
+	"storj.io/storjscan/common"
 	"storj.io/storjscan/tokenprice"
 )
 
 func TestCalculateValue(t *testing.T) {
 	var (
-		tokenValue = currency.AmountFromBaseUnits(100000000, currency.StorjToken)
+		tokenValue = common.TokenAmountFromBaseUnits(100000000, currency.StorjToken)
 
 		prices = []float64{
 			0.9,
@@ -41,3 +44,29 @@ func TestCalculateValue(t *testing.T) {
 		require.Equal(t, expected, value)
 	}
 }
+
+func TestCalculateValueLarge(t *testing.T) {
+	// 10^12 tokens with 18 decimals, which don't fit into int64 base units
+	baseUnits := new(big.Int).Exp(big.NewInt(10), big.NewInt(30), nil)
+	tokenValue := common.TokenAmountFromBig(baseUnits, currency.New("Test Token", "TT", 18))
+	price := currency.AmountFromBaseUnits(2000000, currency.USDollarsMicro)
+
+	expected := currency.AmountFromBaseUnits(2000000000000000000, currency.USDollarsMicro)
+
+	value := tokenprice.CalculateValue(tokenValue, price)
+	require.True(t, expected.Equal(value), "expected %s, got %s", expected.AsDecimal(), value.AsDecimal())
+}
+
+func TestCalculateValueOverflow(t *testing.T) {
+	// 10^22 tokens with 18 decimals, worth more USD micro units than fit into int64
+	baseUnits := new(big.Int).Exp(big.NewInt(10), big.NewInt(40), nil)
+	tokenValue := common.TokenAmountFromBig(baseUnits, currency.New("Test Token", "TT", 18))
+	price := currency.AmountFromBaseUnits(2000000, currency.USDollarsMicro)
+
+	value := tokenprice.CalculateValue(tokenValue, price)
+	require.Equal(t, currency.AmountFromBaseUnits(math.MaxInt64, currency.USDollarsMicro), value)
+
+	tokenValue = common.TokenAmountFromBig(new(big.Int).Neg(baseUnits), currency.New("Test Token", "TT", 18))
+	value = tokenprice.CalculateValue(tokenValue, price)
+	require.Equal(t, currency.AmountFromBaseUnits(math.MinInt64, currency.USDollarsMicro), value)
+}
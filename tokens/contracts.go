@@ -0,0 +1,152 @@
+// Copyright (C) 2024 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package tokens
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	"storj.io/common/currency"
+	"storj.io/storjscan/blockchain"
+	"storj.io/storjscan/common"
+	"storj.io/storjscan/tokens/erc20"
+)
+
+// ErrContractMismatch is the error class of token contracts which don't match their configuration.
+var ErrContractMismatch = errs.Class("token contract mismatch")
+
+// contractKey identifies a token contract on a chain.
+type contractKey struct {
+	chainID  int64
+	contract common.Address
+}
+
+// VerifyContracts reads the symbol and decimals of the token contracts of all the endpoints and returns an error if
+// they don't match the configured tokens, as a misconfigured contract address would silently produce wrong amounts.
+// The amounts of the payments are then denominated in the currencies read from the contracts. Chains which can't be
+// reached are only logged, their contracts are verified before their payments are served.
+func (service *Service) VerifyContracts(ctx context.Context) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	service.mu.Lock()
+	if service.currencies == nil {
+		service.currencies = make(map[contractKey]*currency.Currency)
+	}
+	service.mu.Unlock()
+
+	var group errs.Group
+	for _, endpoint := range service.endpoints {
+		for _, token := range endpoint.GetTokens() {
+			err := service.verifyContract(ctx, endpoint, token)
+			switch {
+			case err == nil:
+				service.log.Debug("verified token contract", zap.Int64("Chain ID", endpoint.ChainID), zap.String("Symbol", token.Symbol),
+					zap.String("Contract", token.Contract))
+			case ErrContractMismatch.Has(err):
+				group.Add(err)
+			default:
+				service.log.Warn("failed to verify token contract", zap.Int64("Chain ID", endpoint.ChainID), zap.String("Symbol", token.Symbol),
+					zap.String("Contract", token.Contract), zap.Error(err))
+			}
+		}
+	}
+	return group.Err()
+}
+
+// verifyChainContracts verifies the token contracts of the endpoint which couldn't be verified yet. Nothing is
+// verified unless VerifyContracts was called.
+func (service *Service) verifyChainContracts(ctx context.Context, endpoint common.EthEndpoint) error {
+	for _, token := range endpoint.GetTokens() {
+		address, err := token.Address()
+		if err != nil {
+			return ErrContractMismatch.New("invalid contract address %q of token %s", token.Contract, token.Symbol)
+		}
+
+		service.mu.Lock()
+		verifying := service.currencies != nil
+		_, verified := service.currencies[contractKey{chainID: endpoint.ChainID, contract: address}]
+		service.mu.Unlock()
+
+		if !verifying {
+			return nil
+		}
+		if verified {
+			continue
+		}
+		if err := service.verifyContract(ctx, endpoint, token); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyContract reads the currency of the token contract and returns an error if it doesn't match the configured
+// token. The currency of a matching contract is kept to denominate the amounts of its payments.
+func (service *Service) verifyContract(ctx context.Context, endpoint common.EthEndpoint, token common.Token) error {
+	address, err := token.Address()
+	if err != nil {
+		return ErrContractMismatch.New("invalid contract address %q of token %s", token.Contract, token.Symbol)
+	}
+
+	var onChain *currency.Currency
+	err = service.clients.Do(ctx, endpoint, func(chainClient blockchain.ChainClient) error {
+		client, ok := chainClient.(bind.ContractCaller)
+		if !ok {
+			return errs.New("chain client of chain %d doesn't support contract calls", endpoint.ChainID)
+		}
+		var err error
+		onChain, err = contractCurrency(ctx, client, address)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(onChain.Symbol(), token.Symbol) || onChain.DecimalPlaces() != token.Decimals {
+		return ErrContractMismatch.New("contract %s on chain %d is %s with %d decimals, configured as %s with %d decimals",
+			token.Contract, endpoint.ChainID, onChain.Symbol(), onChain.DecimalPlaces(), token.Symbol, token.Decimals)
+	}
+
+	service.mu.Lock()
+	service.currencies[contractKey{chainID: endpoint.ChainID, contract: address}] = onChain
+	service.mu.Unlock()
+	return nil
+}
+
+// tokenCurrency returns the currency of the amounts of the token, read from its contract once it's verified.
+func (service *Service) tokenCurrency(chainID int64, token common.Token, contract common.Address) *currency.Currency {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	if onChain, ok := service.currencies[contractKey{chainID: chainID, contract: contract}]; ok {
+		return onChain
+	}
+	return token.Currency()
+}
+
+// contractCurrency returns the currency of the token contract, constructed from its symbol and decimals.
+func contractCurrency(ctx context.Context, client bind.ContractCaller, address common.Address) (*currency.Currency, error) {
+	contract, err := erc20.NewERC20Caller(address, client)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &bind.CallOpts{Context: ctx}
+	decimals, err := contract.Decimals(opts)
+	if errors.Is(err, bind.ErrNoCode) {
+		return nil, ErrContractMismatch.New("no contract at address %s", address.Hex())
+	}
+	if err != nil {
+		return nil, err
+	}
+	symbol, err := contract.Symbol(opts)
+	if err != nil {
+		return nil, err
+	}
+	return common.Token{Symbol: symbol, Decimals: int32(decimals)}.Currency(), nil
+}
@@ -0,0 +1,33 @@
+// Copyright (C) 2024 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package tokens
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/zeebo/errs"
+)
+
+// ErrCursor - payments cursor error class.
+var ErrCursor = errs.Class("payments cursor")
+
+// encodeCursor encodes the blocks to continue scanning the chains from, keyed by chain id, into an opaque cursor.
+func encodeCursor(from map[int64]int64) string {
+	data, _ := json.Marshal(from)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor decodes the blocks to continue scanning the chains from, keyed by chain id, from an opaque cursor.
+func decodeCursor(cursor string) (map[int64]int64, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrCursor.Wrap(err)
+	}
+	var from map[int64]int64
+	if err = json.Unmarshal(data, &from); err != nil {
+		return nil, ErrCursor.Wrap(err)
+	}
+	return from, nil
+}
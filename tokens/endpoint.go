@@ -55,17 +55,10 @@ func (endpoint *Endpoint) Payments(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	from := map[int64]int64{}
-	for _, ethEndpoint := range endpoint.service.endpoints {
-		from[ethEndpoint.ChainID] = 0
-		if s := r.URL.Query().Get(strconv.FormatInt(ethEndpoint.ChainID, 10)); s != "" {
-			block, err := strconv.ParseInt(s, 10, 64)
-			if err != nil {
-				api.ServeJSONError(endpoint.log, w, http.StatusBadRequest, ErrEndpoint.Wrap(err))
-				return
-			}
-			from[ethEndpoint.ChainID] = block
-		}
+	from, err := endpoint.fromBlocks(r)
+	if err != nil {
+		api.ServeJSONError(endpoint.log, w, http.StatusBadRequest, ErrEndpoint.Wrap(err))
+		return
 	}
 
 	payments, err := endpoint.service.Payments(ctx, address, from)
@@ -87,17 +80,10 @@ func (endpoint *Endpoint) AllPayments(w http.ResponseWriter, r *http.Request) {
 	var err error
 	defer mon.Task()(&ctx)(&err)
 
-	from := map[int64]int64{}
-	for _, ethEndpoint := range endpoint.service.endpoints {
-		from[ethEndpoint.ChainID] = 0
-		if s := r.URL.Query().Get(strconv.FormatInt(ethEndpoint.ChainID, 10)); s != "" {
-			block, err := strconv.ParseInt(s, 10, 64)
-			if err != nil {
-				api.ServeJSONError(endpoint.log, w, http.StatusBadRequest, ErrEndpoint.Wrap(err))
-				return
-			}
-			from[ethEndpoint.ChainID] = block
-		}
+	from, err := endpoint.fromBlocks(r)
+	if err != nil {
+		api.ServeJSONError(endpoint.log, w, http.StatusBadRequest, ErrEndpoint.Wrap(err))
+		return
 	}
 
 	// We request logs of 100 addresses in one batch. We can make it configurable if required later.
@@ -114,3 +100,32 @@ func (endpoint *Endpoint) AllPayments(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// fromBlocks returns the blocks to start scanning the chains from, keyed by chain id. They are given either per chain
+// id query parameter or by the cursor query parameter continuing the scan of a partial response.
+func (endpoint *Endpoint) fromBlocks(r *http.Request) (map[int64]int64, error) {
+	from := map[int64]int64{}
+	for _, ethEndpoint := range endpoint.service.endpoints {
+		from[ethEndpoint.ChainID] = 0
+		if s := r.URL.Query().Get(strconv.FormatInt(ethEndpoint.ChainID, 10)); s != "" {
+			block, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			from[ethEndpoint.ChainID] = block
+		}
+	}
+
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		next, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		for chainID := range from {
+			if block, ok := next[chainID]; ok {
+				from[chainID] = block
+			}
+		}
+	}
+	return from, nil
+}
@@ -14,7 +14,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/zeebo/errs"
 	"go.uber.org/zap/zaptest"
+	"golang.org/x/sync/errgroup"
 
 	"storj.io/common/currency"
 	"storj.io/common/testcontext"
@@ -58,8 +60,10 @@ func testEndpoint(t *testing.T, connStr string) {
 		}
 
 		tokenPriceDB := db.TokenPrice()
-		headersCache := blockchain.NewHeadersCache(logger, db.Headers())
-		events := events.NewEventsService(logger, db.Wallets(), events.Config{
+		headersCache := blockchain.NewHeadersCache(logger, db.Headers(), blockchain.HeadersCacheConfig{})
+		clients := blockchain.NewClients(logger, blockchain.ClientsConfig{})
+		defer ctx.Check(clients.Close)
+		events := events.NewEventsService(logger, clients, db.Wallets(), db.TransferEvents(), db.ScanWatermarks(), db.ReportedEvents(), events.Config{
 			AddressBatchSize: 100,
 			BlockBatchSize:   100,
 			ChainReorgBuffer: 15,
@@ -75,7 +79,7 @@ func testEndpoint(t *testing.T, connStr string) {
 		err = json.Unmarshal([]byte(jsonEndpoint), &ethEndpoints)
 		require.NoError(t, err)
 
-		service := tokens.NewService(logger.Named("service"), ethEndpoints, headersCache, events, tokenPrice)
+		service := tokens.NewService(logger.Named("service"), clients, ethEndpoints, headersCache, events, tokenPrice, nil, 0, 0)
 		paymentEndpoint := tokens.NewEndpoint(logger.Named("endpoint"), service)
 
 		apiServer := api.NewServer(logger, lis, map[string]string{"eu1": "eu1secret", "us1": "us1secret"})
@@ -195,7 +199,7 @@ func testEndpoint(t *testing.T, connStr string) {
 			require.Equal(t, latestBlockHeader, payments.LatestBlocks[0])
 			require.Equal(t, accounts[0].Address, payments.Payments[0].From)
 			require.EqualValues(t, 1000000, payments.Payments[0].TokenValue.BaseUnits())
-			require.EqualValues(t, tokenprice.CalculateValue(currency.AmountFromBaseUnits(1000000, currency.StorjToken), price), payments.Payments[0].USDValue)
+			require.EqualValues(t, tokenprice.CalculateValue(common.TokenAmountFromBaseUnits(1000000, currency.StorjToken), price), payments.Payments[0].USDValue)
 			require.Equal(t, recpt.BlockHash, payments.Payments[0].BlockHash)
 			require.Equal(t, recpt.BlockNumber.Int64(), payments.Payments[0].BlockNumber)
 			require.Equal(t, tx.Hash(), payments.Payments[0].Transaction)
@@ -238,5 +242,168 @@ func testEndpoint(t *testing.T, connStr string) {
 			require.Len(t, payments.Payments, 2)
 			require.Equal(t, accounts[2].Address, payments.Payments[0].To)
 		})
+
+		t.Run("/payments REST endpoint handles parallel requests", func(t *testing.T) {
+			url := fmt.Sprintf(
+				"http://%s/api/v0/example/payments",
+				lis.Addr().String())
+
+			const pollers = 8
+			results := make([]tokens.LatestPayments, pollers)
+
+			var group errgroup.Group
+			for i := range results {
+				group.Go(func() error {
+					req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+					if err != nil {
+						return err
+					}
+					req.SetBasicAuth("us1", "us1secret")
+
+					resp, err := http.DefaultClient.Do(req)
+					if err != nil {
+						return err
+					}
+					defer func() { _ = resp.Body.Close() }()
+
+					if resp.StatusCode != http.StatusOK {
+						return errs.New("unexpected status code: %d", resp.StatusCode)
+					}
+					return json.NewDecoder(resp.Body).Decode(&results[i])
+				})
+			}
+			require.NoError(t, group.Wait())
+
+			for _, payments := range results {
+				require.Len(t, payments.Payments, 2)
+				require.Equal(t, results[0].LatestBlocks, payments.LatestBlocks)
+				require.Equal(t, accounts[2].Address, payments.Payments[0].To)
+			}
+		})
+	})
+}
+
+func TestEndpointCursor(t *testing.T) {
+	t.Run("Postgres", func(t *testing.T) {
+		testEndpointCursor(t, dbtest.PickPostgres(t))
+	})
+	t.Run("Cockroach", func(t *testing.T) {
+		testEndpointCursor(t, dbtest.PickCockroach(t))
+	})
+}
+
+func testEndpointCursor(t *testing.T, connStr string) {
+	testeth.Run(t, 1, 2, func(ctx *testcontext.Context, t *testing.T, networks []*testeth.Network) {
+		logger := zaptest.NewLogger(t)
+
+		network := networks[0]
+		db, err := storjscandbtest.OpenDB(ctx, zaptest.NewLogger(t), connStr, t.Name(), "T")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ctx.Check(db.Close)
+
+		err = db.MigrateToLatest(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// a single block is scanned after the starting block, so that every transfer needs a new request
+		headersCache := blockchain.NewHeadersCache(logger, db.Headers(), blockchain.HeadersCacheConfig{})
+		clients := blockchain.NewClients(logger, blockchain.ClientsConfig{})
+		defer ctx.Check(clients.Close)
+		events := events.NewEventsService(logger, clients, db.Wallets(), db.TransferEvents(), db.ScanWatermarks(), db.ReportedEvents(), events.Config{
+			AddressBatchSize: 100,
+			BlockBatchSize:   100,
+			ChainReorgBuffer: 15,
+			MaximumQuerySize: 1,
+		})
+		tokenPrice := tokenprice.NewService(logger, db.TokenPrice(), coinmarketcap.NewTestClient(), time.Minute)
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		jsonEndpoint := `[{"Name":"Geth", "URL": "` + network.HTTPEndpoint() + `", "Contract": "` + network.TokenAddress().Hex() + `", "ChainID": "` + fmt.Sprint(network.ChainID()) + `"}]`
+		var ethEndpoints []common.EthEndpoint
+		err = json.Unmarshal([]byte(jsonEndpoint), &ethEndpoints)
+		require.NoError(t, err)
+
+		service := tokens.NewService(logger.Named("service"), clients, ethEndpoints, headersCache, events, tokenPrice, nil, 0, 0)
+		paymentEndpoint := tokens.NewEndpoint(logger.Named("endpoint"), service)
+
+		apiServer := api.NewServer(logger, lis, map[string]string{"eu1": "eu1secret"})
+		apiServer.NewAPI("/example", paymentEndpoint.Register)
+		ctx.Go(func() error {
+			return apiServer.Run(ctx)
+		})
+		defer ctx.Check(apiServer.Close)
+
+		client := network.Dial()
+		defer client.Close()
+
+		tk, err := testtoken.NewTestToken(network.TokenAddress(), client)
+		require.NoError(t, err)
+
+		accounts := network.Accounts()
+
+		const transfers = 3
+		for i := 0; i < transfers; i++ {
+			opts := network.TransactOptions(ctx, accounts[0], int64(i+1))
+			tx, err := tk.Transfer(opts, accounts[1].Address, big.NewInt(1000000))
+			require.NoError(t, err)
+			_, err = network.WaitForTx(ctx, tx.Hash())
+			require.NoError(t, err)
+		}
+
+		price := currency.AmountFromBaseUnits(2000000, currency.USDollarsMicro)
+		firstBlock := network.Ethereum().BlockChain().GetBlockByNumber(1)
+		startTime := time.Unix(int64(firstBlock.Time()), 0).Add(-time.Minute)
+		for i := 0; i < 10; i++ {
+			window := startTime.Add(time.Duration(i) * time.Minute)
+			require.NoError(t, db.TokenPrice().Update(ctx, window, price.BaseUnits()))
+		}
+
+		currentHead, err := client.HeaderByNumber(ctx, nil)
+		require.NoError(t, err)
+
+		url := fmt.Sprintf("http://%s/api/v0/example/payments/%s", lis.Addr().String(), accounts[1].Address.String())
+		query := fmt.Sprintf("%d=1", network.ChainID())
+
+		var received []tokens.Payment
+		var requests int
+		for {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"?"+query, nil)
+			require.NoError(t, err)
+			req.SetBasicAuth("eu1", "eu1secret")
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			var payments tokens.LatestPayments
+			err = json.NewDecoder(resp.Body).Decode(&payments)
+			require.NoError(t, resp.Body.Close())
+			require.NoError(t, err)
+
+			requests++
+			received = append(received, payments.Payments...)
+			require.Len(t, payments.Chains, 1)
+			require.Equal(t, payments.Partial, payments.Chains[0].Partial)
+			if !payments.Partial {
+				require.Empty(t, payments.Cursor)
+				require.Equal(t, currentHead.Number.Int64(), payments.LatestBlocks[0].Number)
+				break
+			}
+			require.NotEmpty(t, payments.Cursor)
+			require.Less(t, payments.LatestBlocks[0].Number, currentHead.Number.Int64())
+			query = "cursor=" + payments.Cursor
+		}
+
+		// no transfer is skipped while catching up with the chain
+		require.Greater(t, requests, 1)
+		require.Len(t, received, transfers)
+		for _, payment := range received {
+			require.Equal(t, accounts[1].Address, payment.To)
+		}
 	})
 }
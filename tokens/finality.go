@@ -0,0 +1,88 @@
+// Copyright (C) 2024 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package tokens
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.uber.org/zap"
+
+	"storj.io/storjscan/blockchain"
+)
+
+// chainFinality holds the block numbers of a chain used to derive the status of payments.
+// safe and finalized are -1 when the node doesn't support the respective block tag.
+type chainFinality struct {
+	head      int64
+	safe      int64
+	finalized int64
+}
+
+// getChainFinality returns the latest, safe and finalized block numbers of the chain.
+func getChainFinality(ctx context.Context, log *zap.Logger, client blockchain.ChainClient) (_ chainFinality, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return chainFinality{}, err
+	}
+	finality := chainFinality{
+		head:      head.Number.Int64(),
+		safe:      -1,
+		finalized: -1,
+	}
+
+	// not every node supports the safe and finalized block tags, the confirmation threshold is used instead
+	safe, err := client.HeaderByNumber(ctx, big.NewInt(rpc.SafeBlockNumber.Int64()))
+	switch {
+	case err == nil:
+		finality.safe = safe.Number.Int64()
+	case isBlockTagUnsupported(err, rpc.SafeBlockNumber):
+		log.Debug("safe block tag is not available", zap.Error(err))
+	default:
+		return chainFinality{}, err
+	}
+	finalized, err := client.HeaderByNumber(ctx, big.NewInt(rpc.FinalizedBlockNumber.Int64()))
+	switch {
+	case err == nil:
+		finality.finalized = finalized.Number.Int64()
+	case isBlockTagUnsupported(err, rpc.FinalizedBlockNumber):
+		log.Debug("finalized block tag is not available", zap.Error(err))
+	default:
+		return chainFinality{}, err
+	}
+	return finality, nil
+}
+
+// isBlockTagUnsupported reports whether the error is returned by a node which doesn't know the block tag: the node
+// has no such block, such as pre-merge nodes, or rejects the tag as an invalid parameter. Other errors, such as
+// transient provider failures or canceled requests, are not.
+func isBlockTagUnsupported(err error, tag rpc.BlockNumber) bool {
+	if errors.Is(err, ethereum.NotFound) {
+		return true
+	}
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	return rpcErr.ErrorCode() == -32602 || strings.Contains(rpcErr.Error(), tag.String()+" block not found")
+}
+
+// status returns the number of confirmations and the status of a payment in the given block.
+func (finality chainFinality) status(blockNumber, threshold int64) (int64, PaymentStatus) {
+	confirmations := max(finality.head-blockNumber+1, 0)
+	switch {
+	case blockNumber <= finality.finalized:
+		return confirmations, PaymentStatusFinalized
+	case blockNumber <= finality.safe, confirmations >= threshold:
+		return confirmations, PaymentStatusConfirmed
+	default:
+		return confirmations, PaymentStatusPending
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (C) 2024 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package tokens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	"storj.io/storjscan/blockchain"
+	"storj.io/storjscan/common"
+)
+
+// transferTopic is the topic of the ERC20 Transfer event logs.
+var transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// receiptClient is a chain client able to return the transactions and their receipts.
+type receiptClient interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error)
+}
+
+// verifyQuorum confirms the payments of the endpoint chain on Quorum providers other than the one of the served client,
+// which served the payments. Payments which are not confirmed by enough providers get the reason in their Disagreement.
+func (service *Service) verifyQuorum(ctx context.Context, endpoint common.EthEndpoint, served blockchain.ChainClient, payments []Payment) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if service.quorum <= 0 || len(payments) == 0 {
+		return nil
+	}
+
+	confirmations := make([]int, len(payments))
+	disagreements := make([][]string, len(payments))
+	_, err = service.clients.DoOthers(ctx, endpoint, served, service.quorum, func(chainClient blockchain.ChainClient) error {
+		client, ok := chainClient.(receiptClient)
+		if !ok {
+			return errs.New("chain client of chain %d doesn't support transaction receipts", endpoint.ChainID)
+		}
+		// the results of a provider are only counted when all the payments could be verified on it
+		results := make([]string, len(payments))
+		for i, payment := range payments {
+			disagreement, err := verifyPayment(ctx, client, payment)
+			if err != nil {
+				return err
+			}
+			results[i] = disagreement
+		}
+		for i, disagreement := range results {
+			if disagreement == "" {
+				confirmations[i]++
+			} else {
+				disagreements[i] = append(disagreements[i], disagreement)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		service.log.Warn("failed to verify payments on some providers", zap.Int64("Chain ID", endpoint.ChainID), zap.Error(err))
+	}
+
+	chainTag := monkit.NewSeriesTag("chain_id", strconv.FormatInt(endpoint.ChainID, 10))
+	for i := range payments {
+		switch {
+		case len(disagreements[i]) > 0:
+			payments[i].Disagreement = strings.Join(disagreements[i], "; ")
+			mon.Counter("quorum_disagreements", chainTag).Inc(1)
+			service.log.Warn("providers disagree on payment",
+				zap.Int64("Chain ID", payments[i].ChainID),
+				zap.String("Transaction Hash", payments[i].Transaction.String()),
+				zap.Int("Log Index", payments[i].LogIndex),
+				zap.String("Disagreement", payments[i].Disagreement),
+			)
+		case confirmations[i] < service.quorum:
+			payments[i].Disagreement = fmt.Sprintf("confirmed by %d of %d providers", confirmations[i], service.quorum)
+			mon.Counter("quorum_unconfirmed", chainTag).Inc(1)
+		default:
+			mon.Counter("quorum_confirmed", chainTag).Inc(1)
+		}
+	}
+	return nil
+}
+
+// verifyPayment checks the transaction hash, block hash, log index and amount of the payment on the provider of the
+// client. It returns the disagreement of the provider, empty when the provider confirms the payment.
+func verifyPayment(ctx context.Context, client receiptClient, payment Payment) (_ string, err error) {
+	receipt, err := client.TransactionReceipt(ctx, payment.Transaction)
+	if errors.Is(err, ethereum.NotFound) {
+		return fmt.Sprintf("transaction %s not found", payment.Transaction.Hex()), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Sprintf("transaction %s failed", payment.Transaction.Hex()), nil
+	}
+	if receipt.BlockHash != payment.BlockHash {
+		return fmt.Sprintf("transaction %s in block %s", payment.Transaction.Hex(), receipt.BlockHash.Hex()), nil
+	}
+
+	if payment.Contract == common.NativeAddress {
+		return verifyNativePayment(ctx, client, payment)
+	}
+
+	for _, log := range receipt.Logs {
+		if int(log.Index) != payment.LogIndex {
+			continue
+		}
+		switch {
+		case log.Address != payment.Contract:
+			return fmt.Sprintf("log %d of contract %s", log.Index, log.Address.Hex()), nil
+		case len(log.Topics) < 3 || log.Topics[0] != transferTopic:
+			return fmt.Sprintf("log %d is not a transfer", log.Index), nil
+		case common.Address(log.Topics[1][common.HashLength-common.AddrLength:]) != payment.From,
+			common.Address(log.Topics[2][common.HashLength-common.AddrLength:]) != payment.To:
+			return fmt.Sprintf("log %d transfers between other addresses", log.Index), nil
+		}
+		if amount := new(big.Int).SetBytes(log.Data); amount.Cmp(payment.TokenValue.BaseUnitsBig()) != 0 {
+			return fmt.Sprintf("log %d amount %s", log.Index, amount), nil
+		}
+		return "", nil
+	}
+	return fmt.Sprintf("log %d not found", payment.LogIndex), nil
+}
+
+// verifyNativePayment checks the amount of a native payment made by the transaction itself. The amounts of native
+// payments made by contract calls are only known from traces, which aren't compared between providers, so they are
+// reported as unverifiable rather than confirmed.
+func verifyNativePayment(ctx context.Context, client receiptClient, payment Payment) (_ string, err error) {
+	tx, _, err := client.TransactionByHash(ctx, payment.Transaction)
+	if errors.Is(err, ethereum.NotFound) {
+		return fmt.Sprintf("transaction %s not found", payment.Transaction.Hex()), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if tx.To() == nil || *tx.To() != payment.To {
+		return fmt.Sprintf("native payment of transaction %s made by a contract call can't be verified", payment.Transaction.Hex()), nil
+	}
+	if tx.Value().Cmp(payment.TokenValue.BaseUnitsBig()) != 0 {
+		return fmt.Sprintf("transaction %s amount %s", payment.Transaction.Hex(), tx.Value()), nil
+	}
+	return "", nil
+}
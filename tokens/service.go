@@ -5,9 +5,11 @@ package tokens
 
 import (
 	"context"
+	"strconv"
+	"sync"
 	"time"
 
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/spacemonkeygo/monkit/v3"
 	"github.com/zeebo/errs"
 	"go.uber.org/zap"
 
@@ -23,7 +25,11 @@ var ErrService = errs.Class("tokens service")
 
 // Config holds tokens service configuration.
 type Config struct {
-	Endpoints string `help:"List of RPC endpoints [{Name:<Name>,URL:<URL>,Contract:<Contract Address>,ChainID:<Chain ID>},...]" devDefault:"[{'Name':'Geth','URL':'http://localhost:8545','Contract':'0xb64ef51c888972c908cfacf59b47c1afbc0ab8ac','ChainID':'1337'}]" releaseDefault:"[{'Name':'Ethereum Mainnet','URL':'/home/storj/.ethereum/geth.ipc','Contract':'0xb64ef51c888972c908cfacf59b47c1afbc0ab8ac','ChainID':'1'}]"`
+	Endpoints    string        `help:"List of RPC endpoints [{Name:<Name>,URL:<URL>,URLs:[<Failover URL>,...],Contract:<Contract Address>,Tokens:[{Symbol:<Symbol>,Contract:<Contract Address>,Decimals:<Decimals>,Price:<storj|usd|eth>},...],Native:{Symbol:<Symbol>,Decimals:<Decimals>,Price:<storj|usd|eth>},ChainID:<Chain ID>,Confirmations:<Confirmation Threshold>,RequestsPerSecond:<Rate Limit>,RetainBlocks:<Headers Retention Blocks>,RetainDays:<Headers Retention Days>},...]" devDefault:"[{'Name':'Geth','URL':'http://localhost:8545','Contract':'0xb64ef51c888972c908cfacf59b47c1afbc0ab8ac','ChainID':'1337'}]" releaseDefault:"[{'Name':'Ethereum Mainnet','URL':'/home/storj/.ethereum/geth.ipc','Contract':'0xb64ef51c888972c908cfacf59b47c1afbc0ab8ac','ChainID':'1'}]"`
+	ChainTimeout time.Duration `help:"Maximum time of retrieving the payments of a single chain, chains exceeding it are reported as failed" default:"1m"`
+	Quorum       int           `help:"Number of providers, other than the one which found them, that must confirm the payments of a chain, payments without quorum are flagged with a disagreement; 0 disables the verification" default:"0"`
+
+	VerifyContracts bool `help:"Refuse to start when the symbol or decimals of a token contract don't match its configuration" default:"true" devDefault:"false"`
 }
 
 // Service for querying ERC20 token information from ethereum chain.
@@ -31,100 +37,306 @@ type Config struct {
 // architecture: Service
 type Service struct {
 	log          *zap.Logger
+	clients      blockchain.Chains
 	endpoints    []common.EthEndpoint
 	headersCache *blockchain.HeadersCache
 	events       *events.Service
 	tokenPrice   *tokenprice.Service
+	ethPrice     *tokenprice.Service
+	chainTimeout time.Duration
+	quorum       int
+
+	mu sync.Mutex
+	// currencies are the currencies read from the token contracts verified by VerifyContracts, nil when the
+	// contracts are not verified.
+	currencies map[contractKey]*currency.Currency
 }
 
 // NewService creates new token service instance.
 func NewService(
 	log *zap.Logger,
+	clients blockchain.Chains,
 	endpoints []common.EthEndpoint,
 	headersCache *blockchain.HeadersCache,
 	events *events.Service,
-	tokenPrice *tokenprice.Service) *Service {
+	tokenPrice *tokenprice.Service,
+	ethPrice *tokenprice.Service,
+	chainTimeout time.Duration,
+	quorum int) *Service {
 	return &Service{
 		log:          log,
+		clients:      clients,
 		endpoints:    endpoints,
 		headersCache: headersCache,
 		events:       events,
 		tokenPrice:   tokenPrice,
+		ethPrice:     ethPrice,
+		chainTimeout: chainTimeout,
+		quorum:       quorum,
 	}
 }
 
 // Payments retrieves all ERC20 token payments across all configured endpoints starting from a particular block per chain for ethereum address.
+// Chains failing to be scanned are reported in the chain statuses instead of failing the whole request, unless all of them fail.
+// When some chains are scanned partially, the returned cursor continues the scan.
 func (service *Service) Payments(ctx context.Context, address common.Address, from map[int64]int64) (_ LatestPayments, err error) {
 	defer mon.Task()(&ctx)(&err)
 	service.log.Debug("payments request received for address", zap.String("wallet", address.Hex()))
-	lastestBlocks, newEvents, err := service.events.GetForAddress(ctx, service.endpoints, []common.Address{address}, from)
-	if err != nil {
-		return LatestPayments{}, ErrService.Wrap(err)
-	}
-	return service.toPayments(ctx, lastestBlocks, newEvents)
+	return service.forEachChain(ctx, from, func(ctx context.Context, endpoint common.EthEndpoint) (LatestPayments, error) {
+		latestBlocks, newEvents, partial, scanErr := service.events.GetForAddress(ctx, []common.EthEndpoint{endpoint}, []common.Address{address}, from)
+		if scanErr != nil && !events.ErrNativeScan.Has(scanErr) {
+			return LatestPayments{}, scanErr
+		}
+		payments, err := service.toPayments(ctx, endpoint, latestBlocks, partial, newEvents, nil)
+		if err != nil {
+			return LatestPayments{}, err
+		}
+		return payments, scanErr
+	})
 }
 
 // AllPayments returns all the payments across all configured endpoints starting from a particular block per chain associated with the current satellite.
+// Chains failing to be scanned are reported in the chain statuses instead of failing the whole request, unless all of them fail.
+// When some chains are scanned partially, the returned cursor continues the scan.
 func (service *Service) AllPayments(ctx context.Context, satelliteID string, from map[int64]int64) (_ LatestPayments, err error) {
 	defer mon.Task()(&ctx)(&err)
 	service.log.Debug("payments request received for satellite", zap.String("satelliteID", satelliteID))
-	lastestBlocks, newEvents, err := service.events.GetForSatellite(ctx, service.endpoints, satelliteID, from)
+	// the satellite wallets and scan watermarks are shared by the chains
+	scan, err := service.events.ForSatellite(ctx, satelliteID)
 	if err != nil {
 		return LatestPayments{}, ErrService.Wrap(err)
 	}
-	return service.toPayments(ctx, lastestBlocks, newEvents)
+	return service.forEachChain(ctx, from, func(ctx context.Context, endpoint common.EthEndpoint) (LatestPayments, error) {
+		endpoints := []common.EthEndpoint{endpoint}
+		latestBlocks, newEvents, partial, scanErr := scan.Get(ctx, endpoints, from)
+		if scanErr != nil && !events.ErrNativeScan.Has(scanErr) {
+			return LatestPayments{}, scanErr
+		}
+		removedEvents, err := service.events.GetRemovedForSatellite(ctx, endpoints, satelliteID)
+		if err != nil {
+			return LatestPayments{}, err
+		}
+		payments, err := service.toPayments(ctx, endpoint, latestBlocks, partial, newEvents, removedEvents)
+		if err != nil {
+			return LatestPayments{}, err
+		}
+		return payments, scanErr
+	})
 }
 
-func (service *Service) toPayments(ctx context.Context, scannedBlocks map[int64]blockchain.Header, newEvents []events.TransferEvent) (_ LatestPayments, err error) {
-	var allPayments []Payment
-	var latestBlocks []blockchain.Header
-	for _, endpoint := range service.endpoints {
-		payments, err := service.toPaymentsForEndpoint(ctx, endpoint, newEvents)
-		if err != nil {
-			return LatestPayments{}, ErrService.Wrap(err)
+// forEachChain retrieves the payments of the endpoint chains concurrently, each within the chain timeout, and merges
+// them in endpoint order. Failing chains are left out of the payments and reported in the chain statuses, an error is
+// returned when no chain succeeds. The payments retrieved along with an events.ErrNativeScan error are kept, the
+// error being reported as the native error of the chain status. If a chain is scanned partially, the cursor of the
+// payments resumes every chain after its last scanned block, or from the requested block for failed chains and chains
+// which weren't scanned past it.
+func (service *Service) forEachChain(ctx context.Context, from map[int64]int64, retrieve func(ctx context.Context, endpoint common.EthEndpoint) (LatestPayments, error)) (LatestPayments, error) {
+	results := make([]LatestPayments, len(service.endpoints))
+	failures := make([]error, len(service.endpoints))
+
+	var wg sync.WaitGroup
+	for i, endpoint := range service.endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			chainCtx := ctx
+			if service.chainTimeout > 0 {
+				var cancel context.CancelFunc
+				chainCtx, cancel = context.WithTimeout(ctx, service.chainTimeout)
+				defer cancel()
+			}
+			// chains which couldn't be reached at startup have their token contracts verified before being served
+			if failures[i] = service.verifyChainContracts(chainCtx, endpoint); failures[i] != nil {
+				return
+			}
+			results[i], failures[i] = retrieve(chainCtx, endpoint)
+		}()
+	}
+	wg.Wait()
+
+	var latestPayments LatestPayments
+	var failed errs.Group
+	next := make(map[int64]int64, len(service.endpoints))
+	for i, endpoint := range service.endpoints {
+		status := ChainStatus{ChainID: endpoint.ChainID, Name: endpoint.Name}
+		next[endpoint.ChainID] = from[endpoint.ChainID]
+		if events.ErrNativeScan.Has(failures[i]) {
+			service.log.Warn("failed to retrieve native payments of chain",
+				zap.Int64("Chain ID", endpoint.ChainID), zap.String("Name", endpoint.Name), zap.Error(failures[i]))
+			status.NativeError = failures[i].Error()
+			failures[i] = nil
+		}
+		if failures[i] != nil {
+			mon.Counter("chain_failures", monkit.NewSeriesTag("chain_id", strconv.FormatInt(endpoint.ChainID, 10))).Inc(1)
+			service.log.Warn("failed to retrieve payments of chain",
+				zap.Int64("Chain ID", endpoint.ChainID), zap.String("Name", endpoint.Name), zap.Error(failures[i]))
+			status.Error = failures[i].Error()
+			latestPayments.Chains = append(latestPayments.Chains, status)
+			failed.Add(failures[i])
+			continue
+		}
+		for _, header := range results[i].LatestBlocks {
+			// the events of a partial scan are complete up to its last scanned block, which is never before the
+			// requested one unless the provider is behind
+			if header.Number > 0 && header.Number >= from[endpoint.ChainID] {
+				next[endpoint.ChainID] = header.Number + 1
+			}
 		}
-		latestBlocks = append(latestBlocks, scannedBlocks[endpoint.ChainID])
-		allPayments = append(allPayments, payments...)
+		status.Partial = results[i].Partial
+		latestPayments.Partial = latestPayments.Partial || status.Partial
+		latestPayments.Chains = append(latestPayments.Chains, status)
+		latestPayments.LatestBlocks = append(latestPayments.LatestBlocks, results[i].LatestBlocks...)
+		latestPayments.Payments = append(latestPayments.Payments, results[i].Payments...)
+		latestPayments.Removed = append(latestPayments.Removed, results[i].Removed...)
+		latestPayments.Outgoing = append(latestPayments.Outgoing, results[i].Outgoing...)
 	}
+	if len(service.endpoints) > 0 && len(failed) == len(service.endpoints) {
+		return LatestPayments{}, ErrService.New("failed to retrieve the payments of every chain: %v", failed.Err())
+	}
+	if latestPayments.Partial {
+		latestPayments.Cursor = encodeCursor(next)
+	}
+	return latestPayments, nil
+}
 
-	return LatestPayments{
-		LatestBlocks: latestBlocks,
-		Payments:     allPayments,
-	}, nil
+// toPayments converts the transfer events of the endpoint chain to payments along with the latest scanned block of the chain.
+func (service *Service) toPayments(ctx context.Context, endpoint common.EthEndpoint, scannedBlocks map[int64]blockchain.Header, partial map[int64]bool, newEvents, removedEvents []events.TransferEvent) (_ LatestPayments, err error) {
+	latestPayments, err := service.toPaymentsForEndpoint(ctx, endpoint, newEvents, removedEvents)
+	if err != nil {
+		return LatestPayments{}, err
+	}
+	latestPayments.LatestBlocks = []blockchain.Header{scannedBlocks[endpoint.ChainID]}
+	latestPayments.Partial = partial[endpoint.ChainID]
+	return latestPayments, nil
+}
+
+// toPaymentsForEndpoint converts the transfer events of the endpoint chain to incoming, removed and outgoing payments.
+func (service *Service) toPaymentsForEndpoint(ctx context.Context, endpoint common.EthEndpoint, newEvents, removedEvents []events.TransferEvent) (latestPayments LatestPayments, err error) {
+	var served blockchain.ChainClient
+	err = service.clients.Do(ctx, endpoint, func(client blockchain.ChainClient) (err error) {
+		served = client
+		latestPayments, err = service.toPaymentsForClient(ctx, client, endpoint, newEvents, removedEvents)
+		return err
+	})
+	if err != nil {
+		return LatestPayments{}, err
+	}
+	if err = service.verifyQuorum(ctx, endpoint, served, latestPayments.Payments); err != nil {
+		return LatestPayments{}, ErrService.Wrap(err)
+	}
+	return latestPayments, nil
 }
 
-func (service *Service) toPaymentsForEndpoint(ctx context.Context, endpoint common.EthEndpoint, newEvents []events.TransferEvent) (_ []Payment, err error) {
-	client, err := ethclient.DialContext(ctx, endpoint.URL)
+// toPaymentsForClient converts the transfer events of the endpoint chain to incoming, removed and outgoing payments,
+// using the given client to fetch the headers of their blocks and the finality of the chain.
+func (service *Service) toPaymentsForClient(ctx context.Context, client blockchain.ChainClient, endpoint common.EthEndpoint, newEvents, removedEvents []events.TransferEvent) (_ LatestPayments, err error) {
+	var incomingEvents, outgoingEvents []events.TransferEvent
+	for _, event := range newEvents {
+		if event.Outgoing {
+			outgoingEvents = append(outgoingEvents, event)
+		} else {
+			incomingEvents = append(incomingEvents, event)
+		}
+	}
+
+	// the headers of all the event blocks are fetched at once, in JSON-RPC batches for the ones not cached yet
+	var blockHashes []common.Hash
+	for _, list := range [][]events.TransferEvent{newEvents, removedEvents} {
+		for _, event := range list {
+			if event.ChainID == endpoint.ChainID {
+				blockHashes = append(blockHashes, event.BlockHash)
+			}
+		}
+	}
+	headers, err := service.headersCache.GetMany(ctx, client, endpoint.ChainID, blockHashes)
 	if err != nil {
-		return []Payment{}, ErrService.Wrap(err)
+		return LatestPayments{}, ErrService.Wrap(err)
+	}
+
+	var endpointPayments LatestPayments
+	if endpointPayments.Payments, err = service.eventsToPayments(ctx, endpoint, headers, incomingEvents); err != nil {
+		return LatestPayments{}, ErrService.Wrap(err)
+	}
+	if endpointPayments.Outgoing, err = service.eventsToPayments(ctx, endpoint, headers, outgoingEvents); err != nil {
+		return LatestPayments{}, ErrService.Wrap(err)
+	}
+	if endpointPayments.Removed, err = service.eventsToPayments(ctx, endpoint, headers, removedEvents); err != nil {
+		return LatestPayments{}, ErrService.Wrap(err)
+	}
+
+	if len(endpointPayments.Payments) > 0 || len(endpointPayments.Outgoing) > 0 {
+		finality, err := getChainFinality(ctx, service.log, client)
+		if err != nil {
+			return LatestPayments{}, ErrService.Wrap(err)
+		}
+		threshold := endpoint.Confirmations
+		if threshold <= 0 {
+			threshold = DefaultConfirmations
+		}
+		for _, payments := range [][]Payment{endpointPayments.Payments, endpointPayments.Outgoing} {
+			for i := range payments {
+				payments[i].Confirmations, payments[i].Status = finality.status(payments[i].BlockNumber, threshold)
+			}
+		}
 	}
-	defer client.Close()
+	return endpointPayments, nil
+}
 
+// eventsToPayments converts the transfer events of the endpoint chain to payments, using the given headers of their
+// blocks.
+func (service *Service) eventsToPayments(ctx context.Context, endpoint common.EthEndpoint, headers map[common.Hash]blockchain.Header, newEvents []events.TransferEvent) (_ []Payment, err error) {
 	var payments []Payment
 	for _, event := range newEvents {
 		// we only want to consider events for the current endpoint chain ID
 		if event.ChainID != endpoint.ChainID {
 			continue
 		}
-		header, err := service.headersCache.Get(ctx, client, event.ChainID, event.BlockHash)
+		token, ok := endpoint.Token(event.Contract)
+		if !ok {
+			return nil, ErrService.New("unknown token contract %s on chain %d", event.Contract.Hex(), event.ChainID)
+		}
+		contract, err := token.Address()
 		if err != nil {
-			return []Payment{}, ErrService.Wrap(err)
+			return nil, err
+		}
+		header, ok := headers[event.BlockHash]
+		if !ok {
+			return nil, ErrService.New("missing header of block %s on chain %d", event.BlockHash.Hex(), event.ChainID)
 		}
-		price, err := service.tokenPrice.PriceAt(ctx, header.Timestamp)
+		price, err := service.priceAt(ctx, token, header.Timestamp)
 		if err != nil {
-			return []Payment{}, ErrService.Wrap(err)
+			return nil, err
 		}
 
-		payments = append(payments, paymentFromEvent(event, header.Timestamp, price))
+		tokenCurrency := service.tokenCurrency(endpoint.ChainID, token, contract)
+		payments = append(payments, paymentFromEvent(event, token, tokenCurrency, contract, header.Timestamp, price))
 		service.log.Debug("found payment",
 			zap.Int64("Chain ID", payments[len(payments)-1].ChainID),
 			zap.String("Transaction Hash", payments[len(payments)-1].Transaction.String()),
 			zap.Int64("Block Number", payments[len(payments)-1].BlockNumber),
 			zap.Int("Log Index", payments[len(payments)-1].LogIndex),
+			zap.String("Token", payments[len(payments)-1].Token),
 			zap.String("USD Value", payments[len(payments)-1].USDValue.AsDecimal().String()),
 		)
 	}
-	return payments, ErrService.Wrap(err)
+	return payments, nil
+}
+
+// priceAt returns the USD price of the token at the given time from its price source.
+func (service *Service) priceAt(ctx context.Context, token common.Token, timestamp time.Time) (currency.Amount, error) {
+	switch token.Price {
+	case "", common.PriceSourceSTORJ:
+		return service.tokenPrice.PriceAt(ctx, timestamp)
+	case common.PriceSourceUSD:
+		return currency.AmountFromBaseUnits(1000000, currency.USDollarsMicro), nil
+	case common.PriceSourceETH:
+		if service.ethPrice == nil {
+			return currency.Amount{}, ErrService.New("ETH price source of token %s is not configured", token.Symbol)
+		}
+		return service.ethPrice.PriceAt(ctx, timestamp)
+	default:
+		return currency.Amount{}, ErrService.New("unknown price source %q of token %s", token.Price, token.Symbol)
+	}
 }
 
 // PingAll checks if configured blockchain services are available for use.
@@ -132,7 +344,7 @@ func (service *Service) PingAll(ctx context.Context) (err error) {
 	defer mon.Task()(&ctx)(&err)
 
 	for _, endpoint := range service.endpoints {
-		err = ping(ctx, endpoint)
+		err = service.ping(ctx, endpoint)
 		if err != nil {
 			return ErrService.Wrap(err)
 		}
@@ -140,18 +352,12 @@ func (service *Service) PingAll(ctx context.Context) (err error) {
 	return err
 }
 
-func ping(ctx context.Context, endpoint common.EthEndpoint) (err error) {
-	client, err := ethclient.DialContext(ctx, endpoint.URL)
-	if err != nil {
-		return err
-	}
-	defer client.Close()
+func (service *Service) ping(ctx context.Context, endpoint common.EthEndpoint) (err error) {
 	// check if service is reachable by getting the latest block
-	_, err = client.HeaderByNumber(ctx, nil)
-	if err != nil {
+	return service.clients.Do(ctx, endpoint, func(client blockchain.ChainClient) error {
+		_, err := client.HeaderByNumber(ctx, nil)
 		return err
-	}
-	return err
+	})
 }
 
 // GetChainIds returns the chain ids of the currently configured endpoints.
@@ -169,17 +375,21 @@ func (service *Service) GetEndpoints() []common.EthEndpoint {
 	return service.endpoints
 }
 
-func paymentFromEvent(event events.TransferEvent, timestamp time.Time, price currency.Amount) Payment {
+func paymentFromEvent(event events.TransferEvent, token common.Token, tokenCurrency *currency.Currency, contract common.Address, timestamp time.Time, price currency.Amount) Payment {
+	tokenValue := common.TokenAmountFromBig(event.TokenValue.BaseUnitsBig(), tokenCurrency)
 	return Payment{
 		ChainID:     event.ChainID,
 		From:        event.From,
 		To:          event.To,
-		TokenValue:  event.TokenValue,
-		USDValue:    tokenprice.CalculateValue(event.TokenValue, price),
+		Token:       token.Symbol,
+		Contract:    contract,
+		TokenValue:  tokenValue,
+		USDValue:    tokenprice.CalculateValue(tokenValue, price),
 		BlockHash:   event.BlockHash,
 		BlockNumber: event.BlockNumber,
 		Transaction: event.TxHash,
 		LogIndex:    event.LogIndex,
+		TraceIndex:  event.TraceIndex,
 		Timestamp:   timestamp,
 	}
 }
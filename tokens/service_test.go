@@ -7,6 +7,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -21,6 +26,7 @@ import (
 	"storj.io/storjscan/blockchain"
 	"storj.io/storjscan/blockchain/events"
 	"storj.io/storjscan/common"
+	"storj.io/storjscan/private/testchain"
 	"storj.io/storjscan/private/testeth"
 	"storj.io/storjscan/private/testeth/testtoken"
 	"storj.io/storjscan/storjscandb/dbx"
@@ -122,15 +128,17 @@ func testPayments(t *testing.T, connStr string) {
 		err = json.Unmarshal([]byte(jsonEndpoint), &ethEndpoints)
 		require.NoError(t, err)
 
-		headersCache := blockchain.NewHeadersCache(logger, db.Headers())
-		events := events.NewEventsService(logger, db.Wallets(), events.Config{
+		headersCache := blockchain.NewHeadersCache(logger, db.Headers(), blockchain.HeadersCacheConfig{})
+		clients := blockchain.NewClients(logger, blockchain.ClientsConfig{})
+		defer ctx.Check(clients.Close)
+		events := events.NewEventsService(logger, clients, db.Wallets(), db.TransferEvents(), db.ScanWatermarks(), db.ReportedEvents(), events.Config{
 			AddressBatchSize: 100,
 			BlockBatchSize:   100,
 			ChainReorgBuffer: 15,
 			MaximumQuerySize: 10000,
 		})
 		tokenPrice := tokenprice.NewService(logger, tokenPriceDB, coinmarketcap.NewTestClient(), time.Minute)
-		service := tokens.NewService(logger, ethEndpoints, headersCache, events, tokenPrice)
+		service := tokens.NewService(logger, clients, ethEndpoints, headersCache, events, tokenPrice, nil, 0, 0)
 
 		// add the wallet to the DB
 		insertedWallet, err := db.Wallets().Insert(ctx, "test", accs[3].Address, "")
@@ -159,7 +167,7 @@ func testPayments(t *testing.T, connStr string) {
 
 		for i, payment := range payments.Payments {
 			testPayment := testPayments[i]
-			a := currency.AmountFromBaseUnits(testPayment.Amount, currency.StorjToken)
+			a := common.TokenAmountFromBaseUnits(testPayment.Amount, currency.StorjToken)
 
 			require.Equal(t, testPayment.From.Address, payment.From)
 			require.Equal(t, testPayment.Amount, payment.TokenValue.BaseUnits())
@@ -169,6 +177,668 @@ func testPayments(t *testing.T, connStr string) {
 	})
 }
 
+func TestPaymentsTestChain(t *testing.T) {
+	storjscandbtest.Run(t, func(ctx *testcontext.Context, t *testing.T, db *storjscandbtest.DB) {
+		logger := zaptest.NewLogger(t)
+
+		chain := testchain.New(1337)
+		contract, sender, wallet := common.Address{1}, common.Address{2}, common.Address{3}
+		tx := chain.Transfer(contract, sender, wallet, big.NewInt(150000000))
+		block := chain.Mine()
+		chain.Mine()
+		chain.Mine()
+
+		price := currency.AmountFromBaseUnits(2000000, currency.USDollarsMicro)
+		startTime := time.Unix(int64(block.Time), 0).Add(-time.Minute)
+		for i := 0; i < 10; i++ {
+			window := startTime.Add(time.Duration(i) * time.Minute)
+			require.NoError(t, db.TokenPrice().Update(ctx, window, price.BaseUnits()))
+		}
+
+		endpoints := []common.EthEndpoint{{
+			Name:          "Test",
+			Contract:      contract.Hex(),
+			ChainID:       1337,
+			Confirmations: 3,
+		}}
+		headersCache := blockchain.NewHeadersCache(logger, db.Headers(), blockchain.HeadersCacheConfig{})
+		events := events.NewEventsService(logger, chain, db.Wallets(), db.TransferEvents(), db.ScanWatermarks(), db.ReportedEvents(), events.Config{
+			AddressBatchSize: 100,
+			BlockBatchSize:   100,
+			ChainReorgBuffer: 15,
+			MaximumQuerySize: 10000,
+		})
+		tokenPrice := tokenprice.NewService(logger, db.TokenPrice(), coinmarketcap.NewTestClient(), time.Minute)
+		service := tokens.NewService(logger, chain, endpoints, headersCache, events, tokenPrice, nil, 0, 0)
+
+		payments, err := service.Payments(ctx, wallet, nil)
+		require.NoError(t, err)
+		require.Len(t, payments.LatestBlocks, 1)
+		require.EqualValues(t, 3, payments.LatestBlocks[0].Number)
+
+		require.Len(t, payments.Payments, 1)
+		payment := payments.Payments[0]
+		require.Equal(t, sender, payment.From)
+		require.Equal(t, wallet, payment.To)
+		require.Equal(t, tx, payment.Transaction)
+		require.Equal(t, block.Hash(), payment.BlockHash)
+		require.Equal(t, "1.5", payment.TokenValue.AsDecimal().String())
+		require.EqualValues(t, 3000000, payment.USDValue.BaseUnits())
+		require.Equal(t, time.Unix(int64(block.Time), 0).UTC(), payment.Timestamp)
+		require.EqualValues(t, 3, payment.Confirmations)
+		require.Equal(t, tokens.PaymentStatusConfirmed, payment.Status)
+	})
+}
+
+func TestLargePayments(t *testing.T) {
+	t.Run("Postgres", func(t *testing.T) {
+		testLargePayments(t, dbtest.PickPostgres(t))
+	})
+	t.Run("Cockroach", func(t *testing.T) {
+		testLargePayments(t, dbtest.PickCockroach(t))
+	})
+}
+
+func testLargePayments(t *testing.T, connStr string) {
+	testeth.Run(t, 1, 2, func(ctx *testcontext.Context, t *testing.T, networks []*testeth.Network) {
+		logger := zaptest.NewLogger(t)
+		network := networks[0]
+
+		db, err := storjscandbtest.OpenDB(ctx, zaptest.NewLogger(t), connStr, t.Name(), "T")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ctx.Check(db.Close)
+
+		err = db.MigrateToLatest(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		client := network.Dial()
+		defer client.Close()
+
+		tk, err := testtoken.NewTestToken(network.TokenAddress(), client)
+		require.NoError(t, err)
+
+		accs := network.Accounts()
+
+		// amounts which don't fit into int64 base units
+		oneToken := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+		amounts := []*big.Int{
+			new(big.Int).Mul(big.NewInt(1000), oneToken),
+			new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 64), big.NewInt(1)),
+			new(big.Int).Mul(big.NewInt(123456), oneToken),
+		}
+		for i, amount := range amounts {
+			opts := network.TransactOptions(ctx, accs[0], int64(i+1))
+			tx, err := tk.Transfer(opts, accs[1].Address, amount)
+			require.NoError(t, err)
+			_, err = network.WaitForTx(ctx, tx.Hash())
+			require.NoError(t, err)
+		}
+
+		// fill token price DB.
+		tokenPriceDB := db.TokenPrice()
+		firstBlock := network.Ethereum().BlockChain().GetBlockByNumber(1)
+		price := currency.AmountFromBaseUnits(2000000, currency.USDollarsMicro)
+
+		startTime := time.Unix(int64(firstBlock.Time()), 0).Add(-time.Minute)
+		for i := 0; i < 10; i++ {
+			window := startTime.Add(time.Duration(i) * time.Minute)
+			require.NoError(t, tokenPriceDB.Update(ctx, window, price.BaseUnits()))
+		}
+
+		jsonEndpoint := `[{"URL": "` + network.HTTPEndpoint() + `", "Contract": "` + network.TokenAddress().Hex() + `", "ChainID": "` + fmt.Sprint(network.ChainID()) + `"}]`
+		var ethEndpoints []common.EthEndpoint
+		err = json.Unmarshal([]byte(jsonEndpoint), &ethEndpoints)
+		require.NoError(t, err)
+
+		headersCache := blockchain.NewHeadersCache(logger, db.Headers(), blockchain.HeadersCacheConfig{})
+		clients := blockchain.NewClients(logger, blockchain.ClientsConfig{})
+		defer ctx.Check(clients.Close)
+		events := events.NewEventsService(logger, clients, db.Wallets(), db.TransferEvents(), db.ScanWatermarks(), db.ReportedEvents(), events.Config{
+			AddressBatchSize: 100,
+			BlockBatchSize:   100,
+			ChainReorgBuffer: 15,
+			MaximumQuerySize: 10000,
+		})
+		tokenPrice := tokenprice.NewService(logger, tokenPriceDB, coinmarketcap.NewTestClient(), time.Minute)
+		service := tokens.NewService(logger, clients, ethEndpoints, headersCache, events, tokenPrice, nil, 0, 0)
+
+		payments, err := service.Payments(ctx, accs[1].Address, nil)
+		require.NoError(t, err)
+		require.Len(t, payments.Payments, len(amounts))
+
+		// payments must survive the JSON encoding used by the API without losing precision
+		data, err := json.Marshal(payments)
+		require.NoError(t, err)
+		var decoded tokens.LatestPayments
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		require.Len(t, decoded.Payments, len(amounts))
+
+		for i, amount := range amounts {
+			expectedValue := tokenprice.CalculateValue(common.TokenAmountFromBig(amount, currency.StorjToken), price)
+
+			for _, payment := range []tokens.Payment{payments.Payments[i], decoded.Payments[i]} {
+				require.Zero(t, amount.Cmp(payment.TokenValue.BaseUnitsBig()), "expected %s, got %s", amount, payment.TokenValue.BaseUnitsBig())
+				require.True(t, expectedValue.Equal(payment.USDValue), "expected %s, got %s", expectedValue, payment.USDValue)
+			}
+		}
+	})
+}
+
+func TestAllPaymentsReorg(t *testing.T) {
+	t.Run("Postgres", func(t *testing.T) {
+		testAllPaymentsReorg(t, dbtest.PickPostgres(t))
+	})
+	t.Run("Cockroach", func(t *testing.T) {
+		testAllPaymentsReorg(t, dbtest.PickCockroach(t))
+	})
+}
+
+func testAllPaymentsReorg(t *testing.T, connStr string) {
+	testeth.Run(t, 1, 2, func(ctx *testcontext.Context, t *testing.T, networks []*testeth.Network) {
+		logger := zaptest.NewLogger(t)
+		network := networks[0]
+		chainID := network.ChainID().Int64()
+
+		db, err := storjscandbtest.OpenDB(ctx, zaptest.NewLogger(t), connStr, t.Name(), "T")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ctx.Check(db.Close)
+
+		err = db.MigrateToLatest(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		client := network.Dial()
+		defer client.Close()
+
+		tk, err := testtoken.NewTestToken(network.TokenAddress(), client)
+		require.NoError(t, err)
+
+		accs := network.Accounts()
+
+		_, err = db.Wallets().Insert(ctx, "eu1", accs[1].Address, "")
+		require.NoError(t, err)
+		_, err = db.Wallets().Claim(ctx, "eu1")
+		require.NoError(t, err)
+
+		tx, err := tk.Transfer(network.TransactOptions(ctx, accs[0], 1), accs[1].Address, big.NewInt(1000))
+		require.NoError(t, err)
+		rcpt, err := network.WaitForTx(ctx, tx.Hash())
+		require.NoError(t, err)
+
+		// fill token price DB.
+		tokenPriceDB := db.TokenPrice()
+		firstBlock := network.Ethereum().BlockChain().GetBlockByNumber(1)
+		price := currency.AmountFromBaseUnits(2000000, currency.USDollarsMicro)
+
+		startTime := time.Unix(int64(firstBlock.Time()), 0).Add(-time.Minute)
+		for i := 0; i < 10; i++ {
+			window := startTime.Add(time.Duration(i) * time.Minute)
+			require.NoError(t, tokenPriceDB.Update(ctx, window, price.BaseUnits()))
+		}
+
+		jsonEndpoint := `[{"Name":"Geth", "URL": "` + network.HTTPEndpoint() + `", "Contract": "` + network.TokenAddress().Hex() + `", "ChainID": "` + fmt.Sprint(network.ChainID()) + `"}]`
+		var ethEndpoints []common.EthEndpoint
+		err = json.Unmarshal([]byte(jsonEndpoint), &ethEndpoints)
+		require.NoError(t, err)
+
+		headersCache := blockchain.NewHeadersCache(logger, db.Headers(), blockchain.HeadersCacheConfig{})
+		clients := blockchain.NewClients(logger, blockchain.ClientsConfig{})
+		defer ctx.Check(clients.Close)
+		events := events.NewEventsService(logger, clients, db.Wallets(), db.TransferEvents(), db.ScanWatermarks(), db.ReportedEvents(), events.Config{
+			AddressBatchSize: 100,
+			BlockBatchSize:   100,
+			ChainReorgBuffer: 15,
+			MaximumQuerySize: 10000,
+			ReorgCheckDepth:  4,
+		})
+		tokenPrice := tokenprice.NewService(logger, tokenPriceDB, coinmarketcap.NewTestClient(), time.Minute)
+		service := tokens.NewService(logger, clients, ethEndpoints, headersCache, events, tokenPrice, nil, 0, 0)
+
+		payments, err := service.AllPayments(ctx, "eu1", map[int64]int64{chainID: 0})
+		require.NoError(t, err)
+		require.Len(t, payments.Payments, 1)
+		require.Empty(t, payments.Removed)
+		require.Equal(t, rcpt.BlockHash, payments.Payments[0].BlockHash)
+		reported := payments.Payments[0]
+
+		replaced, err := network.Reorg(1)
+		require.NoError(t, err)
+		require.Equal(t, []common.Hash{rcpt.BlockHash}, replaced)
+
+		payments, err = service.AllPayments(ctx, "eu1", map[int64]int64{chainID: 0})
+		require.NoError(t, err)
+		require.Len(t, payments.Removed, 1)
+		require.Equal(t, reported.BlockHash, payments.Removed[0].BlockHash)
+		require.Equal(t, reported.Transaction, payments.Removed[0].Transaction)
+		require.Equal(t, reported.LogIndex, payments.Removed[0].LogIndex)
+		require.True(t, reported.TokenValue.Equal(payments.Removed[0].TokenValue))
+		require.True(t, reported.USDValue.Equal(payments.Removed[0].USDValue))
+		for _, payment := range payments.Payments {
+			require.NotEqual(t, reported.BlockHash, payment.BlockHash)
+		}
+
+		// removed payments are reported to satellites which already scanned past the orphaned block as well
+		payments, err = service.AllPayments(ctx, "eu1", map[int64]int64{chainID: reported.BlockNumber + 1})
+		require.NoError(t, err)
+		require.Len(t, payments.Removed, 1)
+		require.Equal(t, reported.BlockHash, payments.Removed[0].BlockHash)
+
+		// until ReorgCheckDepth blocks were mined after their removal
+		for range 5 {
+			network.Commit()
+		}
+		payments, err = service.AllPayments(ctx, "eu1", map[int64]int64{chainID: reported.BlockNumber + 1})
+		require.NoError(t, err)
+		require.Empty(t, payments.Removed)
+	})
+}
+
+func TestPaymentStatus(t *testing.T) {
+	t.Run("Postgres", func(t *testing.T) {
+		testPaymentStatus(t, dbtest.PickPostgres(t))
+	})
+	t.Run("Cockroach", func(t *testing.T) {
+		testPaymentStatus(t, dbtest.PickCockroach(t))
+	})
+}
+
+func testPaymentStatus(t *testing.T, connStr string) {
+	testeth.Run(t, 1, 2, func(ctx *testcontext.Context, t *testing.T, networks []*testeth.Network) {
+		logger := zaptest.NewLogger(t)
+		network := networks[0]
+
+		db, err := storjscandbtest.OpenDB(ctx, zaptest.NewLogger(t), connStr, t.Name(), "T")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ctx.Check(db.Close)
+
+		err = db.MigrateToLatest(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		client := network.Dial()
+		defer client.Close()
+
+		tk, err := testtoken.NewTestToken(network.TokenAddress(), client)
+		require.NoError(t, err)
+
+		accs := network.Accounts()
+
+		tx, err := tk.Transfer(network.TransactOptions(ctx, accs[0], 1), accs[1].Address, big.NewInt(1000))
+		require.NoError(t, err)
+		_, err = network.WaitForTx(ctx, tx.Hash())
+		require.NoError(t, err)
+
+		// fill token price DB.
+		tokenPriceDB := db.TokenPrice()
+		firstBlock := network.Ethereum().BlockChain().GetBlockByNumber(1)
+		price := currency.AmountFromBaseUnits(2000000, currency.USDollarsMicro)
+
+		startTime := time.Unix(int64(firstBlock.Time()), 0).Add(-time.Minute)
+		for i := 0; i < 10; i++ {
+			window := startTime.Add(time.Duration(i) * time.Minute)
+			require.NoError(t, tokenPriceDB.Update(ctx, window, price.BaseUnits()))
+		}
+
+		jsonEndpoint := `[{"Name":"Geth", "URL": "` + network.HTTPEndpoint() + `", "Contract": "` + network.TokenAddress().Hex() + `", "ChainID": "` + fmt.Sprint(network.ChainID()) + `", "Confirmations": "3"}]`
+		var ethEndpoints []common.EthEndpoint
+		err = json.Unmarshal([]byte(jsonEndpoint), &ethEndpoints)
+		require.NoError(t, err)
+
+		headersCache := blockchain.NewHeadersCache(logger, db.Headers(), blockchain.HeadersCacheConfig{})
+		clients := blockchain.NewClients(logger, blockchain.ClientsConfig{})
+		defer ctx.Check(clients.Close)
+		events := events.NewEventsService(logger, clients, db.Wallets(), db.TransferEvents(), db.ScanWatermarks(), db.ReportedEvents(), events.Config{
+			AddressBatchSize: 100,
+			BlockBatchSize:   100,
+			ChainReorgBuffer: 15,
+			MaximumQuerySize: 10000,
+		})
+		tokenPrice := tokenprice.NewService(logger, tokenPriceDB, coinmarketcap.NewTestClient(), time.Minute)
+		service := tokens.NewService(logger, clients, ethEndpoints, headersCache, events, tokenPrice, nil, 0, 0)
+
+		payments, err := service.Payments(ctx, accs[1].Address, nil)
+		require.NoError(t, err)
+		require.Len(t, payments.Payments, 1)
+		require.EqualValues(t, 1, payments.Payments[0].Confirmations)
+		require.Equal(t, tokens.PaymentStatusPending, payments.Payments[0].Status)
+
+		for i := 0; i < 2; i++ {
+			network.Commit()
+		}
+		payments, err = service.Payments(ctx, accs[1].Address, nil)
+		require.NoError(t, err)
+		require.Len(t, payments.Payments, 1)
+		require.EqualValues(t, 3, payments.Payments[0].Confirmations)
+		require.Equal(t, tokens.PaymentStatusConfirmed, payments.Payments[0].Status)
+
+		// the simulated beacon finalizes blocks once per epoch of 32 blocks
+		for i := 0; i < 64; i++ {
+			network.Commit()
+		}
+		payments, err = service.Payments(ctx, accs[1].Address, nil)
+		require.NoError(t, err)
+		require.Len(t, payments.Payments, 1)
+		require.EqualValues(t, 67, payments.Payments[0].Confirmations)
+		require.Equal(t, tokens.PaymentStatusFinalized, payments.Payments[0].Status)
+	})
+}
+
+func TestMultipleTokens(t *testing.T) {
+	t.Run("Postgres", func(t *testing.T) {
+		testMultipleTokens(t, dbtest.PickPostgres(t))
+	})
+	t.Run("Cockroach", func(t *testing.T) {
+		testMultipleTokens(t, dbtest.PickCockroach(t))
+	})
+}
+
+func testMultipleTokens(t *testing.T, connStr string) {
+	testeth.Run(t, 1, 2, func(ctx *testcontext.Context, t *testing.T, networks []*testeth.Network) {
+		logger := zaptest.NewLogger(t)
+		network := networks[0]
+
+		db, err := storjscandbtest.OpenDB(ctx, zaptest.NewLogger(t), connStr, t.Name(), "T")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ctx.Check(db.Close)
+
+		err = db.MigrateToLatest(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		client := network.Dial()
+		defer client.Close()
+
+		accs := network.Accounts()
+
+		storjToken, err := testtoken.NewTestToken(network.TokenAddress(), client)
+		require.NoError(t, err)
+
+		// deploy a second token acting as a USD stablecoin.
+		usdcAddress, deployTx, usdcToken, err := testtoken.DeployTestToken(network.TransactOptions(ctx, accs[0], 1), client, big.NewInt(1000000000000))
+		require.NoError(t, err)
+		_, err = network.WaitForTx(ctx, deployTx.Hash())
+		require.NoError(t, err)
+
+		_, err = db.Wallets().Insert(ctx, "eu1", accs[1].Address, "")
+		require.NoError(t, err)
+		_, err = db.Wallets().Claim(ctx, "eu1")
+		require.NoError(t, err)
+
+		storjTx, err := storjToken.Transfer(network.TransactOptions(ctx, accs[0], 2), accs[1].Address, big.NewInt(100000000))
+		require.NoError(t, err)
+		_, err = network.WaitForTx(ctx, storjTx.Hash())
+		require.NoError(t, err)
+
+		usdcTx, err := usdcToken.Transfer(network.TransactOptions(ctx, accs[0], 3), accs[1].Address, big.NewInt(2500000))
+		require.NoError(t, err)
+		_, err = network.WaitForTx(ctx, usdcTx.Hash())
+		require.NoError(t, err)
+
+		// fill token price DB.
+		tokenPriceDB := db.TokenPrice()
+		firstBlock := network.Ethereum().BlockChain().GetBlockByNumber(1)
+		price := currency.AmountFromBaseUnits(2000000, currency.USDollarsMicro)
+
+		startTime := time.Unix(int64(firstBlock.Time()), 0).Add(-time.Minute)
+		for i := 0; i < 10; i++ {
+			window := startTime.Add(time.Duration(i) * time.Minute)
+			require.NoError(t, tokenPriceDB.Update(ctx, window, price.BaseUnits()))
+		}
+
+		jsonEndpoint := `[{"Name":"Geth", "URL": "` + network.HTTPEndpoint() + `", "ChainID": "` + fmt.Sprint(network.ChainID()) + `", "Tokens": [` +
+			`{"Symbol": "STORJ", "Contract": "` + network.TokenAddress().Hex() + `", "Decimals": "8", "Price": "storj"},` +
+			`{"Symbol": "USDC", "Contract": "` + usdcAddress.Hex() + `", "Decimals": "6", "Price": "usd"}]}]`
+		var ethEndpoints []common.EthEndpoint
+		err = json.Unmarshal([]byte(jsonEndpoint), &ethEndpoints)
+		require.NoError(t, err)
+
+		headersCache := blockchain.NewHeadersCache(logger, db.Headers(), blockchain.HeadersCacheConfig{})
+		clients := blockchain.NewClients(logger, blockchain.ClientsConfig{})
+		defer ctx.Check(clients.Close)
+		eventsService := events.NewEventsService(logger, clients, db.Wallets(), db.TransferEvents(), db.ScanWatermarks(), db.ReportedEvents(), events.Config{
+			AddressBatchSize: 100,
+			BlockBatchSize:   100,
+			ChainReorgBuffer: 15,
+			MaximumQuerySize: 10000,
+		})
+		tokenPrice := tokenprice.NewService(logger, tokenPriceDB, coinmarketcap.NewTestClient(), time.Minute)
+		service := tokens.NewService(logger, clients, ethEndpoints, headersCache, eventsService, tokenPrice, nil, 0, 0)
+
+		payments, err := service.AllPayments(ctx, "eu1", nil)
+		require.NoError(t, err)
+		require.Len(t, payments.Payments, 2)
+
+		bySymbol := make(map[string]tokens.Payment)
+		for _, payment := range payments.Payments {
+			bySymbol[payment.Token] = payment
+		}
+
+		storjPayment := bySymbol["STORJ"]
+		require.Equal(t, storjTx.Hash(), storjPayment.Transaction)
+		require.Equal(t, network.TokenAddress(), storjPayment.Contract)
+		require.Equal(t, currency.StorjToken, storjPayment.TokenValue.Currency())
+		require.Equal(t, "1", storjPayment.TokenValue.AsDecimal().String())
+		require.Equal(t, "2", storjPayment.USDValue.AsDecimal().String())
+
+		usdcPayment := bySymbol["USDC"]
+		require.Equal(t, usdcTx.Hash(), usdcPayment.Transaction)
+		require.Equal(t, usdcAddress, usdcPayment.Contract)
+		require.Equal(t, "2.5", usdcPayment.TokenValue.AsDecimal().String())
+		require.Equal(t, "2.5", usdcPayment.USDValue.AsDecimal().String())
+
+		// the payments of tokens other than STORJ must be decodable by the clients of the API
+		data, err := json.Marshal(payments)
+		require.NoError(t, err)
+		var decoded tokens.LatestPayments
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		require.Len(t, decoded.Payments, 2)
+		for i, payment := range payments.Payments {
+			require.True(t, payment.TokenValue.Equal(decoded.Payments[i].TokenValue), "expected %s, got %s", payment.TokenValue, decoded.Payments[i].TokenValue)
+		}
+	})
+}
+
+func TestNativePayments(t *testing.T) {
+	t.Run("Postgres", func(t *testing.T) {
+		testNativePayments(t, dbtest.PickPostgres(t))
+	})
+	t.Run("Cockroach", func(t *testing.T) {
+		testNativePayments(t, dbtest.PickCockroach(t))
+	})
+}
+
+func testNativePayments(t *testing.T, connStr string) {
+	testeth.Run(t, 1, 3, func(ctx *testcontext.Context, t *testing.T, networks []*testeth.Network) {
+		logger := zaptest.NewLogger(t)
+		network := networks[0]
+
+		db, err := storjscandbtest.OpenDB(ctx, zaptest.NewLogger(t), connStr, t.Name(), "T")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ctx.Check(db.Close)
+
+		err = db.MigrateToLatest(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		accs := network.Accounts()
+
+		_, err = db.Wallets().Insert(ctx, "eu1", accs[1].Address, "")
+		require.NoError(t, err)
+		_, err = db.Wallets().Claim(ctx, "eu1")
+		require.NoError(t, err)
+
+		oneETH := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+		tx, err := network.TransferETH(ctx, accs[2], 0, accs[1].Address, oneETH)
+		require.NoError(t, err)
+		_, err = network.WaitForTx(ctx, tx.Hash())
+		require.NoError(t, err)
+
+		jsonEndpoint := `[{"Name":"Geth", "URL": "` + network.HTTPEndpoint() + `", "Contract": "` + network.TokenAddress().Hex() + `", "ChainID": "` + fmt.Sprint(network.ChainID()) + `"}]`
+		var ethEndpoints []common.EthEndpoint
+		err = json.Unmarshal([]byte(jsonEndpoint), &ethEndpoints)
+		require.NoError(t, err)
+
+		headersCache := blockchain.NewHeadersCache(logger, db.Headers(), blockchain.HeadersCacheConfig{})
+		clients := blockchain.NewClients(logger, blockchain.ClientsConfig{})
+		defer ctx.Check(clients.Close)
+		eventsService := events.NewEventsService(logger, clients, db.Wallets(), db.TransferEvents(), db.ScanWatermarks(), db.ReportedEvents(), events.Config{
+			AddressBatchSize: 100,
+			BlockBatchSize:   100,
+			ChainReorgBuffer: 15,
+			MaximumQuerySize: 10000,
+			Native:           true,
+		})
+		tokenPrice := tokenprice.NewService(logger, db.TokenPrice(), coinmarketcap.NewTestClient(), time.Minute)
+		ethPrice := tokenprice.NewService(logger, db.ETHPrice(), coinmarketcap.NewTestClient(), time.Minute)
+		service := tokens.NewService(logger, clients, ethEndpoints, headersCache, eventsService, tokenPrice, ethPrice, 0, 0)
+
+		payments, err := service.AllPayments(ctx, "eu1", nil)
+		require.NoError(t, err)
+		require.Len(t, payments.Payments, 1)
+
+		payment := payments.Payments[0]
+		require.Equal(t, tx.Hash(), payment.Transaction)
+		require.Equal(t, accs[2].Address, payment.From)
+		require.Equal(t, accs[1].Address, payment.To)
+		require.Equal(t, "ETH", payment.Token)
+		require.Equal(t, common.NativeAddress, payment.Contract)
+		require.Equal(t, "1", payment.TokenValue.AsDecimal().String())
+		require.Equal(t, "1", payment.USDValue.AsDecimal().String())
+		require.Equal(t, 1, payment.TraceIndex)
+
+		// the native currency payments must be decodable by the clients of the API
+		data, err := json.Marshal(payments)
+		require.NoError(t, err)
+		var decoded tokens.LatestPayments
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		require.Len(t, decoded.Payments, 1)
+		require.True(t, payment.TokenValue.Equal(decoded.Payments[0].TokenValue), "expected %s, got %s", payment.TokenValue, decoded.Payments[0].TokenValue)
+		require.Equal(t, int32(18), decoded.Payments[0].TokenValue.Currency().DecimalPlaces())
+	})
+}
+
+func TestOutgoingPayments(t *testing.T) {
+	t.Run("Postgres", func(t *testing.T) {
+		testOutgoingPayments(t, dbtest.PickPostgres(t))
+	})
+	t.Run("Cockroach", func(t *testing.T) {
+		testOutgoingPayments(t, dbtest.PickCockroach(t))
+	})
+}
+
+func testOutgoingPayments(t *testing.T, connStr string) {
+	testeth.Run(t, 1, 3, func(ctx *testcontext.Context, t *testing.T, networks []*testeth.Network) {
+		logger := zaptest.NewLogger(t)
+		network := networks[0]
+
+		db, err := storjscandbtest.OpenDB(ctx, zaptest.NewLogger(t), connStr, t.Name(), "T")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ctx.Check(db.Close)
+
+		err = db.MigrateToLatest(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		client := network.Dial()
+		defer client.Close()
+
+		tk, err := testtoken.NewTestToken(network.TokenAddress(), client)
+		require.NoError(t, err)
+
+		accs := network.Accounts()
+
+		_, err = db.Wallets().Insert(ctx, "eu1", accs[1].Address, "")
+		require.NoError(t, err)
+		_, err = db.Wallets().Claim(ctx, "eu1")
+		require.NoError(t, err)
+
+		// deposit to the wallet and sweep part of it
+		tx, err := tk.Transfer(network.TransactOptions(ctx, accs[0], 1), accs[1].Address, big.NewInt(1000))
+		require.NoError(t, err)
+		_, err = network.WaitForTx(ctx, tx.Hash())
+		require.NoError(t, err)
+
+		sweep, err := tk.Transfer(network.TransactOptions(ctx, accs[1], 0), accs[2].Address, big.NewInt(400))
+		require.NoError(t, err)
+		_, err = network.WaitForTx(ctx, sweep.Hash())
+		require.NoError(t, err)
+
+		// fill token price DB.
+		tokenPriceDB := db.TokenPrice()
+		firstBlock := network.Ethereum().BlockChain().GetBlockByNumber(1)
+		price := currency.AmountFromBaseUnits(2000000, currency.USDollarsMicro)
+
+		startTime := time.Unix(int64(firstBlock.Time()), 0).Add(-time.Minute)
+		for i := 0; i < 10; i++ {
+			window := startTime.Add(time.Duration(i) * time.Minute)
+			require.NoError(t, tokenPriceDB.Update(ctx, window, price.BaseUnits()))
+		}
+
+		jsonEndpoint := `[{"Name":"Geth", "URL": "` + network.HTTPEndpoint() + `", "Contract": "` + network.TokenAddress().Hex() + `", "ChainID": "` + fmt.Sprint(network.ChainID()) + `"}]`
+		var ethEndpoints []common.EthEndpoint
+		err = json.Unmarshal([]byte(jsonEndpoint), &ethEndpoints)
+		require.NoError(t, err)
+
+		for _, indexed := range []bool{false, true} {
+			t.Run(fmt.Sprintf("indexed=%t", indexed), func(t *testing.T) {
+				headersCache := blockchain.NewHeadersCache(logger, db.Headers(), blockchain.HeadersCacheConfig{})
+				clients := blockchain.NewClients(logger, blockchain.ClientsConfig{})
+				defer ctx.Check(clients.Close)
+				eventsService := events.NewEventsService(logger, clients, db.Wallets(), db.TransferEvents(), db.ScanWatermarks(), db.ReportedEvents(), events.Config{
+					AddressBatchSize: 100,
+					BlockBatchSize:   100,
+					ChainReorgBuffer: 15,
+					MaximumQuerySize: 10000,
+					Outgoing:         true,
+					Indexer: events.IndexerConfig{
+						Enabled: indexed,
+					},
+				})
+				if indexed {
+					require.NoError(t, eventsService.Index(ctx, ethEndpoints))
+				}
+				tokenPrice := tokenprice.NewService(logger, tokenPriceDB, coinmarketcap.NewTestClient(), time.Minute)
+				service := tokens.NewService(logger, clients, ethEndpoints, headersCache, eventsService, tokenPrice, nil, 0, 0)
+
+				payments, err := service.AllPayments(ctx, "eu1", nil)
+				require.NoError(t, err)
+				require.Len(t, payments.Payments, 1)
+				require.Equal(t, tx.Hash(), payments.Payments[0].Transaction)
+				require.Len(t, payments.Outgoing, 1)
+				require.Equal(t, sweep.Hash(), payments.Outgoing[0].Transaction)
+				require.Equal(t, accs[1].Address, payments.Outgoing[0].From)
+				require.Equal(t, accs[2].Address, payments.Outgoing[0].To)
+				require.EqualValues(t, 400, payments.Outgoing[0].TokenValue.BaseUnits())
+
+				payments, err = service.Payments(ctx, accs[1].Address, nil)
+				require.NoError(t, err)
+				require.Len(t, payments.Payments, 1)
+				require.Len(t, payments.Outgoing, 1)
+				require.Equal(t, sweep.Hash(), payments.Outgoing[0].Transaction)
+			})
+		}
+	})
+}
+
 func TestAllPayments(t *testing.T) {
 	t.Run("Postgres", func(t *testing.T) {
 		testAllPayments(t, dbtest.PickPostgres(t))
@@ -283,15 +953,17 @@ func testAllPayments(t *testing.T, connStr string) {
 		err = json.Unmarshal([]byte(jsonEndpoint), &ethEndpoints)
 		require.NoError(t, err)
 
-		headersCache := blockchain.NewHeadersCache(logger, db.Headers())
-		events := events.NewEventsService(logger, db.Wallets(), events.Config{
+		headersCache := blockchain.NewHeadersCache(logger, db.Headers(), blockchain.HeadersCacheConfig{})
+		clients := blockchain.NewClients(logger, blockchain.ClientsConfig{})
+		defer ctx.Check(clients.Close)
+		events := events.NewEventsService(logger, clients, db.Wallets(), db.TransferEvents(), db.ScanWatermarks(), db.ReportedEvents(), events.Config{
 			AddressBatchSize: 100,
 			BlockBatchSize:   100,
 			ChainReorgBuffer: 15,
 			MaximumQuerySize: 10000,
 		})
 		tokenPrice := tokenprice.NewService(logger, tokenPriceDB, coinmarketcap.NewTestClient(), time.Minute)
-		service := tokens.NewService(logger, ethEndpoints, headersCache, events, tokenPrice)
+		service := tokens.NewService(logger, clients, ethEndpoints, headersCache, events, tokenPrice, nil, 0, 0)
 
 		currentHead, err := client.HeaderByNumber(ctx, nil)
 		require.NoError(t, err)
@@ -311,10 +983,10 @@ func testAllPayments(t *testing.T, connStr string) {
 			require.Equal(t, latestBlockHeader, payments.LatestBlocks[0])
 			require.Equal(t, 4, len(payments.Payments))
 
-			a1 := currency.AmountFromBaseUnits(testPayments[1].Amount, currency.StorjToken)
-			a2 := currency.AmountFromBaseUnits(testPayments[2].Amount, currency.StorjToken)
-			a4 := currency.AmountFromBaseUnits(testPayments[4].Amount, currency.StorjToken)
-			a5 := currency.AmountFromBaseUnits(testPayments[5].Amount, currency.StorjToken)
+			a1 := common.TokenAmountFromBaseUnits(testPayments[1].Amount, currency.StorjToken)
+			a2 := common.TokenAmountFromBaseUnits(testPayments[2].Amount, currency.StorjToken)
+			a4 := common.TokenAmountFromBaseUnits(testPayments[4].Amount, currency.StorjToken)
+			a5 := common.TokenAmountFromBaseUnits(testPayments[5].Amount, currency.StorjToken)
 
 			txEqual(t, testPayments[1], payments.Payments[0])
 			require.EqualValues(t, tokenprice.CalculateValue(a1, price), payments.Payments[0].USDValue)
@@ -334,8 +1006,8 @@ func testAllPayments(t *testing.T, connStr string) {
 			require.Equal(t, latestBlockHeader, payments.LatestBlocks[0])
 			require.Equal(t, 2, len(payments.Payments))
 
-			a4 := currency.AmountFromBaseUnits(testPayments[4].Amount, currency.StorjToken)
-			a5 := currency.AmountFromBaseUnits(testPayments[5].Amount, currency.StorjToken)
+			a4 := common.TokenAmountFromBaseUnits(testPayments[4].Amount, currency.StorjToken)
+			a5 := common.TokenAmountFromBaseUnits(testPayments[5].Amount, currency.StorjToken)
 
 			txEqual(t, testPayments[4], payments.Payments[0])
 			require.EqualValues(t, tokenprice.CalculateValue(a4, price), payments.Payments[0].USDValue)
@@ -353,7 +1025,9 @@ func TestPing(t *testing.T) {
 		err := json.Unmarshal([]byte(jsonEndpoint), &ethEndpoints)
 		require.NoError(t, err)
 
-		service := tokens.NewService(zaptest.NewLogger(t), ethEndpoints, nil, nil, nil)
+		clients := blockchain.NewClients(zaptest.NewLogger(t), blockchain.ClientsConfig{})
+		defer ctx.Check(clients.Close)
+		service := tokens.NewService(zaptest.NewLogger(t), clients, ethEndpoints, nil, nil, nil, nil, 0, 0)
 		err = service.PingAll(ctx)
 		require.NoError(t, err)
 	})
@@ -366,7 +1040,9 @@ func TestChainIds(t *testing.T) {
 		err := json.Unmarshal([]byte(jsonEndpoint), &ethEndpoints)
 		require.NoError(t, err)
 
-		service := tokens.NewService(zaptest.NewLogger(t), ethEndpoints, nil, nil, nil)
+		clients := blockchain.NewClients(zaptest.NewLogger(t), blockchain.ClientsConfig{})
+		defer ctx.Check(clients.Close)
+		service := tokens.NewService(zaptest.NewLogger(t), clients, ethEndpoints, nil, nil, nil, nil, 0, 0)
 		ids, err := service.GetChainIds(ctx)
 		require.Len(t, ids, 2)
 		require.Equal(t, "Geth1", ids[networks[0].ChainID().Int64()])
@@ -382,12 +1058,82 @@ func TestPingMultipleAPIEndpoints(t *testing.T) {
 		err := json.Unmarshal([]byte(jsonEndpoint), &ethEndpoints)
 		require.NoError(t, err)
 
-		service := tokens.NewService(zaptest.NewLogger(t), ethEndpoints, nil, nil, nil)
+		clients := blockchain.NewClients(zaptest.NewLogger(t), blockchain.ClientsConfig{})
+		defer ctx.Check(clients.Close)
+		service := tokens.NewService(zaptest.NewLogger(t), clients, ethEndpoints, nil, nil, nil, nil, 0, 0)
 		err = service.PingAll(ctx)
 		require.NoError(t, err)
 	})
 }
 
+func TestVerifyContracts(t *testing.T) {
+	testeth.Run(t, 1, 1, func(ctx *testcontext.Context, t *testing.T, networks []*testeth.Network) {
+		network := networks[0]
+
+		clients := blockchain.NewClients(zaptest.NewLogger(t), blockchain.ClientsConfig{})
+		defer ctx.Check(clients.Close)
+
+		newService := func(url string, token common.Token) *tokens.Service {
+			endpoints := []common.EthEndpoint{{
+				Name:    "Geth",
+				URL:     url,
+				ChainID: network.ChainID().Int64(),
+				Tokens:  []common.Token{token},
+			}}
+			return tokens.NewService(zaptest.NewLogger(t), clients, endpoints, nil, nil, nil, nil, 0, 0)
+		}
+		verify := func(url string, token common.Token) error {
+			return newService(url, token).VerifyContracts(ctx)
+		}
+
+		t.Run("matching", func(t *testing.T) {
+			err := verify(network.HTTPEndpoint(), common.Token{Symbol: "TT", Contract: network.TokenAddress().Hex(), Decimals: 18})
+			require.NoError(t, err)
+		})
+		t.Run("wrong decimals", func(t *testing.T) {
+			err := verify(network.HTTPEndpoint(), common.Token{Symbol: "TT", Contract: network.TokenAddress().Hex(), Decimals: 8})
+			require.True(t, tokens.ErrContractMismatch.Has(err))
+		})
+		t.Run("wrong symbol", func(t *testing.T) {
+			err := verify(network.HTTPEndpoint(), common.Token{Symbol: "STORJ", Contract: network.TokenAddress().Hex(), Decimals: 18})
+			require.True(t, tokens.ErrContractMismatch.Has(err))
+		})
+		t.Run("no contract", func(t *testing.T) {
+			err := verify(network.HTTPEndpoint(), common.Token{Symbol: "TT", Contract: network.Accounts()[0].Address.Hex(), Decimals: 18})
+			require.True(t, tokens.ErrContractMismatch.Has(err))
+		})
+		t.Run("unreachable chain", func(t *testing.T) {
+			err := verify("http://127.0.0.1:1", common.Token{Symbol: "TT", Contract: network.TokenAddress().Hex(), Decimals: 18})
+			require.NoError(t, err)
+		})
+		t.Run("reachable after startup", func(t *testing.T) {
+			target, err := url.Parse(network.HTTPEndpoint())
+			require.NoError(t, err)
+			proxy := httputil.NewSingleHostReverseProxy(target)
+
+			var down atomic.Bool
+			down.Store(true)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if down.Load() {
+					http.Error(w, "unavailable", http.StatusServiceUnavailable)
+					return
+				}
+				proxy.ServeHTTP(w, r)
+			}))
+			defer server.Close()
+
+			service := newService(server.URL, common.Token{Symbol: "TT", Contract: network.TokenAddress().Hex(), Decimals: 8})
+			require.NoError(t, service.VerifyContracts(ctx))
+
+			// the contract is verified before the payments of the chain are served, failing the only chain
+			down.Store(false)
+			_, err = service.Payments(ctx, network.Accounts()[0].Address, nil)
+			require.True(t, tokens.ErrService.Has(err))
+			require.ErrorContains(t, err, "token contract mismatch")
+		})
+	})
+}
+
 func txEqual(t *testing.T, s struct {
 	Amount      int64
 	From        accounts.Account
@@ -402,3 +1148,181 @@ func txEqual(t *testing.T, s struct {
 	require.Equal(t, s.Amount, payment.TokenValue.BaseUnits())
 	require.Equal(t, s.Tx, payment.Transaction)
 }
+
+func TestPaymentsChainFailure(t *testing.T) {
+	t.Run("Postgres", func(t *testing.T) {
+		testPaymentsChainFailure(t, dbtest.PickPostgres(t))
+	})
+	t.Run("Cockroach", func(t *testing.T) {
+		testPaymentsChainFailure(t, dbtest.PickCockroach(t))
+	})
+}
+
+func testPaymentsChainFailure(t *testing.T, connStr string) {
+	testeth.Run(t, 1, 2, func(ctx *testcontext.Context, t *testing.T, networks []*testeth.Network) {
+		logger := zaptest.NewLogger(t)
+		network := networks[0]
+
+		db, err := storjscandbtest.OpenDB(ctx, zaptest.NewLogger(t), connStr, t.Name(), "T")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ctx.Check(db.Close)
+
+		err = db.MigrateToLatest(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		client := network.Dial()
+		defer client.Close()
+
+		tk, err := testtoken.NewTestToken(network.TokenAddress(), client)
+		require.NoError(t, err)
+
+		accs := network.Accounts()
+		opts := network.TransactOptions(ctx, accs[0], 1)
+		tx, err := tk.Transfer(opts, accs[1].Address, big.NewInt(1000000))
+		require.NoError(t, err)
+		_, err = network.WaitForTx(ctx, tx.Hash())
+		require.NoError(t, err)
+
+		tokenPriceDB := db.TokenPrice()
+		firstBlock := network.Ethereum().BlockChain().GetBlockByNumber(1)
+		price := currency.AmountFromBaseUnits(2000000, currency.USDollarsMicro)
+		startTime := time.Unix(int64(firstBlock.Time()), 0).Add(-time.Minute)
+		for i := 0; i < 10; i++ {
+			window := startTime.Add(time.Duration(i) * time.Minute)
+			require.NoError(t, tokenPriceDB.Update(ctx, window, price.BaseUnits()))
+		}
+
+		// the first chain is unreachable, the payments of the second one must still be returned
+		jsonEndpoint := `[{"Name":"Broken", "URL": "http://127.0.0.1:1", "Contract": "` + network.TokenAddress().Hex() + `", "ChainID": "9999"},` +
+			`{"Name":"Geth", "URL": "` + network.HTTPEndpoint() + `", "Contract": "` + network.TokenAddress().Hex() + `", "ChainID": "` + fmt.Sprint(network.ChainID()) + `"}]`
+		var ethEndpoints []common.EthEndpoint
+		err = json.Unmarshal([]byte(jsonEndpoint), &ethEndpoints)
+		require.NoError(t, err)
+
+		headersCache := blockchain.NewHeadersCache(logger, db.Headers(), blockchain.HeadersCacheConfig{})
+		clients := blockchain.NewClients(logger, blockchain.ClientsConfig{})
+		defer ctx.Check(clients.Close)
+		events := events.NewEventsService(logger, clients, db.Wallets(), db.TransferEvents(), db.ScanWatermarks(), db.ReportedEvents(), events.Config{
+			AddressBatchSize: 100,
+			BlockBatchSize:   100,
+			ChainReorgBuffer: 15,
+			MaximumQuerySize: 10000,
+		})
+		tokenPrice := tokenprice.NewService(logger, tokenPriceDB, coinmarketcap.NewTestClient(), time.Minute)
+		service := tokens.NewService(logger, clients, ethEndpoints, headersCache, events, tokenPrice, nil, time.Minute, 0)
+
+		payments, err := service.Payments(ctx, accs[1].Address, nil)
+		require.NoError(t, err)
+		require.Len(t, payments.Payments, 1)
+		require.Equal(t, tx.Hash(), payments.Payments[0].Transaction)
+		require.Equal(t, ethEndpoints[1].ChainID, payments.Payments[0].ChainID)
+
+		require.Len(t, payments.LatestBlocks, 1)
+		require.Equal(t, ethEndpoints[1].ChainID, payments.LatestBlocks[0].ChainID)
+
+		require.Len(t, payments.Chains, 2)
+		require.Equal(t, int64(9999), payments.Chains[0].ChainID)
+		require.Equal(t, "Broken", payments.Chains[0].Name)
+		require.NotEmpty(t, payments.Chains[0].Error)
+		require.Equal(t, ethEndpoints[1].ChainID, payments.Chains[1].ChainID)
+		require.Empty(t, payments.Chains[1].Error)
+
+		// the request fails when none of the chains can be scanned
+		service = tokens.NewService(logger, clients, ethEndpoints[:1], headersCache, events, tokenPrice, nil, time.Minute, 0)
+		_, err = service.Payments(ctx, accs[1].Address, nil)
+		require.Error(t, err)
+		require.True(t, tokens.ErrService.Has(err))
+	})
+}
+
+func TestPaymentsQuorum(t *testing.T) {
+	t.Run("Postgres", func(t *testing.T) {
+		testPaymentsQuorum(t, dbtest.PickPostgres(t))
+	})
+	t.Run("Cockroach", func(t *testing.T) {
+		testPaymentsQuorum(t, dbtest.PickCockroach(t))
+	})
+}
+
+func testPaymentsQuorum(t *testing.T, connStr string) {
+	testeth.Run(t, 2, 2, func(ctx *testcontext.Context, t *testing.T, networks []*testeth.Network) {
+		logger := zaptest.NewLogger(t)
+		network := networks[0]
+
+		db, err := storjscandbtest.OpenDB(ctx, zaptest.NewLogger(t), connStr, t.Name(), "T")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ctx.Check(db.Close)
+
+		err = db.MigrateToLatest(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		client := network.Dial()
+		defer client.Close()
+
+		tk, err := testtoken.NewTestToken(network.TokenAddress(), client)
+		require.NoError(t, err)
+
+		accs := network.Accounts()
+		opts := network.TransactOptions(ctx, accs[0], 1)
+		tx, err := tk.Transfer(opts, accs[1].Address, big.NewInt(1000000))
+		require.NoError(t, err)
+		_, err = network.WaitForTx(ctx, tx.Hash())
+		require.NoError(t, err)
+
+		tokenPriceDB := db.TokenPrice()
+		firstBlock := network.Ethereum().BlockChain().GetBlockByNumber(1)
+		price := currency.AmountFromBaseUnits(2000000, currency.USDollarsMicro)
+		startTime := time.Unix(int64(firstBlock.Time()), 0).Add(-time.Minute)
+		for i := 0; i < 10; i++ {
+			window := startTime.Add(time.Duration(i) * time.Minute)
+			require.NoError(t, tokenPriceDB.Update(ctx, window, price.BaseUnits()))
+		}
+
+		// the WebSocket endpoint is an independent provider of the same node, the second network is a provider
+		// which doesn't know the transaction
+		jsonEndpoint := `[{"Name":"Geth", "URL": "` + network.HTTPEndpoint() + `", "URLs": ["` + network.WSEndpoint() + `", "` + networks[1].HTTPEndpoint() + `"], ` +
+			`"Contract": "` + network.TokenAddress().Hex() + `", "ChainID": "` + fmt.Sprint(network.ChainID()) + `"}]`
+		var ethEndpoints []common.EthEndpoint
+		err = json.Unmarshal([]byte(jsonEndpoint), &ethEndpoints)
+		require.NoError(t, err)
+
+		headersCache := blockchain.NewHeadersCache(logger, db.Headers(), blockchain.HeadersCacheConfig{})
+		clients := blockchain.NewClients(logger, blockchain.ClientsConfig{})
+		defer ctx.Check(clients.Close)
+		events := events.NewEventsService(logger, clients, db.Wallets(), db.TransferEvents(), db.ScanWatermarks(), db.ReportedEvents(), events.Config{
+			AddressBatchSize: 100,
+			BlockBatchSize:   100,
+			ChainReorgBuffer: 15,
+			MaximumQuerySize: 10000,
+		})
+		tokenPrice := tokenprice.NewService(logger, tokenPriceDB, coinmarketcap.NewTestClient(), time.Minute)
+
+		t.Run("confirmed", func(t *testing.T) {
+			service := tokens.NewService(logger, clients, ethEndpoints, headersCache, events, tokenPrice, nil, 0, 1)
+
+			payments, err := service.Payments(ctx, accs[1].Address, nil)
+			require.NoError(t, err)
+			require.Len(t, payments.Payments, 1)
+			require.Equal(t, tx.Hash(), payments.Payments[0].Transaction)
+			require.Empty(t, payments.Payments[0].Disagreement)
+		})
+
+		t.Run("disagreement", func(t *testing.T) {
+			service := tokens.NewService(logger, clients, ethEndpoints, headersCache, events, tokenPrice, nil, 0, 2)
+
+			payments, err := service.Payments(ctx, accs[1].Address, nil)
+			require.NoError(t, err)
+			require.Len(t, payments.Payments, 1)
+			require.Equal(t, tx.Hash(), payments.Payments[0].Transaction)
+			require.Contains(t, payments.Payments[0].Disagreement, "not found")
+		})
+	})
+}
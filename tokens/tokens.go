@@ -15,22 +15,69 @@ import (
 
 var mon = monkit.Package()
 
+// PaymentStatus describes how final a payment is.
+type PaymentStatus string
+
+const (
+	// PaymentStatusPending is the status of payments with fewer confirmations than the endpoint threshold.
+	PaymentStatusPending PaymentStatus = "pending"
+	// PaymentStatusConfirmed is the status of payments which reached the endpoint confirmation threshold
+	// or the safe block of the chain.
+	PaymentStatusConfirmed PaymentStatus = "confirmed"
+	// PaymentStatusFinalized is the status of payments in or before the finalized block of the chain.
+	PaymentStatusFinalized PaymentStatus = "finalized"
+)
+
+// DefaultConfirmations is the confirmation threshold of endpoints which don't configure one.
+const DefaultConfirmations = 12
+
 // Payment is on chain payment made for particular contract and deposit wallet.
+// Token is the symbol of the received token and Contract its contract address.
+// Native currency payments, which don't emit logs, are identified by the LogIndex position of their transaction in the
+// block along with the TraceIndex position of their call in the transaction, which is 0 for token payments.
+// Disagreement is set when the payment was not confirmed by the quorum of independent providers of the chain.
 type Payment struct {
-	ChainID     int64
-	From        common.Address
-	To          common.Address
-	TokenValue  currency.Amount
-	USDValue    currency.Amount
-	BlockHash   common.Hash
-	BlockNumber int64
-	Transaction common.Hash
-	LogIndex    int
-	Timestamp   time.Time
+	ChainID       int64
+	From          common.Address
+	To            common.Address
+	Token         string
+	Contract      common.Address
+	TokenValue    common.TokenAmount
+	USDValue      currency.Amount
+	BlockHash     common.Hash
+	BlockNumber   int64
+	Transaction   common.Hash
+	LogIndex      int
+	TraceIndex    int `json:",omitempty"`
+	Timestamp     time.Time
+	Confirmations int64
+	Status        PaymentStatus
+	Disagreement  string `json:",omitempty"`
 }
 
 // LatestPayments contains latest payments and latest chain block header.
+// Removed contains previously reported payments whose blocks were orphaned by a chain reorganization.
+// Outgoing contains transfers sent from the deposit wallets, when tracking them is enabled.
+// Chains contains the status of every configured chain; the payments and latest blocks of failed chains are left out.
+// Partial is set when some chains were not scanned up to their latest block, the payments of the following blocks
+// are then retrieved by requesting the payments again with the returned Cursor.
 type LatestPayments struct {
 	LatestBlocks []blockchain.Header
 	Payments     []Payment
+	Removed      []Payment
+	Outgoing     []Payment
+	Chains       []ChainStatus
+	Partial      bool
+	Cursor       string `json:",omitempty"`
+}
+
+// ChainStatus is the status of retrieving the payments of a chain. Error is empty when the payments were retrieved.
+// Partial is set when the chain was not scanned up to its latest block. NativeError is set when the native currency
+// payments of the chain couldn't be retrieved, its token payments being returned anyway.
+type ChainStatus struct {
+	ChainID     int64
+	Name        string
+	Error       string
+	Partial     bool
+	NativeError string `json:",omitempty"`
 }